@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultAcceptanceWindow is how many of the latest bids the acceptance rate is computed over.
+const defaultAcceptanceWindow = 50
+
+// acceptanceMonitor tracks the share of the latest bids that were accepted and alerts once when
+// it stays below a threshold for a sustained period, which happens when the bot keeps bidding but
+// never wins, for instance because the amounts are too low or the offset is wrong. Recovery is
+// notified once the rate is back at the threshold. It is safe for concurrent use.
+type acceptanceMonitor struct {
+	mu         sync.Mutex
+	clock      clock         // Source of the current time.
+	notifier   notifier      // Receives the alerts.
+	threshold  float64       // The lowest healthy acceptance rate, between 0 and 1.
+	sustain    time.Duration // How long the rate must stay below threshold before alerting.
+	outcomes   []bool        // The latest outcomes, used as a ring.
+	next       int           // The index of outcomes the next outcome is written to.
+	count      int           // The outcomes recorded, up to len(outcomes).
+	accepted   int           // The accepted bids among the recorded outcomes.
+	belowSince time.Time     // When the rate dropped below threshold, zero while it is not.
+	alerted    bool          // Whether the drop was notified.
+}
+
+// newAcceptanceMonitor creates an acceptanceMonitor over the latest window bids. It returns nil,
+// which observes nothing, if threshold or window is not positive.
+func newAcceptanceMonitor(clock clock, n notifier, threshold float64, window int, sustain time.Duration) *acceptanceMonitor {
+	if threshold <= 0 || window <= 0 {
+		return nil
+	}
+	return &acceptanceMonitor{clock: clock, notifier: n, threshold: threshold, sustain: sustain, outcomes: make([]bool, window)}
+}
+
+// observe records whether a bid was accepted and returns the acceptance rate over the window.
+// The rate is only judged once the window is full.
+func (m *acceptanceMonitor) observe(accepted bool) float64 {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.count == len(m.outcomes) {
+		if m.outcomes[m.next] {
+			m.accepted--
+		}
+	} else {
+		m.count++
+	}
+	m.outcomes[m.next] = accepted
+	m.next = (m.next + 1) % len(m.outcomes)
+	if accepted {
+		m.accepted++
+	}
+
+	rate := float64(m.accepted) / float64(m.count)
+	if m.count < len(m.outcomes) {
+		return rate
+	}
+
+	now := m.clock.Now()
+	if rate >= m.threshold {
+		if m.alerted {
+			m.send(fmt.Sprintf("bid acceptance rate recovered to %.0f%% over the last %d bids", rate*100, m.count))
+		}
+		m.belowSince, m.alerted = time.Time{}, false
+		return rate
+	}
+	if m.belowSince.IsZero() {
+		m.belowSince = now
+	}
+	if !m.alerted && now.Sub(m.belowSince) >= m.sustain {
+		m.alerted = true
+		m.send(fmt.Sprintf("bid acceptance rate is %.0f%% over the last %d bids, below %.0f%% for %s",
+			rate*100, m.count, m.threshold*100, now.Sub(m.belowSince).Round(time.Second)))
+	}
+	return rate
+}
+
+// send delivers msg without holding up the bid that triggered it.
+func (m *acceptanceMonitor) send(msg string) {
+	go func() {
+		if err := m.notifier.notify(msg); err != nil {
+			log.Warn("failed to send alert", "err", err)
+		}
+	}()
+}
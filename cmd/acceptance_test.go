@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanNotifier passes the alerts it receives on a channel, as they are sent asynchronously.
+type chanNotifier chan string
+
+func (n chanNotifier) notify(msg string) error {
+	n <- msg
+	return nil
+}
+
+func TestAcceptanceMonitor(t *testing.T) {
+	type step struct {
+		advance  time.Duration // Passed before the bid.
+		accepted bool
+		rate     float64
+		alert    string // A part of the alert the bid triggers, empty for none.
+	}
+	tests := []struct {
+		name      string
+		threshold float64
+		window    int
+		sustain   time.Duration
+		steps     []step
+	}{
+		{
+			name: "window not full", threshold: 0.5, window: 4,
+			steps: []step{{rate: 0}, {rate: 0}, {rate: 0}},
+		},
+		{
+			name: "alerts once sustained", threshold: 0.5, window: 2, sustain: 10 * time.Minute,
+			steps: []step{
+				{rate: 0},
+				{rate: 0},
+				{advance: 5 * time.Minute, rate: 0},
+				{advance: 5 * time.Minute, rate: 0, alert: "is 0% over the last 2 bids, below 50% for 10m0s"},
+				{advance: time.Minute, rate: 0},
+			},
+		},
+		{
+			name: "recovers", threshold: 0.5, window: 2,
+			steps: []step{
+				{rate: 0},
+				{rate: 0, alert: "is 0%"},
+				{accepted: true, rate: 0.5, alert: "recovered to 50%"},
+				{accepted: true, rate: 1},
+			},
+		},
+		{
+			name: "short dip", threshold: 0.5, window: 2, sustain: 10 * time.Minute,
+			steps: []step{
+				{rate: 0},
+				{rate: 0},
+				{advance: 5 * time.Minute, accepted: true, rate: 0.5},
+				{advance: 6 * time.Minute, accepted: true, rate: 1},
+				{advance: time.Minute, rate: 0.5},
+				{advance: time.Minute, rate: 0},
+				{advance: 9 * time.Minute, rate: 0},
+			},
+		},
+		{
+			name: "oldest outcome drops out", threshold: 0.6, window: 3,
+			steps: []step{
+				{accepted: true, rate: 1},
+				{accepted: true, rate: 1},
+				{rate: 2.0 / 3},
+				{rate: 1.0 / 3, alert: "is 33% over the last 3 bids"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1700000000, 0)}
+			alerts := make(chanNotifier, len(tt.steps))
+			m := newAcceptanceMonitor(clock, alerts, tt.threshold, tt.window, tt.sustain)
+			for i, s := range tt.steps {
+				clock.now = clock.now.Add(s.advance)
+				if got := m.observe(s.accepted); got != s.rate {
+					t.Fatalf("bid %d: rate = %v, want %v", i, got, s.rate)
+				}
+				if s.alert == "" {
+					continue
+				}
+				select {
+				case msg := <-alerts:
+					if !strings.Contains(msg, s.alert) {
+						t.Fatalf("bid %d: alert %q, want it to contain %q", i, msg, s.alert)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("bid %d: no alert, want %q", i, s.alert)
+				}
+			}
+
+			// No other alert is sent
+			select {
+			case msg := <-alerts:
+				t.Fatalf("unexpected alert %q", msg)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestNewAcceptanceMonitorDisabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		window    int
+	}{
+		{name: "no threshold", window: defaultAcceptanceWindow},
+		{name: "negative threshold", threshold: -0.5, window: defaultAcceptanceWindow},
+		{name: "no window", threshold: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newAcceptanceMonitor(&fakeClock{}, chanNotifier(nil), tt.threshold, tt.window, 0)
+			if m != nil {
+				t.Fatalf("newAcceptanceMonitor = %+v, want nil", m)
+			}
+			// A disabled monitor observes nothing
+			if got := m.observe(false); got != 0 {
+				t.Fatalf("observe = %v, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// errBidBudgetExhausted is returned for bids skipped because the window's bid budget is spent.
+var errBidBudgetExhausted = errors.New("bid budget of the window is exhausted")
+
+// errDuplicateBid is returned for bids skipped because their transaction was already bid on.
+var errDuplicateBid = errors.New("transaction was already bid on")
+
+// windowBudget caps the total amount bid on the target blocks of one bidding window. The spend
+// starts over when bids move on to a later window.
+type windowBudget struct {
+	mu        sync.Mutex
+	max       *big.Int               // The most that may be bid per window, in wei. Nil disables the budget.
+	windows   *bb.WindowSizeDetector // Maps target blocks to windows.
+	window    uint64                 // The window being spent.
+	spent     *big.Int               // The amount bid in the window so far, in wei.
+	exhausted bool                   // Whether the exhaustion of the window was already logged.
+}
+
+// newWindowBudget creates a windowBudget allowing max wei of bids per window, nil for no limit.
+// Windows are sized by windows, which may be nil to use bb.DefaultBlocksPerWindow.
+func newWindowBudget(max *big.Int, windows *bb.WindowSizeDetector) *windowBudget {
+	return &windowBudget{max: max, windows: windows, spent: new(big.Int)}
+}
+
+// spend reserves amount for a bid on blockNumber and reports whether it fits in the budget of
+// the block's window. Bids on windows before the current one count against the current one.
+func (b *windowBudget) spend(blockNumber uint64, amount *big.Int) bool {
+	if b == nil || b.max == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if window := b.windows.WindowForBlock(blockNumber); window > b.window {
+		b.window = window
+		b.spent = new(big.Int)
+		b.exhausted = false
+	}
+
+	total := new(big.Int).Add(b.spent, amount)
+	if total.Cmp(b.max) > 0 {
+		if !b.exhausted {
+			log.Warn("bid budget of the window exhausted, skipping bids until the next window", "window", b.window, "spent (ETH)", ee.FormatEther(b.spent), "max (ETH)", ee.FormatEther(b.max))
+			b.exhausted = true
+		}
+		return false
+	}
+	b.spent = total
+	return true
+}
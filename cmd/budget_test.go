@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+func TestWindowBudgetSpend(t *testing.T) {
+	type bid struct {
+		block  uint64
+		amount int64
+		want   bool
+	}
+	tests := []struct {
+		name            string
+		max             *big.Int
+		blocksPerWindow uint64 // The window size detected before bidding, zero for none.
+		bids            []bid
+	}{
+		{name: "no limit", bids: []bid{{block: 1, amount: 1e18, want: true}, {block: 2, amount: 1e18, want: true}}},
+		{name: "within budget", max: big.NewInt(100), bids: []bid{{block: 1, amount: 40, want: true}, {block: 5, amount: 60, want: true}}},
+		{name: "over budget", max: big.NewInt(100), bids: []bid{{block: 1, amount: 60, want: true}, {block: 2, amount: 50, want: false}, {block: 3, amount: 40, want: true}}},
+		{name: "zero budget", max: new(big.Int), bids: []bid{{block: 1, amount: 1, want: false}, {block: 11, amount: 0, want: true}}},
+		{name: "next window starts over", max: big.NewInt(100), bids: []bid{{block: 10, amount: 100, want: true}, {block: 10, amount: 1, want: false}, {block: 11, amount: 100, want: true}}},
+		{name: "detected window size", max: big.NewInt(100), blocksPerWindow: 5, bids: []bid{{block: 5, amount: 100, want: true}, {block: 6, amount: 100, want: true}, {block: 10, amount: 1, want: false}}},
+		{name: "earlier window counts against the current", max: big.NewInt(100), bids: []bid{{block: 11, amount: 70, want: true}, {block: 9, amount: 40, want: false}, {block: 9, amount: 30, want: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var windows *bb.WindowSizeDetector
+			if tt.blocksPerWindow != 0 {
+				windows = bb.NewWindowSizeDetector()
+				for block := uint64(1); block <= 2*tt.blocksPerWindow+1; block++ {
+					windows.Observe((block-1)/tt.blocksPerWindow+1, block)
+				}
+			}
+			budget := newWindowBudget(tt.max, windows)
+			for i, bid := range tt.bids {
+				if got := budget.spend(bid.block, big.NewInt(bid.amount)); got != bid.want {
+					t.Fatalf("bid %d: spend(%d, %d) = %v, want %v", i, bid.block, bid.amount, got, bid.want)
+				}
+			}
+		})
+	}
+
+	var disabled *windowBudget
+	if !disabled.spend(1, big.NewInt(1)) {
+		t.Fatal("a nil budget rejected a bid")
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "time"
+
+// clock abstracts the current time so that time-based behaviour can be driven in tests.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the clock backed by the system time.
+type systemClock struct{}
+
+// Now returns the current system time.
+func (systemClock) Now() time.Time { return time.Now() }
+
+// After waits for d to elapse on the system clock.
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// competitionRetryDelay is how long watchCompetition waits before listening again after the
+// commitment listener fails.
+const competitionRetryDelay = 10 * time.Second
+
+// watchCompetition feeds the CommitmentStored events of the mev-commit chain to the competition
+// strategy in the background, listening again whenever the listener fails. The chain is reached
+// through MEV_COMMIT_WS_ENDPOINT, falling back to WS_ENDPOINT like the observe command.
+func watchCompetition(strategy *bb.CompetitionAwareBidStrategy) error {
+	wsEndpoint := os.Getenv("MEV_COMMIT_WS_ENDPOINT")
+	if wsEndpoint == "" {
+		wsEndpoint = os.Getenv("WS_ENDPOINT")
+	}
+	client, err := bb.NewGethClient(wsEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the mev-commit chain: %w", err)
+	}
+	listener, err := bb.NewCommitmentListener(client, bb.ListenerConfig{})
+	if err != nil {
+		return err
+	}
+
+	events := make(chan bb.CommitmentEvent)
+	go strategy.Consume(context.Background(), events)
+	go func() {
+		for {
+			err := listener.Run(context.Background(), events)
+			log.Warn("competition listener stopped, retrying", "err", err, "retryIn", competitionRetryDelay)
+			time.Sleep(competitionRetryDelay)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// redacted replaces the values of sensitive configuration entries.
+const redacted = "<redacted>"
+
+// configEntry is one resolved configuration value.
+type configEntry struct {
+	name      string
+	value     interface{}
+	sensitive bool // Whether the value is masked, e.g. a private key.
+}
+
+// effectiveConfig is the fully resolved configuration, after the env file and the process
+// environment are merged and defaults are applied. It is logged at startup so misconfiguration
+// shows up immediately.
+type effectiveConfig []configEntry
+
+// newEffectiveConfig starts a configuration with the chain ID and contract addresses the binary
+// was built against.
+func newEffectiveConfig() effectiveConfig {
+	cfg := effectiveConfig{{name: "chainID", value: bb.HOLESKY_CHAIN_ID}}
+	addresses := bb.ContractAddresses()
+	names := make([]string, 0, len(addresses))
+	for name := range addresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg = append(cfg, configEntry{name: name, value: addresses[name]})
+	}
+	return cfg
+}
+
+// add appends a value to the configuration.
+func (c *effectiveConfig) add(name string, value interface{}) {
+	*c = append(*c, configEntry{name: name, value: value})
+}
+
+// addEndpoint appends an endpoint URL, masking any credentials in it.
+func (c *effectiveConfig) addEndpoint(name, endpoint string) {
+	if u, err := url.Parse(endpoint); err == nil && u.User != nil {
+		endpoint = u.Redacted()
+	}
+	c.add(name, endpoint)
+}
+
+// addSecret appends a sensitive value, which is only reported as set or unset.
+func (c *effectiveConfig) addSecret(name, value string) {
+	*c = append(*c, configEntry{name: name, value: value, sensitive: true})
+}
+
+// display returns the value of e as it may be logged.
+func (e configEntry) display() interface{} {
+	if e.sensitive {
+		if e.value == "" {
+			return ""
+		}
+		return redacted
+	}
+	return e.value
+}
+
+// logCtx returns the configuration as key-value pairs for a structured log line.
+func (c effectiveConfig) logCtx() []interface{} {
+	ctx := make([]interface{}, 0, 2*len(c))
+	for _, e := range c {
+		ctx = append(ctx, e.name, e.display())
+	}
+	return ctx
+}
+
+// String formats the configuration as name=value pairs, with sensitive values masked.
+func (c effectiveConfig) String() string {
+	var b strings.Builder
+	for i, e := range c {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%s=%v", e.name, e.display())
+	}
+	return b.String()
+}
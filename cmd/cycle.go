@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// logBidCycle emits one structured log line summarising a bid cycle, so cycles can be followed
+// from the logs alone. It is emitted for failed cycles too, with whatever was known when the
+// cycle failed and the error under the "err" key.
+func logBidCycle(headBlock, targetBlock uint64, signedTx *types.Transaction, bidRequest *pb.Bid, result *bb.BidResult, latency time.Duration, err error) {
+	var (
+		txHash               string
+		amountEth, amountWei string
+		decayStart, decayEnd int64
+		commitments          int
+		firstCommitment      = "none"
+	)
+	if signedTx != nil {
+		txHash = signedTx.Hash().Hex()
+	}
+	if bidRequest != nil {
+		if amount, ok := new(big.Int).SetString(bidRequest.Amount, 10); ok {
+			amountEth = ee.FormatEther(amount)
+		}
+		amountWei = bidRequest.Amount
+		decayStart = bidRequest.DecayStartTimestamp
+		decayEnd = bidRequest.DecayEndTimestamp
+	}
+	if result != nil {
+		commitments = len(result.Commitments)
+		if commitments > 0 {
+			firstCommitment = result.FirstCommitmentLatency.String()
+		}
+	}
+
+	attrs := []interface{}{
+		"block", headBlock,
+		"targetBlock", targetBlock,
+		"txHash", txHash,
+		"amountEth", amountEth,
+		"amountWei", amountWei,
+		"decayStart", decayStart,
+		"decayEnd", decayEnd,
+		"commitments", commitments,
+		"firstCommitment", firstCommitment,
+		"latency", latency,
+	}
+	if err != nil {
+		log.Warn("bid cycle", append(attrs, "err", err)...)
+		return
+	}
+	log.Info("bid cycle", attrs...)
+}
+
+// cycleRunner runs bid cycles with a deadline. A cycle that runs past it is abandoned with its
+// context cancelled, and no new cycle starts until it has returned, so two cycles never reconcile
+// or take nonces at the same time. It is only used from the head loop.
+type cycleRunner struct {
+	timeout time.Duration
+	done    chan struct{} // Closed when the last abandoned cycle returns, nil if there is none.
+}
+
+// busy reports whether an abandoned cycle is still running.
+func (r *cycleRunner) busy() bool {
+	if r.done == nil {
+		return false
+	}
+	select {
+	case <-r.done:
+		r.done = nil
+		return false
+	default:
+		return true
+	}
+}
+
+// run runs fn with a context that is cancelled after the timeout, and reports whether fn returned
+// in time. When it doesn't, fn is left to finish in the background and busy reports true until it
+// does, so fn should stop early once ctx is done. A zero timeout waits for fn without a deadline.
+func (r *cycleRunner) run(fn func(ctx context.Context)) bool {
+	if r.timeout <= 0 {
+		fn(context.Background())
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		r.done = done
+		return false
+	}
+}
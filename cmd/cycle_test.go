@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCycleRunner(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		slow    bool
+		want    bool
+	}{
+		{name: "no timeout", timeout: 0, want: true},
+		{name: "returns in time", timeout: time.Second, want: true},
+		{name: "exceeds timeout", timeout: 10 * time.Millisecond, slow: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &cycleRunner{timeout: tt.timeout}
+			release := make(chan struct{})
+			cancelled := make(chan struct{})
+			got := r.run(func(ctx context.Context) {
+				if !tt.slow {
+					return
+				}
+				<-ctx.Done()
+				close(cancelled)
+				<-release
+			})
+			if got != tt.want {
+				t.Fatalf("run = %v, want %v", got, tt.want)
+			}
+			if !tt.slow {
+				if r.busy() {
+					t.Fatal("busy after a cycle returned in time")
+				}
+				return
+			}
+
+			<-cancelled
+			if !r.busy() {
+				t.Fatal("not busy while the abandoned cycle is still running")
+			}
+			close(release)
+			deadline := time.Now().Add(time.Second)
+			for r.busy() {
+				if time.Now().After(deadline) {
+					t.Fatal("still busy after the abandoned cycle returned")
+				}
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+}
+
+// hungBidder accepts bids but never answers them, until the call is cancelled.
+type hungBidder struct {
+	pb.UnimplementedBidderServer
+}
+
+func (hungBidder) SendBid(bid *pb.Bid, stream grpc.ServerStreamingServer[pb.Commitment]) error {
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+// serveBidder serves srv as a bidder node on a local port for the duration of the test and
+// returns its address.
+func serveBidder(t *testing.T, srv pb.BidderServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterBidderServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestCycleRunnerHungBidder(t *testing.T) {
+	// The call timeout alone would keep the cycle waiting far longer than the test runs
+	bidder, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: serveBidder(t, hungBidder{}), CallTimeout: time.Hour, Storage: bb.DiscardStorage{}})
+	if err != nil {
+		t.Fatalf("NewBidderClient: %v", err)
+	}
+	bid, err := bb.NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+	if err != nil {
+		t.Fatalf("NewBidRequest: %v", err)
+	}
+
+	r := &cycleRunner{timeout: 50 * time.Millisecond}
+	returned := make(chan error, 1)
+	if r.run(func(ctx context.Context) {
+		_, err := bidder.SendBidRequestWithRetryBudget(ctx, bid, 2, bb.NewRetryBudget(2, time.Minute))
+		returned <- err
+	}) {
+		t.Fatal("a cycle waiting on a hung bidder returned in time")
+	}
+
+	select {
+	case err := <-returned:
+		if code := status.Code(errors.Unwrap(err)); code != codes.DeadlineExceeded && code != codes.Canceled {
+			t.Fatalf("bid error = %v, want the call cut short by the cycle deadline", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the bid did not return once the cycle deadline passed")
+	}
+	deadline := time.Now().Add(time.Second)
+	for r.busy() {
+		if time.Now().After(deadline) {
+			t.Fatal("still busy after the abandoned cycle returned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
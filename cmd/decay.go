@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+const (
+	// fixedBidDecay is how long a bid decays for by default (2 blocks).
+	fixedBidDecay = 36 * time.Second
+	// defaultMinBidDecay is the shortest decay a bid decaying to its target block gets, for
+	// targets that are already imminent.
+	defaultMinBidDecay = 2 * time.Second
+)
+
+// bidDecay decides the decay window of a bid.
+type bidDecay struct {
+	toTarget bool          // Decay to zero at the target block's estimated proposal time instead of after fixedBidDecay.
+	minDecay time.Duration // The shortest decay when decaying to the target block.
+	startPct float64       // When decaying to the target, the decay starts this percentage of the way to it.
+	endPct   float64       // When decaying to the target, the decay ends this percentage of the way to it.
+}
+
+// window returns the decay start and end in milliseconds for a bid made at now on targetBlock,
+// while head is the latest observed block. When decaying to the target, the target block is
+// estimated to be proposed (targetBlock - head) slots after the head's timestamp, and the decay
+// covers startPct to endPct of the time until then.
+func (d bidDecay) window(now time.Time, head *types.Header, targetBlock uint64) (int64, int64) {
+	if !d.toTarget {
+		return now.UnixMilli(), now.Add(fixedBidDecay).UnixMilli()
+	}
+
+	headTime := time.Unix(int64(head.Time), 0)
+	var slots uint64
+	if headBlock := head.Number.Uint64(); targetBlock > headBlock {
+		slots = targetBlock - headBlock
+	}
+	decay := headTime.Add(time.Duration(slots) * slotDuration).Sub(now)
+	if decay < d.minDecay {
+		decay = d.minDecay
+	}
+	decayStart, decayEnd, err := bb.DecayWindowFromPercent(now, now.Add(decay), d.startPct, d.endPct)
+	if err != nil {
+		// The percentages are validated at startup and the target is after now
+		return now.UnixMilli(), now.Add(decay).UnixMilli()
+	}
+	return decayStart, decayEnd
+}
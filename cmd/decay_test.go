@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBidDecayWindow(t *testing.T) {
+	headTime := time.Unix(1_700_000_000, 0)
+	head := &types.Header{Number: big.NewInt(100), Time: uint64(headTime.Unix())}
+	tests := []struct {
+		name       string
+		decay      bidDecay
+		now        time.Time
+		target     uint64
+		start, end time.Duration // Relative to now.
+	}{
+		{name: "fixed", decay: bidDecay{minDecay: defaultMinBidDecay, endPct: 100}, now: headTime.Add(time.Second), target: 103, end: fixedBidDecay},
+		{name: "next block", decay: bidDecay{toTarget: true, minDecay: defaultMinBidDecay, endPct: 100}, now: headTime.Add(time.Second), target: 101, end: 11 * time.Second},
+		{name: "later block", decay: bidDecay{toTarget: true, minDecay: defaultMinBidDecay, endPct: 100}, now: headTime.Add(time.Second), target: 103, end: 35 * time.Second},
+		{name: "imminent target", decay: bidDecay{toTarget: true, minDecay: defaultMinBidDecay, endPct: 100}, now: headTime.Add(11 * time.Second), target: 101, end: defaultMinBidDecay},
+		{name: "percentages", decay: bidDecay{toTarget: true, minDecay: defaultMinBidDecay, startPct: 10, endPct: 90}, now: headTime, target: 101, start: 1200 * time.Millisecond, end: 10800 * time.Millisecond},
+		{name: "second half", decay: bidDecay{toTarget: true, minDecay: defaultMinBidDecay, startPct: 50, endPct: 100}, now: headTime.Add(time.Second), target: 103, start: 17500 * time.Millisecond, end: 35 * time.Second},
+		{name: "percentages of the minimum decay", decay: bidDecay{toTarget: true, minDecay: 4 * time.Second, startPct: 50, endPct: 100}, now: headTime.Add(11 * time.Second), target: 101, start: 2 * time.Second, end: 4 * time.Second},
+		{name: "percentages ignored for fixed decay", decay: bidDecay{minDecay: defaultMinBidDecay, startPct: 50, endPct: 60}, now: headTime, target: 101, end: fixedBidDecay},
+		{name: "target already passed", decay: bidDecay{toTarget: true, minDecay: 3 * time.Second, endPct: 100}, now: headTime.Add(time.Second), target: 99, end: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.decay.window(tt.now, head, tt.target)
+			if want := tt.now.Add(tt.start).UnixMilli(); start != want {
+				t.Errorf("decay start = %d, want %d", start, want)
+			}
+			if want := tt.now.Add(tt.end).UnixMilli(); end != want {
+				t.Errorf("decay end = %d, want %d", end, want)
+			}
+		})
+	}
+}
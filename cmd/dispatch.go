@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultMaxInFlightBids is how many bids may run at once by default.
+const defaultMaxInFlightBids = 16
+
+// bidLimiter bounds how many bids run at once across the whole process, whichever cycle started
+// them and whether they run synchronously or not.
+type bidLimiter struct {
+	sem     chan struct{} // Holds a token per running bid.
+	maxWait time.Duration // The longest a bid waits for a free slot. Zero waits as long as its cycle.
+}
+
+// newBidLimiter creates a bidLimiter allowing maxInFlight bids at once.
+func newBidLimiter(maxInFlight int, maxWait time.Duration) *bidLimiter {
+	return &bidLimiter{sem: make(chan struct{}, maxInFlight), maxWait: maxWait}
+}
+
+// acquire takes a slot for a bid, waiting for one to free up until ctx is done or maxWait has
+// passed. It reports whether a slot was taken; it must then be given back with release.
+func (l *bidLimiter) acquire(ctx context.Context) bool {
+	if l.maxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.maxWait)
+		defer cancel()
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release gives back a slot taken by acquire.
+func (l *bidLimiter) release() {
+	<-l.sem
+}
+
+// bidDispatcher runs each bid of a cycle in its own goroutine, once the limiter has a free slot
+// for it. By default a cycle waits for its bids to drain their commitment streams. In async mode
+// the cycle returns once its bids are started, so the loop can process the next head right away.
+type bidDispatcher struct {
+	async    bool
+	limiter  *bidLimiter    // Bounds the running bids of all cycles.
+	inFlight sync.WaitGroup // Tracks the running bids in async mode.
+}
+
+// newBidDispatcher creates a bidDispatcher whose bids are bounded by limiter.
+func newBidDispatcher(async bool, limiter *bidLimiter) *bidDispatcher {
+	return &bidDispatcher{async: async, limiter: limiter}
+}
+
+// dispatch waits for a free slot, then starts bid in its own goroutine. Synchronous bids are
+// added to cycle, which the cycle waits on. Async bids run with a context that is not cancelled
+// with the cycle. dispatch reports false if no slot freed up before ctx was done or the
+// limiter's wait ran out.
+func (d *bidDispatcher) dispatch(ctx context.Context, cycle *sync.WaitGroup, bid func(ctx context.Context)) bool {
+	if !d.limiter.acquire(ctx) {
+		return false
+	}
+
+	if !d.async {
+		cycle.Add(1)
+		go func() {
+			defer func() {
+				d.limiter.release()
+				cycle.Done()
+			}()
+			bid(ctx)
+		}()
+		return true
+	}
+
+	d.inFlight.Add(1)
+	go func() {
+		defer func() {
+			d.limiter.release()
+			d.inFlight.Done()
+		}()
+		bid(context.WithoutCancel(ctx))
+	}()
+	return true
+}
+
+// wait blocks until every async bid has finished.
+func (d *bidDispatcher) wait() {
+	d.inFlight.Wait()
+}
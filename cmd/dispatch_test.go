@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBidDispatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		async bool
+	}{
+		{name: "sync"},
+		{name: "async", async: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newBidDispatcher(tt.async, newBidLimiter(2, 0))
+			ctx, cancel := context.WithCancel(context.Background())
+
+			release := make(chan struct{})
+			cancelled := make(chan bool, 2)
+			var cycle sync.WaitGroup
+			for i := 0; i < 2; i++ {
+				ok := d.dispatch(ctx, &cycle, func(ctx context.Context) {
+					<-release
+					cancelled <- ctx.Err() != nil
+				})
+				if !ok {
+					t.Fatalf("bid %d was not dispatched", i)
+				}
+			}
+
+			// The cycle ends, cancelling its context, while the bids are still running
+			cancel()
+			close(release)
+			cycle.Wait()
+			if !tt.async && len(cancelled) != 2 {
+				t.Fatal("the cycle did not wait for its synchronous bids")
+			}
+			d.wait()
+			for i := 0; i < 2; i++ {
+				if got := <-cancelled; got != !tt.async {
+					t.Fatalf("bid context cancelled = %v, want %v", got, !tt.async)
+				}
+			}
+		})
+	}
+}
+
+func TestBidDispatcherMaxInFlight(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		t.Run(fmt.Sprintf("async %v", async), func(t *testing.T) {
+			d := newBidDispatcher(async, newBidLimiter(1, 0))
+			var cycle sync.WaitGroup
+			release := make(chan struct{})
+			if !d.dispatch(context.Background(), &cycle, func(context.Context) { <-release }) {
+				t.Fatal("first bid was not dispatched")
+			}
+
+			// No slot is free, so the next bid gives up once its cycle is done
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			if d.dispatch(ctx, &cycle, func(context.Context) { t.Error("bid ran without a free slot") }) {
+				t.Fatal("bid dispatched without a free slot")
+			}
+			cancel()
+
+			// Once the first bid ends, its slot is reused
+			close(release)
+			ran := make(chan struct{})
+			if !d.dispatch(context.Background(), &cycle, func(context.Context) { close(ran) }) {
+				t.Fatal("bid not dispatched after a slot freed up")
+			}
+			<-ran
+			cycle.Wait()
+			d.wait()
+		})
+	}
+}
+
+func TestBidLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxWait time.Duration
+		timeout time.Duration // Of the waiting bid's context, zero for none.
+	}{
+		{name: "gives up after its wait", maxWait: 20 * time.Millisecond},
+		{name: "gives up with its cycle", timeout: 20 * time.Millisecond},
+		{name: "cycle ends before the wait", maxWait: time.Hour, timeout: 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBidLimiter(1, tt.maxWait)
+			if !l.acquire(context.Background()) {
+				t.Fatal("first slot not taken")
+			}
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			if l.acquire(ctx) {
+				t.Fatal("slot taken while none was free")
+			}
+			if waited := time.Since(start); waited > time.Second {
+				t.Fatalf("waited %s for a slot", waited)
+			}
+
+			// The slot is free again once released
+			l.release()
+			if !l.acquire(context.Background()) {
+				t.Fatal("slot not taken after it was released")
+			}
+		})
+	}
+}
+
+func TestBidLimiterSharedAcrossCycles(t *testing.T) {
+	// Every cycle gets its own dispatcher, all bounded by the same limiter
+	limiter := newBidLimiter(1, 20*time.Millisecond)
+	tests := []struct {
+		name         string
+		first, later bool // Whether the first and later cycles are async.
+	}{
+		{name: "sync then sync"},
+		{name: "async then sync", first: true},
+		{name: "sync then async", later: true},
+		{name: "async then async", first: true, later: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := newBidDispatcher(tt.first, limiter)
+			var cycle sync.WaitGroup
+			release := make(chan struct{})
+			if !first.dispatch(context.Background(), &cycle, func(context.Context) { <-release }) {
+				t.Fatal("first bid was not dispatched")
+			}
+
+			// The first cycle's bid still holds the only slot
+			later := newBidDispatcher(tt.later, limiter)
+			var laterCycle sync.WaitGroup
+			if later.dispatch(context.Background(), &laterCycle, func(context.Context) { t.Error("bid ran without a free slot") }) {
+				t.Fatal("bid dispatched without a free slot")
+			}
+
+			close(release)
+			cycle.Wait()
+			first.wait()
+			ran := make(chan struct{})
+			if !later.dispatch(context.Background(), &laterCycle, func(context.Context) { close(ran) }) {
+				t.Fatal("bid not dispatched after a slot freed up")
+			}
+			<-ran
+			laterCycle.Wait()
+			later.wait()
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/joho/godotenv"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// defaultEnvFile is the dotenv file loaded when none is specified.
+const defaultEnvFile = ".env"
+
+// envFileFlag is the command line flag that selects the dotenv file to load.
+const envFileFlag = "--env-file"
+
+// envFileFromArgs finds the dotenv file to load, from the --env-file flag (as "--env-file path"
+// or "--env-file=path") or else the ENV_FILE environment variable. It returns the path, whether it
+// was explicitly specified, and the arguments with the flag removed.
+func envFileFromArgs(args []string) (string, bool, []string, error) {
+	path := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == envFileFlag:
+			if i+1 >= len(args) {
+				return "", false, nil, fmt.Errorf("%s requires a path", envFileFlag)
+			}
+			path = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], envFileFlag+"="):
+			path = strings.TrimPrefix(args[i], envFileFlag+"=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	if path == "" {
+		path = os.Getenv("ENV_FILE")
+	}
+	if path == "" {
+		return defaultEnvFile, false, rest, nil
+	}
+	return path, true, rest, nil
+}
+
+// loadEnvFile loads the dotenv file at path into the process environment. Variables already set
+// in the environment are not overridden. A missing file is only an error if it was explicitly
+// specified; otherwise loadEnvFile reports that nothing was loaded and the bot relies on the
+// process environment alone.
+func loadEnvFile(path string, explicit bool) (bool, error) {
+	if err := godotenv.Load(path); err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load env file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// missingEnvVars returns the names that are unset or empty in the process environment, which
+// includes anything loaded from the env file.
+func missingEnvVars(names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// privateKeyFromEnv returns the hex private key given either directly by PRIVATE_KEY or as the
+// contents of the file named by PRIVATE_KEY_FILE, such as a mounted secret. Exactly one of the
+// two must be set. Surrounding whitespace and a 0x prefix are removed from the file contents.
+func privateKeyFromEnv() (string, error) {
+	privateKey := os.Getenv("PRIVATE_KEY")
+	keyFile := os.Getenv("PRIVATE_KEY_FILE")
+	switch {
+	case privateKey != "" && keyFile != "":
+		return "", errors.New("only one of PRIVATE_KEY and PRIVATE_KEY_FILE may be set")
+	case privateKey != "":
+		return privateKey, nil
+	case keyFile != "":
+		return readPrivateKeyFile(keyFile)
+	default:
+		return "", errors.New("one of PRIVATE_KEY and PRIVATE_KEY_FILE must be set")
+	}
+}
+
+// chainIDOverrideFromEnv returns the chain ID set by CHAIN_ID_OVERRIDE, which transactions are
+// signed for instead of the one reported by the node, or nil if it is not set.
+func chainIDOverrideFromEnv() (*big.Int, error) {
+	chainIDEnv := os.Getenv("CHAIN_ID_OVERRIDE")
+	if chainIDEnv == "" {
+		return nil, nil
+	}
+	chainID, ok := new(big.Int).SetString(chainIDEnv, 10)
+	if !ok {
+		return nil, fmt.Errorf("CHAIN_ID_OVERRIDE must be a decimal number, got '%s'", chainIDEnv)
+	}
+	if chainID.Sign() <= 0 {
+		return nil, fmt.Errorf("CHAIN_ID_OVERRIDE: %w, got %s", ee.ErrInvalidChainID, chainID)
+	}
+	return chainID, nil
+}
+
+// bidAmountsFromEnv returns the unit of the bid amounts set by BID_AMOUNT_UNIT, ETH by default,
+// and the bid amount bounds BID_AMOUNT_MIN and BID_AMOUNT_MAX in wei.
+func bidAmountsFromEnv(env *envConfig) (string, *big.Int, *big.Int) {
+	unit := os.Getenv("BID_AMOUNT_UNIT")
+	if unit == "" {
+		unit = "eth"
+	}
+	if err := ee.ValidateUnit(unit); err != nil {
+		env.invalid("BID_AMOUNT_UNIT", err)
+		unit = "eth"
+	}
+	minAmount := env.amountVar("BID_AMOUNT_MIN", unit, "0.04")
+	maxAmount := env.amountVar("BID_AMOUNT_MAX", unit, "0.11")
+	if maxAmount.Cmp(minAmount) < 0 {
+		env.fail("BID_AMOUNT_MAX", "must not be lower than BID_AMOUNT_MIN")
+	}
+	return unit, minAmount, maxAmount
+}
+
+// bidStrategyFromEnv returns the strategy set by BID_STRATEGY and its name: a random amount
+// between the bounds, escalating from the minimum after rejections, or scaling the amount with
+// the competition seen in recent commitments. self is the bidder's own address, whose
+// commitments are not counted as competition.
+func bidStrategyFromEnv(env *envConfig, unit string, minAmount, maxAmount *big.Int, self common.Address) (bb.BidStrategy, string) {
+	name := os.Getenv("BID_STRATEGY")
+	if name == "" {
+		name = "random"
+	}
+
+	var strategy bb.BidStrategy
+	var err error
+	switch name {
+	case "random":
+		strategy, err = bb.NewRandomBidStrategy(minAmount, maxAmount)
+	case "escalating":
+		step := env.amountVar("BID_AMOUNT_STEP", unit, "0.01")
+		strategy, err = bb.NewEscalatingBidStrategy(minAmount, maxAmount, step)
+	case "competition":
+		strategy, err = bb.NewCompetitionAwareBidStrategy(bb.CompetitionConfig{
+			MinAmount:  minAmount,
+			MaxAmount:  maxAmount,
+			Self:       self,
+			Blocks:     env.uintVar("COMPETITION_BLOCKS", 0),
+			Saturation: env.floatVar("COMPETITION_SATURATION", 0),
+		})
+	default:
+		err = fmt.Errorf("unknown strategy '%s', must be random, escalating or competition", name)
+	}
+	env.invalid("BID_STRATEGY", err)
+	return strategy, name
+}
+
+// txOptionsFromEnv returns the options of the built transactions and the name of their fee
+// oracle: the fee multipliers and caps, the blob recipient, the chain ID override, the source of
+// the base fee and tip set by FEE_ORACLE, and the fork's limit on blobs per transaction.
+func txOptionsFromEnv(env *envConfig) (ee.TxOptions, string) {
+	var opts ee.TxOptions
+	opts.Fees.PriorityFeeMultiplier = env.floatVar("PRIORITY_FEE_MULTIPLIER", 0)
+	opts.Fees.MaxFeeMultiplier = env.floatVar("MAX_FEE_MULTIPLIER", 0)
+	opts.Fees.FeeCap = env.gweiVar("GAS_FEE_CAP_GWEI")
+	opts.Fees.TipCap = env.gweiVar("GAS_TIP_CAP_GWEI")
+	opts.Fees.MinTip = env.gweiVar("MIN_TIP_GWEI")
+	if err := opts.Fees.Validate(); err != nil {
+		env.errs = append(env.errs, fmt.Errorf("invalid fee configuration: %w", err))
+	}
+
+	// Send blob transactions to another address than the account itself, if configured
+	if recipientEnv := os.Getenv("BLOB_RECIPIENT"); recipientEnv != "" {
+		recipient := common.HexToAddress(recipientEnv)
+		switch {
+		case !common.IsHexAddress(recipientEnv):
+			env.fail("BLOB_RECIPIENT", "must be an address")
+		case recipient == (common.Address{}):
+			env.invalid("BLOB_RECIPIENT", ee.ErrZeroRecipient)
+		default:
+			opts.BlobRecipient = &recipient
+		}
+	}
+
+	// Sign for this chain instead of the one the node reports, for nodes that misreport it
+	chainID, err := chainIDOverrideFromEnv()
+	if err != nil {
+		env.errs = append(env.errs, err)
+	}
+	opts.ChainID = chainID
+
+	oracleName := os.Getenv("FEE_ORACLE")
+	if oracleName == "" {
+		oracleName = "header"
+	}
+	switch oracleName {
+	case "header":
+		opts.Oracle = ee.HeaderFeeOracle{}
+	case "feehistory":
+		oracle := ee.FeeHistoryOracle{
+			Blocks:     env.uintVar("FEE_HISTORY_BLOCKS", 0),
+			Percentile: env.floatVar("FEE_HISTORY_PERCENTILE", 0),
+		}
+		if oracle.Percentile < 0 || oracle.Percentile > 100 {
+			env.fail("FEE_HISTORY_PERCENTILE", "must be between 0 and 100")
+		}
+		opts.Oracle = oracle
+	default:
+		env.fail("FEE_ORACLE", "must be header or feehistory")
+	}
+
+	// The fork's limit on blobs per transaction, 9 after Prague
+	opts.MaxBlobs = int(env.uintVar("MAX_BLOBS_PER_TX", ee.MaxBlobsPerTransaction))
+	if opts.MaxBlobs == 0 {
+		env.fail("MAX_BLOBS_PER_TX", "must be at least 1")
+		opts.MaxBlobs = ee.MaxBlobsPerTransaction
+	}
+	return opts, oracleName
+}
+
+// numBlobsFromEnv returns the number of blobs per blob transaction set by NUM_BLOBS, at most
+// maxBlobs and by default as many as a transaction can carry under Cancun.
+func numBlobsFromEnv(env *envConfig, maxBlobs int) int {
+	numBlobs := min(ee.MaxBlobsPerTransaction, maxBlobs)
+	numBlobsEnv := os.Getenv("NUM_BLOBS")
+	if numBlobsEnv == "" {
+		return numBlobs
+	}
+	parsed, err := strconv.Atoi(numBlobsEnv)
+	if err != nil {
+		env.fail("NUM_BLOBS", "must be an integer")
+		return numBlobs
+	}
+	if err := ee.ValidateBlobCountMax(parsed, maxBlobs); err != nil {
+		env.invalid("NUM_BLOBS", err)
+		return numBlobs
+	}
+	return parsed
+}
+
+// bidDecayFromEnv returns the decay of the bids: after a fixed time, or to zero at their target
+// block's estimated proposal time if BID_DECAY_TO_TARGET is set, over part of the time until
+// then given as percentages of it.
+func bidDecayFromEnv(env *envConfig) bidDecay {
+	decay := bidDecay{
+		toTarget: env.boolVar("BID_DECAY_TO_TARGET", false),
+		minDecay: env.durationVar("MIN_BID_DECAY", defaultMinBidDecay),
+		startPct: env.floatVar("BID_DECAY_START_PCT", 0),
+		endPct:   env.floatVar("BID_DECAY_END_PCT", 100),
+	}
+	if _, _, err := bb.DecayWindowFromPercent(time.Unix(0, 0), time.Unix(1, 0), decay.startPct, decay.endPct); err != nil {
+		env.invalid("BID_DECAY_START_PCT or BID_DECAY_END_PCT", err)
+	}
+	return decay
+}
+
+// coinbasePaymentFromEnv returns the builder payment set by COINBASE_PAYMENT, in wei, and the
+// BUILDER_COINBASE address it is sent to. The payment is appended to bundles, so it is nil
+// unless usesHash, when bids are made by transaction hash.
+func coinbasePaymentFromEnv(env *envConfig, usesHash bool) (*big.Int, common.Address) {
+	if os.Getenv("COINBASE_PAYMENT") == "" {
+		return nil, common.Address{}
+	}
+	payment := env.etherVar("COINBASE_PAYMENT", "0")
+	if payment.Sign() <= 0 {
+		env.fail("COINBASE_PAYMENT", "must be positive")
+	}
+	coinbaseEnv := os.Getenv("BUILDER_COINBASE")
+	if !common.IsHexAddress(coinbaseEnv) {
+		env.fail("BUILDER_COINBASE", "must be set to the builder's coinbase address when COINBASE_PAYMENT is set")
+	}
+	if !usesHash {
+		log.Warn("COINBASE_PAYMENT only applies to bids by transaction hash, which send a bundle")
+		return nil, common.HexToAddress(coinbaseEnv)
+	}
+	return payment, common.HexToAddress(coinbaseEnv)
+}
+
+// bundleConfigFromEnv returns the configuration of the bundle client: the request deadline set
+// by BUNDLE_TIMEOUT, the JSON-RPC method set by BUNDLE_METHOD, since relays differ in the
+// method they accept bundles under, and how blob sidecars are sent, set by BUNDLE_BLOB_ENCODING.
+func bundleConfigFromEnv(env *envConfig) ee.BundleClientConfig {
+	cfg := ee.BundleClientConfig{
+		Timeout:      env.positiveDurationVar("BUNDLE_TIMEOUT", ee.DefaultBundleTimeout),
+		Method:       ee.DefaultBundleMethod,
+		BlobEncoding: ee.BlobEncodingNetwork,
+	}
+	if method, ok := os.LookupEnv("BUNDLE_METHOD"); ok {
+		if method == "" {
+			env.fail("BUNDLE_METHOD", "must not be empty")
+		} else {
+			cfg.Method = method
+		}
+	}
+	if encoding := os.Getenv("BUNDLE_BLOB_ENCODING"); encoding != "" {
+		if err := ee.ValidateBlobEncoding(encoding); err != nil {
+			env.invalid("BUNDLE_BLOB_ENCODING", err)
+		} else {
+			cfg.BlobEncoding = encoding
+		}
+	}
+	return cfg
+}
+
+// bidStorageFromEnv returns where the bidder saves bid requests and commitments: the default
+// files, as a nil storage, or nowhere when SAVE_BID_DATA is false.
+func bidStorageFromEnv(env *envConfig) bb.Storage {
+	if env.boolVar("SAVE_BID_DATA", true) {
+		return nil
+	}
+	return bb.DiscardStorage{}
+}
+
+// nodeSyncCheckFromEnv reports whether bidding is held off while the node is syncing, set by
+// CHECK_NODE_SYNC and on by default, and how often its sync progress is queried, set by
+// SYNC_CHECK_INTERVAL and once per slot by default.
+func nodeSyncCheckFromEnv(env *envConfig, slot time.Duration) (bool, time.Duration) {
+	return env.boolVar("CHECK_NODE_SYNC", true), env.durationVar("SYNC_CHECK_INTERVAL", slot)
+}
+
+// readPrivateKeyFile reads a hex private key from path.
+func readPrivateKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PRIVATE_KEY_FILE: %w", err)
+	}
+	privateKey := strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+	if privateKey == "" {
+		return "", fmt.Errorf("PRIVATE_KEY_FILE %s is empty", path)
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+	"google.golang.org/grpc"
+)
+
+// setenv sets the variables for the duration of the test.
+func setenv(t *testing.T, vars map[string]string) {
+	t.Helper()
+	for name, value := range vars {
+		t.Setenv(name, value)
+	}
+}
+
+func TestEnvFileFromArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		envFile  string
+		want     string
+		explicit bool
+		rest     []string
+		wantErr  bool
+	}{
+		{name: "default", args: []string{"observe"}, want: defaultEnvFile, rest: []string{"observe"}},
+		{name: "flag", args: []string{"--env-file", "a.env", "observe"}, want: "a.env", explicit: true, rest: []string{"observe"}},
+		{name: "flag with value", args: []string{"observe", "--env-file=b.env"}, want: "b.env", explicit: true, rest: []string{"observe"}},
+		{name: "ENV_FILE", envFile: "c.env", want: "c.env", explicit: true, rest: []string{}},
+		{name: "flag wins over ENV_FILE", args: []string{"--env-file", "a.env"}, envFile: "c.env", want: "a.env", explicit: true, rest: []string{}},
+		{name: "missing path", args: []string{"--env-file"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENV_FILE", tt.envFile)
+			got, explicit, rest, err := envFileFromArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("envFileFromArgs succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("envFileFromArgs: %v", err)
+			}
+			if got != tt.want || explicit != tt.explicit || !reflect.DeepEqual(rest, tt.rest) {
+				t.Fatalf("envFileFromArgs = %q, %v, %q, want %q, %v, %q", got, explicit, rest, tt.want, tt.explicit, tt.rest)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "test.env")
+	if err := os.WriteFile(envFile, []byte("LOAD_ENV_FILE_TEST_A=from-file\nLOAD_ENV_FILE_TEST_B=from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		path     string
+		explicit bool
+		loaded   bool
+		wantErr  bool
+	}{
+		{name: "file", path: envFile, explicit: true, loaded: true},
+		{name: "missing default", path: filepath.Join(dir, ".env")},
+		{name: "missing explicit", path: filepath.Join(dir, "missing.env"), explicit: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOAD_ENV_FILE_TEST_A", "")
+			os.Unsetenv("LOAD_ENV_FILE_TEST_A")
+			t.Setenv("LOAD_ENV_FILE_TEST_B", "from-environment")
+			loaded, err := loadEnvFile(tt.path, tt.explicit)
+			if loaded != tt.loaded || (err != nil) != tt.wantErr {
+				t.Fatalf("loadEnvFile = %v, %v, want %v, error %v", loaded, err, tt.loaded, tt.wantErr)
+			}
+			if !tt.loaded {
+				return
+			}
+			// Variables already set in the environment win over the file
+			if a, b := os.Getenv("LOAD_ENV_FILE_TEST_A"), os.Getenv("LOAD_ENV_FILE_TEST_B"); a != "from-file" || b != "from-environment" {
+				t.Fatalf("variables = %q, %q, want from-file, from-environment", a, b)
+			}
+		})
+	}
+}
+
+func TestPrivateKeyFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte(" 0xabc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	prefixOnlyFile := filepath.Join(dir, "prefix")
+	if err := os.WriteFile(prefixOnlyFile, []byte("0x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	plainFile := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plainFile, []byte("def"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "key", key: "abc", want: "abc"},
+		{name: "file", file: keyFile, want: "abc"},
+		{name: "both", key: "abc", file: keyFile, wantErr: true},
+		{name: "neither", wantErr: true},
+		{name: "file without prefix or newline", file: plainFile, want: "def"},
+		{name: "empty file", file: emptyFile, wantErr: true},
+		{name: "prefix only", file: prefixOnlyFile, wantErr: true},
+		{name: "missing file", file: filepath.Join(dir, "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, map[string]string{"PRIVATE_KEY": tt.key, "PRIVATE_KEY_FILE": tt.file})
+			got, err := privateKeyFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("privateKeyFromEnv error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("privateKeyFromEnv = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainIDOverrideFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    *big.Int
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "17000", want: big.NewInt(17000)},
+		{value: "0", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "0x4268", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("CHAIN_ID_OVERRIDE", tt.value)
+		got, err := chainIDOverrideFromEnv()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("chainIDOverrideFromEnv(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && got.Cmp(tt.want) != 0) {
+			t.Errorf("chainIDOverrideFromEnv(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEnvConfigVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		read    func(env *envConfig) interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "bool unset", read: func(env *envConfig) interface{} { return env.boolVar("V", true) }, want: true},
+		{name: "bool", value: "false", read: func(env *envConfig) interface{} { return env.boolVar("V", true) }, want: false},
+		{name: "bool invalid", value: "yes", read: func(env *envConfig) interface{} { return env.boolVar("V", true) }, want: true, wantErr: true},
+		{name: "uint", value: "7", read: func(env *envConfig) interface{} { return env.uintVar("V", 1) }, want: uint64(7)},
+		{name: "uint invalid", value: "-1", read: func(env *envConfig) interface{} { return env.uintVar("V", 1) }, want: uint64(1), wantErr: true},
+		{name: "duration", value: "3s", read: func(env *envConfig) interface{} { return env.durationVar("V", time.Second) }, want: 3 * time.Second},
+		{name: "duration negative", value: "-3s", read: func(env *envConfig) interface{} { return env.durationVar("V", time.Second) }, want: time.Second, wantErr: true},
+		{name: "positive duration zero", value: "0s", read: func(env *envConfig) interface{} { return env.positiveDurationVar("V", time.Second) }, want: time.Second, wantErr: true},
+		{name: "float", value: "1.5", read: func(env *envConfig) interface{} { return env.floatVar("V", 1) }, want: 1.5},
+		{name: "float invalid", value: "x", read: func(env *envConfig) interface{} { return env.floatVar("V", 1) }, want: 1.0, wantErr: true},
+		{name: "fraction", value: "0.5", read: func(env *envConfig) interface{} { return env.fractionVar("V", 0.2) }, want: 0.5},
+		{name: "fraction one", value: "1", read: func(env *envConfig) interface{} { return env.fractionVar("V", 0.2) }, want: 1.0},
+		{name: "fraction zero", value: "0", read: func(env *envConfig) interface{} { return env.fractionVar("V", 0.2) }, want: 0.2, wantErr: true},
+		{name: "fraction above one", value: "1.5", read: func(env *envConfig) interface{} { return env.fractionVar("V", 0.2) }, want: 0.2, wantErr: true},
+		{name: "gwei", value: "1.5", read: func(env *envConfig) interface{} { return env.gweiVar("V").String() }, want: "1500000000"},
+		{name: "gwei invalid", value: "1.0000000001", read: func(env *envConfig) interface{} { return env.gweiVar("V") == nil }, want: true, wantErr: true},
+		{name: "wei", value: "42", read: func(env *envConfig) interface{} { return env.weiVar("V").String() }, want: "42"},
+		{name: "wei negative", value: "-42", read: func(env *envConfig) interface{} { return env.weiVar("V") == nil }, want: true, wantErr: true},
+		{name: "ether default", read: func(env *envConfig) interface{} { return env.etherVar("V", "0.5").String() }, want: "500000000000000000"},
+		{name: "amount in gwei", value: "2", read: func(env *envConfig) interface{} { return env.amountVar("V", "gwei", "0.5").String() }, want: "2000000000"},
+		{name: "amount invalid", value: "abc", read: func(env *envConfig) interface{} { return env.amountVar("V", "gwei", "0.5").String() }, want: "500000000000000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("V", tt.value)
+			env := &envConfig{}
+			if got := tt.read(env); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBidAmountsFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]string
+		unit     string
+		min, max string
+		wantErr  bool
+	}{
+		{name: "defaults", vars: map[string]string{}, unit: "eth", min: "40000000000000000", max: "110000000000000000"},
+		{name: "gwei", vars: map[string]string{"BID_AMOUNT_UNIT": "gwei", "BID_AMOUNT_MIN": "1", "BID_AMOUNT_MAX": "2"}, unit: "gwei", min: "1000000000", max: "2000000000"},
+		{name: "wei", vars: map[string]string{"BID_AMOUNT_UNIT": "wei", "BID_AMOUNT_MIN": "5", "BID_AMOUNT_MAX": "7"}, unit: "wei", min: "5", max: "7"},
+		{name: "unit is case-insensitive", vars: map[string]string{"BID_AMOUNT_UNIT": "GWEI", "BID_AMOUNT_MIN": "0.5", "BID_AMOUNT_MAX": "1"}, unit: "GWEI", min: "500000000", max: "1000000000"},
+		{name: "finer than the unit", vars: map[string]string{"BID_AMOUNT_UNIT": "wei", "BID_AMOUNT_MIN": "0.5"}, unit: "wei", min: "40000000000000000", max: "110000000000000000", wantErr: true},
+		{name: "max below min", vars: map[string]string{"BID_AMOUNT_MIN": "0.2", "BID_AMOUNT_MAX": "0.1"}, unit: "eth", min: "200000000000000000", max: "100000000000000000", wantErr: true},
+		{name: "unknown unit", vars: map[string]string{"BID_AMOUNT_UNIT": "finney"}, unit: "eth", min: "40000000000000000", max: "110000000000000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, map[string]string{"BID_AMOUNT_UNIT": "", "BID_AMOUNT_MIN": "", "BID_AMOUNT_MAX": ""})
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			unit, minAmount, maxAmount := bidAmountsFromEnv(env)
+			if unit != tt.unit || minAmount.String() != tt.min || maxAmount.String() != tt.max {
+				t.Errorf("bidAmountsFromEnv = %s, %s, %s, want %s, %s, %s", unit, minAmount, maxAmount, tt.unit, tt.min, tt.max)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBidStrategyFromEnv(t *testing.T) {
+	minAmount, maxAmount := big.NewInt(1), big.NewInt(10)
+	tests := []struct {
+		strategy string
+		want     string
+		wantType interface{}
+		wantErr  bool
+	}{
+		{strategy: "", want: "random", wantType: &bb.RandomBidStrategy{}},
+		{strategy: "escalating", want: "escalating", wantType: &bb.EscalatingBidStrategy{}},
+		{strategy: "competition", want: "competition", wantType: &bb.CompetitionAwareBidStrategy{}},
+		{strategy: "greedy", want: "greedy", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			setenv(t, map[string]string{"BID_STRATEGY": tt.strategy, "BID_AMOUNT_STEP": "0.000000000000000001"})
+			env := &envConfig{}
+			strategy, name := bidStrategyFromEnv(env, "eth", minAmount, maxAmount, common.Address{1})
+			if name != tt.want {
+				t.Errorf("name = %q, want %q", name, tt.want)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantType != nil && reflect.TypeOf(strategy) != reflect.TypeOf(tt.wantType) {
+				t.Errorf("strategy = %T, want %T", strategy, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestTxOptionsFromEnv(t *testing.T) {
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		check   func(t *testing.T, opts ee.TxOptions, oracle string)
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			vars: map[string]string{},
+			check: func(t *testing.T, opts ee.TxOptions, oracle string) {
+				if oracle != "header" || opts.MaxBlobs != ee.MaxBlobsPerTransaction || opts.ChainID != nil || opts.BlobRecipient != nil {
+					t.Errorf("options = %+v, oracle %s", opts, oracle)
+				}
+			},
+		},
+		{
+			name: "fees",
+			vars: map[string]string{"PRIORITY_FEE_MULTIPLIER": "1.5", "GAS_FEE_CAP_GWEI": "30", "GAS_TIP_CAP_GWEI": "2"},
+			check: func(t *testing.T, opts ee.TxOptions, _ string) {
+				if opts.Fees.PriorityFeeMultiplier != 1.5 || opts.Fees.FeeCap.String() != "30000000000" || opts.Fees.TipCap.String() != "2000000000" {
+					t.Errorf("fees = %+v", opts.Fees)
+				}
+			},
+		},
+		{name: "fee cap below tip cap", vars: map[string]string{"GAS_FEE_CAP_GWEI": "1", "GAS_TIP_CAP_GWEI": "2"}, wantErr: true},
+		{
+			name: "blob recipient and chain",
+			vars: map[string]string{"BLOB_RECIPIENT": recipient.Hex(), "CHAIN_ID_OVERRIDE": "17000", "MAX_BLOBS_PER_TX": "9"},
+			check: func(t *testing.T, opts ee.TxOptions, _ string) {
+				if opts.BlobRecipient == nil || *opts.BlobRecipient != recipient || opts.ChainID.Int64() != 17000 || opts.MaxBlobs != 9 {
+					t.Errorf("options = %+v", opts)
+				}
+			},
+		},
+		{name: "zero blob recipient", vars: map[string]string{"BLOB_RECIPIENT": common.Address{}.Hex()}, wantErr: true},
+		{name: "invalid blob recipient", vars: map[string]string{"BLOB_RECIPIENT": "0x12"}, wantErr: true},
+		{
+			name: "fee history",
+			vars: map[string]string{"FEE_ORACLE": "feehistory", "FEE_HISTORY_BLOCKS": "10", "FEE_HISTORY_PERCENTILE": "60"},
+			check: func(t *testing.T, opts ee.TxOptions, oracle string) {
+				want := ee.FeeHistoryOracle{Blocks: 10, Percentile: 60}
+				if oracle != "feehistory" || opts.Oracle != want {
+					t.Errorf("oracle = %s %+v, want %+v", oracle, opts.Oracle, want)
+				}
+			},
+		},
+		{name: "fee history percentile", vars: map[string]string{"FEE_ORACLE": "feehistory", "FEE_HISTORY_PERCENTILE": "101"}, wantErr: true},
+		{name: "unknown oracle", vars: map[string]string{"FEE_ORACLE": "gasstation"}, wantErr: true},
+		{name: "zero max blobs", vars: map[string]string{"MAX_BLOBS_PER_TX": "0"}, wantErr: true},
+	}
+	names := []string{"PRIORITY_FEE_MULTIPLIER", "MAX_FEE_MULTIPLIER", "GAS_FEE_CAP_GWEI", "GAS_TIP_CAP_GWEI", "MIN_TIP_GWEI", "BLOB_RECIPIENT", "CHAIN_ID_OVERRIDE", "FEE_ORACLE", "FEE_HISTORY_BLOCKS", "FEE_HISTORY_PERCENTILE", "MAX_BLOBS_PER_TX"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range names {
+				t.Setenv(name, "")
+			}
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			opts, oracle := txOptionsFromEnv(env)
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, opts, oracle)
+			}
+		})
+	}
+}
+
+func TestNumBlobsFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		maxBlobs int
+		want     int
+		wantErr  bool
+	}{
+		{value: "", maxBlobs: ee.MaxBlobsPerTransaction, want: ee.MaxBlobsPerTransaction},
+		{value: "", maxBlobs: 3, want: 3},
+		{value: "2", maxBlobs: ee.MaxBlobsPerTransaction, want: 2},
+		{value: "9", maxBlobs: ee.PragueMaxBlobsPerTransaction, want: 9},
+		{value: "9", maxBlobs: ee.MaxBlobsPerTransaction, want: ee.MaxBlobsPerTransaction, wantErr: true},
+		{value: "0", maxBlobs: ee.MaxBlobsPerTransaction, want: ee.MaxBlobsPerTransaction, wantErr: true},
+		{value: "two", maxBlobs: ee.MaxBlobsPerTransaction, want: ee.MaxBlobsPerTransaction, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("NUM_BLOBS", tt.value)
+		env := &envConfig{}
+		if got := numBlobsFromEnv(env, tt.maxBlobs); got != tt.want {
+			t.Errorf("numBlobsFromEnv(%q, %d) = %d, want %d", tt.value, tt.maxBlobs, got, tt.want)
+		}
+		if err := env.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("numBlobsFromEnv(%q, %d) error = %v, want error %v", tt.value, tt.maxBlobs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBidDecayFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		want    bidDecay
+		wantErr bool
+	}{
+		{name: "defaults", vars: map[string]string{}, want: bidDecay{minDecay: defaultMinBidDecay, endPct: 100}},
+		{
+			name: "to target",
+			vars: map[string]string{"BID_DECAY_TO_TARGET": "true", "MIN_BID_DECAY": "2s", "BID_DECAY_START_PCT": "10", "BID_DECAY_END_PCT": "90"},
+			want: bidDecay{toTarget: true, minDecay: 2 * time.Second, startPct: 10, endPct: 90},
+		},
+		{name: "end before start", vars: map[string]string{"BID_DECAY_START_PCT": "60", "BID_DECAY_END_PCT": "50"}, want: bidDecay{minDecay: defaultMinBidDecay, startPct: 60, endPct: 50}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, map[string]string{"BID_DECAY_TO_TARGET": "", "MIN_BID_DECAY": "", "BID_DECAY_START_PCT": "", "BID_DECAY_END_PCT": ""})
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			if got := bidDecayFromEnv(env); got != tt.want {
+				t.Errorf("bidDecayFromEnv = %+v, want %+v", got, tt.want)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCoinbasePaymentFromEnv(t *testing.T) {
+	coinbase := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	tests := []struct {
+		name     string
+		payment  string
+		coinbase string
+		usesHash bool
+		want     string
+		wantErr  bool
+	}{
+		{name: "unset", usesHash: true},
+		{name: "hash bids", payment: "0.01", coinbase: coinbase.Hex(), usesHash: true, want: "10000000000000000"},
+		{name: "payload bids", payment: "0.01", coinbase: coinbase.Hex()},
+		{name: "missing coinbase", payment: "0.01", usesHash: true, want: "10000000000000000", wantErr: true},
+		{name: "zero payment", payment: "0", coinbase: coinbase.Hex(), usesHash: true, want: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, map[string]string{"COINBASE_PAYMENT": tt.payment, "BUILDER_COINBASE": tt.coinbase})
+			env := &envConfig{}
+			payment, _ := coinbasePaymentFromEnv(env, tt.usesHash)
+			got := ""
+			if payment != nil {
+				got = payment.String()
+			}
+			if got != tt.want {
+				t.Errorf("payment = %q, want %q", got, tt.want)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBundleConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		want    ee.BundleClientConfig
+		wantErr bool
+	}{
+		{name: "defaults", vars: map[string]string{}, want: ee.BundleClientConfig{Timeout: ee.DefaultBundleTimeout, Method: ee.DefaultBundleMethod, BlobEncoding: ee.BlobEncodingNetwork}},
+		{
+			name: "configured",
+			vars: map[string]string{"BUNDLE_TIMEOUT": "2s", "BUNDLE_METHOD": "mev_sendBundle", "BUNDLE_BLOB_ENCODING": ee.BlobEncodingSeparate},
+			want: ee.BundleClientConfig{Timeout: 2 * time.Second, Method: "mev_sendBundle", BlobEncoding: ee.BlobEncodingSeparate},
+		},
+		{name: "empty method", vars: map[string]string{"BUNDLE_METHOD": ""}, want: ee.BundleClientConfig{Timeout: ee.DefaultBundleTimeout, Method: ee.DefaultBundleMethod, BlobEncoding: ee.BlobEncodingNetwork}, wantErr: true},
+		{name: "zero timeout", vars: map[string]string{"BUNDLE_TIMEOUT": "0s"}, want: ee.BundleClientConfig{Timeout: ee.DefaultBundleTimeout, Method: ee.DefaultBundleMethod, BlobEncoding: ee.BlobEncodingNetwork}, wantErr: true},
+		{name: "unknown encoding", vars: map[string]string{"BUNDLE_BLOB_ENCODING": "inline"}, want: ee.BundleClientConfig{Timeout: ee.DefaultBundleTimeout, Method: ee.DefaultBundleMethod, BlobEncoding: ee.BlobEncodingNetwork}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BUNDLE_TIMEOUT", "")
+			t.Setenv("BUNDLE_BLOB_ENCODING", "")
+			t.Setenv("BUNDLE_METHOD", "")
+			os.Unsetenv("BUNDLE_METHOD")
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			if got := bundleConfigFromEnv(env); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bundleConfigFromEnv = %+v, want %+v", got, tt.want)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNodeSyncCheckFromEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		vars         map[string]string
+		wantCheck    bool
+		wantInterval time.Duration
+		wantErr      bool
+	}{
+		{name: "default", vars: map[string]string{}, wantCheck: true, wantInterval: 12 * time.Second},
+		{name: "disabled", vars: map[string]string{"CHECK_NODE_SYNC": "false"}, wantInterval: 12 * time.Second},
+		{name: "interval", vars: map[string]string{"CHECK_NODE_SYNC": "true", "SYNC_CHECK_INTERVAL": "1m"}, wantCheck: true, wantInterval: time.Minute},
+		{name: "invalid", vars: map[string]string{"CHECK_NODE_SYNC": "sometimes"}, wantCheck: true, wantInterval: 12 * time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CHECK_NODE_SYNC", "")
+			t.Setenv("SYNC_CHECK_INTERVAL", "")
+			os.Unsetenv("CHECK_NODE_SYNC")
+			os.Unsetenv("SYNC_CHECK_INTERVAL")
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			check, interval := nodeSyncCheckFromEnv(env, 12*time.Second)
+			if check != tt.wantCheck || interval != tt.wantInterval {
+				t.Errorf("nodeSyncCheckFromEnv = %v, %s, want %v, %s", check, interval, tt.wantCheck, tt.wantInterval)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// committingBidder answers every bid with one commitment.
+type committingBidder struct {
+	pb.UnimplementedBidderServer
+}
+
+func (committingBidder) SendBid(bid *pb.Bid, stream grpc.ServerStreamingServer[pb.Commitment]) error {
+	return stream.Send(&pb.Commitment{TxHashes: bid.TxHashes, BidAmount: bid.Amount, BlockNumber: bid.BlockNumber})
+}
+
+func TestBidStorageFromEnv(t *testing.T) {
+	addr := serveBidder(t, committingBidder{})
+	tests := []struct {
+		name      string
+		vars      map[string]string
+		wantFiles bool
+		wantErr   bool
+	}{
+		{name: "default", vars: map[string]string{}, wantFiles: true},
+		{name: "saved", vars: map[string]string{"SAVE_BID_DATA": "true"}, wantFiles: true},
+		{name: "not saved", vars: map[string]string{"SAVE_BID_DATA": "false"}},
+		{name: "invalid", vars: map[string]string{"SAVE_BID_DATA": "no"}, wantFiles: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SAVE_BID_DATA", "")
+			os.Unsetenv("SAVE_BID_DATA")
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			storage := bidStorageFromEnv(env)
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+
+			// The default files are relative to the working directory
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatalf("Getwd: %v", err)
+			}
+			if err := os.Chdir(t.TempDir()); err != nil {
+				t.Fatalf("Chdir: %v", err)
+			}
+			t.Cleanup(func() { os.Chdir(wd) })
+
+			bidder, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: addr, Storage: storage})
+			if err != nil {
+				t.Fatalf("NewBidderClient: %v", err)
+			}
+			result, err := bidder.SendBidAndCollect(context.Background(), []string{common.Hash{0xab}.Hex()}, "42", 100, 1000, 37000)
+			if err != nil || len(result.Commitments) != 1 {
+				t.Fatalf("SendBidAndCollect = %+v, %v", result, err)
+			}
+			bidder.Flush()
+
+			for _, file := range []string{bb.DefaultBidRequestsFile, bb.DefaultBidResponsesFile} {
+				if _, err := os.Stat(file); (err == nil) != tt.wantFiles {
+					t.Errorf("%s exists: %v, want %v", file, err == nil, tt.wantFiles)
+				}
+			}
+			if _, err := os.Stat("data"); !tt.wantFiles && !os.IsNotExist(err) {
+				t.Errorf("data directory created without SAVE_BID_DATA: %v", err)
+			}
+		})
+	}
+}
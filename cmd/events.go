@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// eventBufferSize is the number of events buffered for each Server-Sent Events client. Events
+// for a client that falls further behind are dropped rather than slowing down the others.
+const eventBufferSize = 64
+
+// eventBroker fans out events as Server-Sent Events to every connected client.
+type eventBroker struct {
+	mu      sync.Mutex
+	clients map[chan []byte]struct{} // The pending events of each connected client.
+}
+
+// newEventBroker creates an eventBroker without clients.
+func newEventBroker() *eventBroker {
+	return &eventBroker{clients: make(map[chan []byte]struct{})}
+}
+
+// publish sends an event, encoded as JSON, to every connected client. Clients whose buffer is
+// full miss the event.
+func (b *eventBroker) publish(event interface{}) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Warn("failed to encode event", "err", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for client := range b.clients {
+		select {
+		case client <- data:
+		default:
+			log.Debug("dropping event for a slow events client")
+		}
+	}
+}
+
+// subscribe registers a client and returns its event channel.
+func (b *eventBroker) subscribe() chan []byte {
+	client := make(chan []byte, eventBufferSize)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clients[client] = struct{}{}
+	return client
+}
+
+// unsubscribe removes a client registered by subscribe.
+func (b *eventBroker) unsubscribe(client chan []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.clients, client)
+}
+
+// ServeHTTP streams the published events to the client until it disconnects.
+func (b *eventBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := b.subscribe()
+	defer b.unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-client:
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// startEventServer serves the events published to broker on addr in the background, at /events.
+func startEventServer(addr string, broker *eventBroker) {
+	mux := http.NewServeMux()
+	mux.Handle("/events", broker)
+
+	go func() {
+		log.Info("events endpoint listening", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Error("events endpoint stopped", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEventBroker(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []interface{}
+		want   []string // The data lines received, in order.
+	}{
+		{name: "one event", events: []interface{}{map[string]string{"txnHash": "0x01"}}, want: []string{`{"txnHash":"0x01"}`}},
+		{name: "in order", events: []interface{}{1, "two", []int{3}}, want: []string{`1`, `"two"`, `[3]`}},
+		{name: "unencodable event skipped", events: []interface{}{make(chan int), 2}, want: []string{`2`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newEventBroker()
+			server := httptest.NewServer(broker)
+			defer server.Close()
+
+			// The headers are flushed once the client is subscribed
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			defer resp.Body.Close()
+			if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+				t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+			}
+
+			for _, event := range tt.events {
+				broker.publish(event)
+			}
+			reader := bufio.NewReader(resp.Body)
+			for _, want := range tt.want {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					t.Fatalf("reading event: %v", err)
+				}
+				if got := strings.TrimSuffix(line, "\n"); got != "data: "+want {
+					t.Fatalf("event = %q, want %q", got, "data: "+want)
+				}
+				if blank, err := reader.ReadString('\n'); err != nil || blank != "\n" {
+					t.Fatalf("event not terminated by a blank line: %q, %v", blank, err)
+				}
+			}
+		})
+	}
+}
+
+func TestEventBrokerSlowClient(t *testing.T) {
+	broker := newEventBroker()
+	slow, fast := broker.subscribe(), broker.subscribe()
+
+	// A full buffer drops the newer events of that client only
+	for i := 0; i < eventBufferSize; i++ {
+		broker.publish(i)
+	}
+	<-fast
+	broker.publish("late")
+	if len(slow) != eventBufferSize {
+		t.Fatalf("slow client has %d events, want %d", len(slow), eventBufferSize)
+	}
+	for i := 1; i < eventBufferSize; i++ {
+		<-fast
+	}
+	if got := string(<-fast); got != `"late"` {
+		t.Fatalf("fast client received %s, want \"late\"", got)
+	}
+
+	// Unsubscribed clients receive nothing
+	broker.unsubscribe(fast)
+	broker.publish("gone")
+	if len(fast) != 0 {
+		t.Fatalf("unsubscribed client received %d events", len(fast))
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// publicFallback broadcasts a transaction to the public mempool if its bid gets no commitment,
+// either because the bid ended without one or because the timeout passed first. Broadcasting a
+// transaction that is also committed is harmless: it can only be included once.
+type publicFallback struct {
+	once   sync.Once
+	timer  *time.Timer
+	client *ethclient.Client
+	tx     *types.Transaction
+}
+
+// startPublicFallback arms a fallback for tx that fires after timeout.
+func startPublicFallback(client *ethclient.Client, tx *types.Transaction, timeout time.Duration) *publicFallback {
+	f := &publicFallback{client: client, tx: tx}
+	f.timer = time.AfterFunc(timeout, func() {
+		log.Info("no commitment before the public fallback timeout", "txHash", tx.Hash(), "timeout", timeout)
+		f.broadcast()
+	})
+	return f
+}
+
+// resolve disarms the fallback once the bid has ended, broadcasting right away if the bid got
+// no commitment.
+func (f *publicFallback) resolve(committed bool) {
+	f.timer.Stop()
+	if !committed {
+		f.broadcast()
+	}
+}
+
+// broadcast sends the transaction to the public mempool, at most once.
+func (f *publicFallback) broadcast() {
+	f.once.Do(func() {
+		if err := f.client.SendTransaction(context.Background(), f.tx); err != nil {
+			log.Warn("failed to broadcast transaction to the public mempool", "txHash", f.tx.Hash(), "err", err)
+			return
+		}
+		log.Info("broadcast transaction to the public mempool", "txHash", f.tx.Hash())
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeMempool counts the transactions broadcast to it.
+type fakeMempool struct {
+	mu   sync.Mutex
+	sent []common.Hash
+}
+
+func (f *fakeMempool) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, tx.Hash())
+	return tx.Hash(), nil
+}
+
+func (f *fakeMempool) broadcasts() []common.Hash {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]common.Hash(nil), f.sent...)
+}
+
+func TestPublicFallback(t *testing.T) {
+	to := common.Address{1}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 3, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)})
+
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		waitTimeout   bool // Whether the timeout passes before the bid ends.
+		committed     bool
+		wantBroadcast bool
+	}{
+		{name: "committed", timeout: time.Minute, committed: true},
+		{name: "no commitment", timeout: time.Minute, wantBroadcast: true},
+		{name: "timeout then committed", timeout: time.Millisecond, waitTimeout: true, committed: true, wantBroadcast: true},
+		{name: "timeout then no commitment", timeout: time.Millisecond, waitTimeout: true, wantBroadcast: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mempool := &fakeMempool{}
+			server := rpc.NewServer()
+			if err := server.RegisterName("eth", mempool); err != nil {
+				t.Fatalf("RegisterName: %v", err)
+			}
+			defer server.Stop()
+			client := ethclient.NewClient(rpc.DialInProc(server))
+			defer client.Close()
+
+			f := startPublicFallback(client, tx, tt.timeout)
+			if tt.waitTimeout {
+				deadline := time.Now().Add(5 * time.Second)
+				for len(mempool.broadcasts()) == 0 {
+					if time.Now().After(deadline) {
+						t.Fatal("the timeout did not broadcast the transaction")
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}
+			f.resolve(tt.committed)
+
+			// The transaction is broadcast at most once
+			want := 0
+			if tt.wantBroadcast {
+				want = 1
+			}
+			sent := mempool.broadcasts()
+			if len(sent) != want {
+				t.Fatalf("%d broadcasts, want %d", len(sent), want)
+			}
+			if want == 1 && sent[0] != tx.Hash() {
+				t.Fatalf("broadcast %s, want %s", sent[0], tx.Hash())
+			}
+		})
+	}
+}
@@ -2,85 +2,211 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/big"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/joho/godotenv"
+	"github.com/ethereum/go-ethereum/metrics"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
 	ee "github.com/primev/preconf_blob_bidder/core/eth"
 	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
-	"golang.org/x/exp/rand"
 )
 
-var NUM_BLOBS = 6
+// maxBidHorizon is the most consecutive target blocks bid on per head.
+const maxBidHorizon = 8
 
 func main() {
-	// Load the .env file
-	err := godotenv.Load()
+	// Print the build information without connecting to anything
+	if len(os.Args) > 1 && (os.Args[1] == "version" || os.Args[1] == "--version") {
+		printVersion()
+		return
+	}
+
+	// Load the .env file, or the file given by --env-file or ENV_FILE
+	envFile, explicitEnvFile, args, err := envFileFromArgs(os.Args[1:])
 	if err != nil {
-		log.Crit("Error loading .env file", "err", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	envFileLoaded, envFileErr := loadEnvFile(envFile, explicitEnvFile)
 
-	// Set up logging
-	glogger := log.NewGlogHandler(log.NewTerminalHandler(os.Stderr, true))
+	// Set up logging, as JSON lines on stdout if requested
+	var logHandler slog.Handler = log.NewTerminalHandler(os.Stderr, true)
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat == "json" {
+		logHandler = log.JSONHandler(os.Stdout)
+	} else if logFormat != "" && logFormat != "terminal" {
+		log.Crit("Invalid LOG_FORMAT value, must be terminal or json", "value", logFormat)
+	}
+	glogger := log.NewGlogHandler(logHandler)
 	glogger.Verbosity(log.LevelInfo)
 	log.SetDefault(log.NewLogger(glogger))
 
+	// The core packages stay silent unless given a logger
+	bb.SetLogger(log.Root())
+	ee.SetLogger(log.Root())
+
+	if envFileErr != nil {
+		log.Crit("Error loading env file", "err", envFileErr)
+	}
+	if !envFileLoaded {
+		log.Warn("No .env file found, using the process environment", "path", envFile)
+	}
+
+	// Replay a recorded bid cycle instead of running the bot
+	if len(args) > 1 && args[0] == "replay" {
+		runReplay(args[1])
+		return
+	}
+
+	// Only listen for commitments instead of running the bot
+	if len(args) > 0 && args[0] == "observe" {
+		if err := runObserve(); err != nil {
+			log.Crit("failed to observe commitments", "err", err)
+		}
+		return
+	}
+
+	// Sign a transaction and print it instead of running the bot
+	if len(args) > 0 && args[0] == "sign" {
+		if err := runSign(args[1:]); err != nil {
+			log.Crit("failed to sign transaction", "err", err)
+		}
+		return
+	}
+
+	// Print the saved bids instead of running the bot
+	if len(args) > 0 && args[0] == "inspect" {
+		if err := runInspect(args[1:]); err != nil {
+			log.Crit("failed to inspect saved bids", "err", err)
+		}
+		return
+	}
+
+	// Run one checked bid cycle against a devnet instead of running the bot
+	if len(args) > 0 && args[0] == "selftest" {
+		if err := runSelftest(args[1:]); err != nil {
+			log.Crit("self-test did not pass", "err", err)
+		}
+		return
+	}
+
+	// Send the saved bids again instead of running the bot
+	if len(args) > 0 && args[0] == "resubmit" {
+		if err := runResubmit(args[1:]); err != nil {
+			log.Crit("failed to resubmit saved bids", "err", err)
+		}
+		return
+	}
+
+	// Bid on one transaction with several amounts for the same block
+	if len(args) > 0 && args[0] == "ladder" {
+		if err := runLadder(args[1:]); err != nil {
+			log.Crit("failed to send bid ladder", "err", err)
+		}
+		return
+	}
+
+	// Bid on the transactions of other senders in the mempool instead of running the bot
+	if len(args) > 0 && args[0] == "mempool" {
+		if err := runMempool(); err != nil {
+			log.Crit("failed to bid on mempool transactions", "err", err)
+		}
+		return
+	}
+
 	// Read configuration from environment variables
 	bidderAddress := os.Getenv("BIDDER_ADDRESS")
 	if bidderAddress == "" {
 		bidderAddress = "mev-commit-bidder:13524"
 	}
 
-	usePayloadEnv := os.Getenv("USE_PAYLOAD")
-	usePayload := true // Default value
-	if usePayloadEnv != "" {
-		// Convert usePayloadEnv to bool
-		var err error
-		usePayload, err = parseBoolEnvVar("USE_PAYLOAD", usePayloadEnv)
-		if err != nil {
-			log.Crit("Invalid USE_PAYLOAD value", "err", err)
-		}
+	// Invalid values are collected and reported together once all are read
+	env := &envConfig{}
+
+	usePayload := env.boolVar("USE_PAYLOAD", true)
+
+	// Blob and transfer bids may override USE_PAYLOAD, e.g. to always send the payload of blob
+	// transactions while bidding on transfers by hash
+	payloads := payloadPolicy{
+		blob:     env.boolVar("BLOB_USE_PAYLOAD", usePayload),
+		transfer: env.boolVar("TRANSFER_USE_PAYLOAD", usePayload),
 	}
+	usesHash := payloads.usesHash(os.Getenv("BLOB") == "true")
 
-	// Now, load rpcEndpoint conditionally
-	var rpcEndpoint string
-	if !usePayload {
-		rpcEndpoint = os.Getenv("RPC_ENDPOINT")
-		if rpcEndpoint == "" {
-			log.Crit("RPC_ENDPOINT environment variable is required when USE_PAYLOAD is false")
-		}
+	// Check the required variables together, so all missing ones are reported at once.
+	// RPC_ENDPOINT is only required when bidding by transaction hash. The private key is
+	// checked on its own, since it can come from PRIVATE_KEY or PRIVATE_KEY_FILE.
+	required := []string{"WS_ENDPOINT"}
+	if usesHash {
+		required = append(required, "RPC_ENDPOINT")
+	}
+	if missing := missingEnvVars(required...); len(missing) > 0 {
+		log.Crit("Required environment variables are not set", "missing", strings.Join(missing, ","), "envFile", envFile, "envFileLoaded", envFileLoaded)
 	}
 
+	rpcEndpoint := os.Getenv("RPC_ENDPOINT")
+	if !usesHash {
+		rpcEndpoint = ""
+	}
 	wsEndpoint := os.Getenv("WS_ENDPOINT")
-	if wsEndpoint == "" {
-		log.Crit("WS_ENDPOINT environment variable is required")
+	privateKeyHex, err := privateKeyFromEnv()
+	if err != nil {
+		log.Crit("Invalid private key configuration", "err", err)
+	}
+	authAcct, err := bb.AuthenticateAddress(privateKeyHex)
+	if err != nil {
+		log.Crit("Failed to authenticate private key:", "err", err)
 	}
 
-	privateKeyHex := os.Getenv("PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Crit("PRIVATE_KEY environment variable is required")
+	offset := env.uintVar("OFFSET", 1)
+
+	bidHorizon := env.uintVar("BID_HORIZON", 1) // Default to bidding on a single target block per head
+	if bidHorizon == 0 || bidHorizon > maxBidHorizon {
+		env.fail("BID_HORIZON", fmt.Sprintf("must be between 1 and %d", maxBidHorizon))
+		bidHorizon = 1
 	}
 
-	offsetEnv := os.Getenv("OFFSET")
-	var offset uint64 = 1 // Default offset
-	if offsetEnv != "" {
-		// Convert offsetEnv to uint64
-		var err error
-		offset, err = parseUintEnvVar("OFFSET", offsetEnv)
-		if err != nil {
-			log.Crit("Invalid OFFSET value", "err", err)
-		}
+	nonceGapTolerance := env.uintVar("NONCE_GAP_TOLERANCE", 0)
+
+	bundleBlockRange := env.uintVar("BUNDLE_BLOCK_RANGE", 0)
+	if bundleBlockRange >= ee.MaxBundleBlockRange {
+		env.fail("BUNDLE_BLOCK_RANGE", fmt.Sprintf("must be lower than %d", ee.MaxBundleBlockRange))
 	}
 
+	handleReorgs := env.boolVar("HANDLE_REORGS", false)
+	minBidInterval := env.durationVar("MIN_BID_INTERVAL", 0)
+
+	// Observe heads without bidding for this many heads and this long after startup
+	warmupBlocks := env.uintVar("WARMUP_BLOCKS", 0)
+	warmupDuration := env.durationVar("WARMUP_DURATION", 0)
+
+	// Bid amount bounds, as decimal amounts in BID_AMOUNT_UNIT (ETH by default), and transfer value in ETH
+	bidAmountUnit, minBidAmount, maxBidAmount := bidAmountsFromEnv(env)
+	bidStrategy, bidStrategyName := bidStrategyFromEnv(env, bidAmountUnit, minBidAmount, maxBidAmount, authAcct.Address)
+	txValue := env.etherVar("TX_VALUE", "0.001")
+
+	// Skip heads whose base fee is above the maximum, if one is set
+	maxBaseFee := env.gweiVar("MAX_BASE_FEE_GWEI")
+
+	txOpts, feeOracleName := txOptionsFromEnv(env)
+	requireProviders := env.boolVar("REQUIRE_PROVIDERS", false)
+	numBlobs := numBlobsFromEnv(env, txOpts.MaxBlobs)
+
 	// these variables are not required
 	ethTransfer := os.Getenv("ETH_TRANSFER")
 	blob := os.Getenv("BLOB")
@@ -90,24 +216,320 @@ func main() {
 		log.Crit("Only one of --ethtransfer or --blob can be set at a time")
 	}
 
-	// Log configuration values (excluding sensitive data)
-	log.Info("Configuration values",
-		"bidderAddress", bidderAddress,
-		"rpcEndpoint", rpcEndpoint,
-		"wsEndpoint", wsEndpoint,
-		"offset", offset,
-		"usePayload", usePayload,
-	)
+	decay := bidDecayFromEnv(env)
 
-	authAcct, err := bb.AuthenticateAddress(privateKeyHex)
+	// Pay the builder directly with a transfer to its coinbase appended to each bundle, if configured
+	coinbasePayment, builderCoinbase := coinbasePaymentFromEnv(env, usesHash)
+
+	// Retry bids that fail with a transient error this many times, within the cycle timeout
+	bidMaxRetries := env.uintVar("BID_MAX_RETRIES", 0)
+
+	// Retry building a transaction and sending a bundle this many times when they fail
+	txBuildMaxRetries := env.uintVar("TX_BUILD_MAX_RETRIES", 0)
+	bundleMaxRetries := env.uintVar("BUNDLE_MAX_RETRIES", 0)
+
+	// Bound the retries of all steps of a cycle together, aborting the cycle once they are spent
+	cycleRetryBudget := env.uintVar("CYCLE_RETRY_BUDGET", 0)
+	cycleRetryWait := env.durationVar("CYCLE_RETRY_WAIT", 0)
+
+	// Count a bid as successful only once it received this many commitments
+	minCommitments := env.uintVar("MIN_COMMITMENTS", 1)
+	if minCommitments == 0 {
+		env.fail("MIN_COMMITMENTS", "must be at least 1")
+		minCommitments = 1
+	}
+
+	// Stop bidding for the rest of a window once this much was bid in it
+	maxSpendPerWindow := env.weiVar("MAX_SPEND_PER_WINDOW_WEI")
+
+	// Alert when the share of accepted bids stays below a threshold for a while
+	acceptanceThreshold := env.fractionVar("ACCEPTANCE_ALERT_THRESHOLD", 0)
+	acceptanceWindow := env.uintVar("ACCEPTANCE_WINDOW", defaultAcceptanceWindow)
+	if acceptanceWindow == 0 {
+		env.fail("ACCEPTANCE_WINDOW", "must be at least 1")
+		acceptanceWindow = defaultAcceptanceWindow
+	}
+	acceptanceAlertAfter := env.durationVar("ACCEPTANCE_ALERT_AFTER", 10*time.Minute)
+	alertWebhookURL := os.Getenv("ALERT_WEBHOOK_URL")
+
+	// Skip bids on a transaction already bid on within this long, zero disables the check
+	bidDedupTTL := env.durationVar("BID_DEDUP_TTL", bb.DefaultBidDedupTTL)
+
+	// Return from a bid after this many commitments instead of waiting for the stream to end
+	returnAfter := env.uintVar("RETURN_AFTER_COMMITMENTS", 0)
+	if returnAfter != 0 && returnAfter < minCommitments {
+		env.fail("RETURN_AFTER_COMMITMENTS", fmt.Sprintf("must be at least MIN_COMMITMENTS (%d)", minCommitments))
+	}
+
+	// Save bid requests and commitments to files, or don't persist them at all
+	bidStorage := bidStorageFromEnv(env)
+
+	// Start bids without waiting for their commitments, so slow bids don't hold up the next head
+	asyncBids := env.boolVar("ASYNC_BIDS", false)
+
+	// Bound the bids running at once, skipping a bid that waited this long for a free slot
+	maxInFlightBids := env.uintVar("MAX_INFLIGHT_BIDS", defaultMaxInFlightBids)
+	if maxInFlightBids == 0 {
+		env.fail("MAX_INFLIGHT_BIDS", "must be at least 1")
+		maxInFlightBids = defaultMaxInFlightBids
+	}
+	inFlightWait := env.durationVar("INFLIGHT_BID_WAIT", 0)
+
+	// Record the observed heads, or replay recorded ones instead of subscribing to the node
+	headerRecordFile := os.Getenv("HEADER_RECORD_FILE")
+	headerReplayFile := os.Getenv("HEADER_REPLAY_FILE")
+	headerReplayInterval := env.durationVar("HEADER_REPLAY_INTERVAL", slotDuration)
+
+	// Log the routine per-block messages only every N blocks, warnings and errors are always logged
+	sampler := logSampler{every: env.uintVar("LOG_SAMPLE_EVERY_N", 0)}
+
+	// Check that committed transactions land within this many blocks after their target block
+	verifyInclusionEnabled := env.boolVar("VERIFY_INCLUSION", false)
+	inclusionTolerance := env.uintVar("INCLUSION_TOLERANCE", 0)
+
+	// Tune the offset from where committed transactions land, within bounds
+	adaptiveOffset := env.boolVar("ADAPTIVE_OFFSET", false)
+	offsetMin := env.uintVar("OFFSET_MIN", 1)
+	offsetMax := env.uintVar("OFFSET_MAX", max(offset, 5))
+	offsetDamping := env.fractionVar("OFFSET_DAMPING", defaultOffsetDamping)
+	if adaptiveOffset {
+		if !verifyInclusionEnabled {
+			env.fail("ADAPTIVE_OFFSET", "requires VERIFY_INCLUSION to observe where transactions land")
+		}
+		if offset < offsetMin || offset > offsetMax {
+			env.fail("OFFSET", fmt.Sprintf("must be between OFFSET_MIN (%d) and OFFSET_MAX (%d) with ADAPTIVE_OFFSET", offsetMin, offsetMax))
+		}
+	}
+
+	// Delay each bid by a random time up to this, zero bids right away
+	bidJitterMax := time.Duration(env.uintVar("BID_JITTER_MS", 0)) * time.Millisecond
+
+	// Deadlines for connecting to nodes, for calls to the bidder node and contracts, and for bundles
+	dialTimeout := env.positiveDurationVar("DIAL_TIMEOUT", bb.DefaultDialTimeout)
+	callTimeout := env.positiveDurationVar("CALL_TIMEOUT", bb.DefaultCallTimeout)
+	bundleCfg := bundleConfigFromEnv(env)
+	bundleCfg.Relays = []string{rpcEndpoint}
+
+	// Abandon bid cycles that take longer than this, zero waits for every cycle to finish
+	cycleTimeout := env.durationVar("CYCLE_TIMEOUT", 0)
+
+	// Broadcast payload transactions publicly when their bid gets no commitment, if enabled
+	var publicFallbackTimeout time.Duration
+	if env.boolVar("PUBLIC_FALLBACK", false) {
+		publicFallbackTimeout = env.positiveDurationVar("PUBLIC_FALLBACK_TIMEOUT", 24*time.Second) // two slots by default
+	}
+
+	// Wait for the bidder deposit on the mev-commit chain before bidding, if requested
+	depositWait := env.durationVar("WAIT_FOR_DEPOSIT", 0)
+
+	// Keep the bidding window this many windows ahead funded, zero disables it
+	fundWindowLookahead := env.uintVar("FUND_WINDOW_LOOKAHEAD", 0)
+
+	// Measure the blocks per window from the window transitions instead of assuming the default
+	detectWindowSize := env.boolVar("DETECT_BLOCKS_PER_WINDOW", false)
+
+	mevCommitRPCEndpoint := os.Getenv("MEV_COMMIT_RPC_ENDPOINT")
+	if mevCommitRPCEndpoint == "" {
+		if depositWait > 0 {
+			env.fail("MEV_COMMIT_RPC_ENDPOINT", "is required when WAIT_FOR_DEPOSIT is set")
+		}
+		if fundWindowLookahead > 0 {
+			env.fail("MEV_COMMIT_RPC_ENDPOINT", "is required when FUND_WINDOW_LOOKAHEAD is set")
+		}
+		if detectWindowSize {
+			env.fail("MEV_COMMIT_RPC_ENDPOINT", "is required when DETECT_BLOCKS_PER_WINDOW is set")
+		}
+	}
+
+	// Report ready on /healthz only once enough providers are connected, polling the count this often
+	minProviders := env.uintVar("MIN_PROVIDERS", 1)
+	providerPollInterval := env.durationVar("PROVIDER_POLL_INTERVAL", 30*time.Second)
+
+	// Hold off bidding while the node is syncing, checking its progress this often
+	checkNodeSync, syncCheckInterval := nodeSyncCheckFromEnv(env, slotDuration)
+
+	// Report every invalid value at once
+	if err := env.Validate(); err != nil {
+		log.Crit("Invalid configuration", "err", err)
+	}
+
+	// Sign with the account for the overridden chain, for nodes that misreport theirs
+	if txOpts.ChainID != nil {
+		if authAcct, err = authAcct.ForChain(txOpts.ChainID); err != nil {
+			log.Crit("Failed to authenticate private key:", "err", err)
+		}
+	}
+	if blob == "true" {
+		log.Info("Sending blob transactions", "numBlobs", numBlobs)
+		ee.InitKZG()
+	}
+	if competition, ok := bidStrategy.(*bb.CompetitionAwareBidStrategy); ok {
+		if err := watchCompetition(competition); err != nil {
+			log.Crit("Invalid BID_STRATEGY value", "err", err)
+		}
+	}
+
+	bb.SetCallTimeout(callTimeout)
+	bundleClient, err := ee.NewBundleClient(bundleCfg)
 	if err != nil {
-		log.Crit("Failed to authenticate private key:", "err", err)
+		log.Crit("Invalid bundle client configuration", "err", err)
+	}
+
+	acceptance := newAcceptanceMonitor(systemClock{}, newNotifier(alertWebhookURL), acceptanceThreshold, int(acceptanceWindow), acceptanceAlertAfter)
+	windowSize := bb.NewWindowSizeDetector()
+	bidOpts := bidOptions{decay: decay, maxRetries: int(bidMaxRetries), minCommitments: int(minCommitments), budget: newWindowBudget(maxSpendPerWindow, windowSize), acceptance: acceptance}
+	if bidDedupTTL > 0 {
+		bidOpts.dedup = bb.NewBidDeduper(bidDedupTTL, 0)
+	}
+	offsets := newOffsetController(offset, adaptiveOffset, offsetMin, offsetMax, offsetDamping)
+
+	// Record failed bid cycles for replay if a directory is configured
+	var recorder *bb.ReplayRecorder
+	if replayDir := os.Getenv("REPLAY_DIR"); replayDir != "" {
+		recorder = bb.RecordForReplay(replayDir)
+	}
+
+	// Serve the bot status over HTTP if an address is configured
+	status := &botStatus{minProviders: int(minProviders)}
+	statusAddr := os.Getenv("STATUS_ADDR")
+	if statusAddr != "" {
+		// Collect metrics only when they can be scraped
+		metrics.Enabled = true
+		startStatusServer(statusAddr, status)
+	}
+	var syncing *syncGate
+	if checkNodeSync {
+		syncing = newSyncGate(systemClock{}, syncCheckInterval, callTimeout, status)
+	}
+
+	// Log the effective configuration, with sensitive values masked
+	effective := newEffectiveConfig()
+	effective.add("bidderAddress", bidderAddress)
+	effective.add("grpcCompression", os.Getenv("GRPC_COMPRESSION"))
+	effective.addEndpoint("rpcEndpoint", rpcEndpoint)
+	effective.addEndpoint("wsEndpoint", wsEndpoint)
+	effective.addEndpoint("mevCommitRPCEndpoint", mevCommitRPCEndpoint)
+	effective.addSecret("privateKey", privateKeyHex)
+	effective.add("ethTransfer", ethTransfer)
+	effective.add("blob", blob)
+	effective.add("numBlobs", numBlobs)
+	effective.add("offset", offset)
+	effective.add("bundleBlockRange", bundleBlockRange)
+	effective.add("bidHorizon", bidHorizon)
+	effective.add("dialTimeout", dialTimeout)
+	effective.add("callTimeout", callTimeout)
+	effective.add("bundleTimeout", bundleCfg.Timeout)
+	effective.add("bundleMethod", bundleCfg.Method)
+	effective.add("bundleBlobEncoding", bundleCfg.BlobEncoding)
+	effective.add("cycleTimeout", cycleTimeout)
+	effective.add("bidJitter", bidJitterMax)
+	effective.add("logSampleEveryN", sampler.every)
+	effective.add("headerRecordFile", headerRecordFile)
+	effective.add("headerReplayFile", headerReplayFile)
+	effective.add("asyncBids", asyncBids)
+	effective.add("maxInFlightBids", maxInFlightBids)
+	effective.add("inFlightBidWait", inFlightWait)
+	effective.add("bidMaxRetries", bidMaxRetries)
+	effective.add("txBuildMaxRetries", txBuildMaxRetries)
+	effective.add("bundleMaxRetries", bundleMaxRetries)
+	effective.add("cycleRetryBudget", cycleRetryBudget)
+	effective.add("cycleRetryWait", cycleRetryWait)
+	effective.add("minCommitments", minCommitments)
+	effective.add("returnAfterCommitments", returnAfter)
+	effective.add("saveBidData", bidStorage == nil)
+	effective.add("maxSpendPerWindow", maxSpendPerWindow)
+	effective.add("acceptanceAlertThreshold", acceptanceThreshold)
+	effective.add("acceptanceWindow", acceptanceWindow)
+	effective.add("acceptanceAlertAfter", acceptanceAlertAfter)
+	effective.addEndpoint("alertWebhookURL", alertWebhookURL)
+	effective.add("bidDedupTTL", bidDedupTTL)
+	effective.add("verifyInclusion", verifyInclusionEnabled)
+	effective.add("inclusionTolerance", inclusionTolerance)
+	effective.add("adaptiveOffset", adaptiveOffset)
+	effective.add("offsetMin", offsetMin)
+	effective.add("offsetMax", offsetMax)
+	effective.add("offsetDamping", offsetDamping)
+	effective.add("bidDecayToTarget", decay.toTarget)
+	effective.add("bidDecayStartPct", decay.startPct)
+	effective.add("bidDecayEndPct", decay.endPct)
+	effective.add("blobUsePayload", payloads.blob)
+	effective.add("transferUsePayload", payloads.transfer)
+	effective.add("minBidInterval", minBidInterval)
+	effective.add("warmupBlocks", warmupBlocks)
+	effective.add("warmupDuration", warmupDuration)
+	effective.add("bidAmountUnit", bidAmountUnit)
+	effective.add("minBidAmount", minBidAmount)
+	effective.add("maxBidAmount", maxBidAmount)
+	effective.add("bidStrategy", bidStrategyName)
+	effective.add("txValue", txValue)
+	effective.add("priorityFeeMultiplier", txOpts.Fees.PriorityFeeMultiplier)
+	effective.add("maxFeeMultiplier", txOpts.Fees.MaxFeeMultiplier)
+	effective.add("feeCap", txOpts.Fees.FeeCap)
+	effective.add("tipCap", txOpts.Fees.TipCap)
+	effective.add("minTip", txOpts.Fees.MinTip)
+	effective.add("feeOracle", feeOracleName)
+	effective.add("blobRecipient", txOpts.BlobRecipient)
+	effective.add("maxBlobsPerTx", txOpts.MaxBlobs)
+	effective.add("chainIDOverride", txOpts.ChainID)
+	effective.add("maxBaseFee", maxBaseFee)
+	effective.add("coinbasePayment", coinbasePayment)
+	effective.add("builderCoinbase", builderCoinbase)
+	effective.add("nonceGapTolerance", nonceGapTolerance)
+	effective.add("publicFallbackTimeout", publicFallbackTimeout)
+	effective.add("depositWait", depositWait)
+	effective.add("fundWindowLookahead", fundWindowLookahead)
+	effective.add("detectWindowSize", detectWindowSize)
+	effective.add("statusAddr", statusAddr)
+	effective.add("checkNodeSync", checkNodeSync)
+	effective.add("syncCheckInterval", syncCheckInterval)
+	effective.add("minProviders", minProviders)
+	log.Info("Effective configuration", effective.logCtx()...)
+
+	if depositWait > 0 {
+		mevCommitClient, err := bb.NewGethClientWithTimeout(mevCommitRPCEndpoint, dialTimeout)
+		if err != nil {
+			log.Crit("failed to connect to mev-commit chain", "err", err)
+		}
+		if err := bb.WaitForSufficientDeposit(context.Background(), mevCommitClient, authAcct.Address, depositWait); err != nil {
+			log.Crit("bidder deposit is not sufficient", "err", err)
+		}
+		mevCommitClient.Close()
+	}
+
+	if fundWindowLookahead > 0 {
+		mevCommitClient, err := bb.NewGethClientWithTimeout(mevCommitRPCEndpoint, dialTimeout)
+		if err != nil {
+			log.Crit("failed to connect to mev-commit chain", "err", err)
+		}
+		chainID, err := mevCommitClient.ChainID(context.Background())
+		if err != nil {
+			log.Crit("failed to get mev-commit chain ID", "err", err)
+		}
+		fundAcct, err := authAcct.ForChain(chainID)
+		if err != nil {
+			log.Crit("failed to authenticate for the mev-commit chain", "err", err)
+		}
+		go bb.KeepNextWindowFunded(context.Background(), mevCommitClient, &fundAcct, fundWindowLookahead)
+	}
+
+	var windowHeads chan uint64
+	if detectWindowSize {
+		mevCommitClient, err := bb.NewGethClientWithTimeout(mevCommitRPCEndpoint, dialTimeout)
+		if err != nil {
+			log.Crit("failed to connect to mev-commit chain", "err", err)
+		}
+		windowHeads = make(chan uint64, 1)
+		watchWindowSize(mevCommitClient, windowSize, windowHeads)
 	}
 
 	cfg := bb.BidderConfig{
 		ServerAddress: bidderAddress,
 		LogFmt:        "json",
 		LogLevel:      "info",
+		CallTimeout:   callTimeout,
+		Compression:   os.Getenv("GRPC_COMPRESSION"),
+		ReturnAfter:   int(returnAfter),
+		Storage:       bidStorage,
 	}
 
 	bidderClient, err := bb.NewBidderClient(cfg)
@@ -117,12 +539,27 @@ func main() {
 
 	log.Info("connected to mev-commit client")
 
-	timeout := 30 * time.Second
+	// Check which providers the bidder node is peered with, since bids are pointless without any
+	topology, err := bidderClient.Topology(context.Background())
+	switch {
+	case err != nil && requireProviders:
+		log.Crit("failed to query bidder node topology", "err", err)
+	case err != nil:
+		log.Warn("failed to query bidder node topology", "err", err)
+	case len(topology.Providers) == 0 && requireProviders:
+		log.Crit("bidder node is not connected to any providers")
+	default:
+		log.Info("bidder node topology", "providers", len(topology.Providers), "addresses", topology.Providers)
+		status.setProviders(topology.Providers)
+	}
+	if statusAddr != "" && providerPollInterval > 0 {
+		go pollProviders(bidderClient, status, providerPollInterval)
+	}
 
-	// Only connect to the RPC client if usePayload is false
-	if !usePayload {
+	// Only connect to the RPC client when bidding by transaction hash
+	if usesHash {
 		// Connect to RPC client
-		client := connectRPCClientWithRetries(rpcEndpoint, 5, timeout)
+		client := connectRPCClientWithRetries(rpcEndpoint, 5, dialTimeout)
 		if client == nil {
 			log.Error("failed to connect to RPC client", rpcEndpoint)
 		}
@@ -130,81 +567,348 @@ func main() {
 	}
 
 	// Connect to WS client
-	wsClient, err := connectWSClient(wsEndpoint)
+	wsClient, err := connectWSClient(wsEndpoint, dialTimeout)
 	if err != nil {
 		log.Crit("failed to connect to geth client", "err", err)
 	}
 	log.Info("(ws) geth client connected")
 
+	// Refuse to sign transactions for a different chain than the node is on. Transactions are
+	// signed for CHAIN_ID_OVERRIDE, or else for the network ID the node reports, which some nodes
+	// report differently from their chain ID.
+	signingChainID, err := ee.SigningChainID(context.Background(), wsClient, txOpts)
+	if err != nil {
+		log.Crit("failed to get the signing chain ID", "err", err)
+	}
+	if err := bb.VerifyChainID(context.Background(), wsClient, signingChainID); err != nil {
+		log.Crit("refusing to start", "err", err)
+	}
+
+	// Check right away whether the node is still syncing, so it is logged before the first head
+	syncing.check(wsClient)
+
+	// Heads come from the node, or from a recorded sequence when replaying one
+	var blocks ee.BlockSource = wsClient
+	if headerReplayFile != "" {
+		replay, err := ee.NewReplaySource(headerReplayFile, headerReplayInterval)
+		if err != nil {
+			log.Crit("failed to load header replay", "err", err)
+		}
+		log.Info("replaying recorded heads", "file", headerReplayFile, "heads", replay.Len(), "interval", headerReplayInterval)
+		blocks = replay
+	}
+	var headerRecorder *ee.HeaderRecorder
+	if headerRecordFile != "" {
+		headerRecorder, err = ee.NewHeaderRecorder(headerRecordFile)
+		if err != nil {
+			log.Crit("failed to open header recording", "err", err)
+		}
+		defer headerRecorder.Close()
+	}
+
 	headers := make(chan *types.Header)
-	sub, err := wsClient.SubscribeNewHead(context.Background(), headers)
+	sub, err := blocks.SubscribeNewHead(context.Background(), headers)
 	if err != nil {
 		log.Crit("failed to subscribe to new blocks", "err", err)
 	}
 
 	timer := time.NewTimer(24 * 14 * time.Hour)
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	heads := newHeadTracker(handleReorgs)
+	throttle := &bidThrottle{clock: systemClock{}, minInterval: minBidInterval}
+	warm := newWarmup(systemClock{}, warmupBlocks, warmupDuration)
+	cycles := &cycleRunner{timeout: cycleTimeout}
+	jitter := newBidJitter(systemClock{}, bidJitterMax)
+	headLag := newHeadLagMonitor(systemClock{}, offset)
+	nonces := ee.NewNonceManager(authAcct.Address, nonceGapTolerance)
+	dispatcher := newBidDispatcher(asyncBids, newBidLimiter(int(maxInFlightBids), inFlightWait))
+
+	// submitBid sends the bundle if needed and the preconfirmation bid for one signed transaction
+	submitBid := func(ctx context.Context, client *ethclient.Client, header *types.Header, signedTx *types.Transaction, bundle []*types.Transaction, blockNumber uint64, cycleStart time.Time, cycleOffset uint64, retries *bb.RetryBudget) {
+		// Spread the bids out within the block, unless the cycle ends first
+		if err := jitter.wait(ctx); err != nil {
+			log.Warn("dropping bid, the cycle ended during the bid jitter", "block", blockNumber, "err", err)
+			logBidCycle(header.Number.Uint64(), blockNumber, signedTx, nil, nil, time.Since(cycleStart), err)
+			return
+		}
+
+		bidOpts := bidOpts
+		bidOpts.retries = retries
+
+		var bidRequest *pb.Bid
+		var bidResult *bb.BidResult
+		var bundleErr, err error
+		if payloads.usePayload(signedTx) {
+			// If the payload is used for this transaction type, send it to mev-commit. Don't send bundle
+			var fallback *publicFallback
+			if publicFallbackTimeout > 0 {
+				fallback = startPublicFallback(client, signedTx, publicFallbackTimeout)
+			}
+			bidRequest, bidResult, err = sendPreconfBid(ctx, bidderClient, bidStrategy, bidOpts, header, signedTx, int64(blockNumber))
+			if fallback != nil {
+				fallback.resolve(bidOpts.succeeded(bidResult, err))
+			}
+		} else {
+			// send as a flashbots bundle and send the preconf bid with the transaction hash
+			bundleErr = bb.RetryWithBudget(ctx, int(bundleMaxRetries), retries, func(context.Context) error {
+				_, err := bundleClient.SendBundleRange(rpcEndpoint, bundle, blockNumber, blockNumber+bundleBlockRange)
+				return err
+			})
+			if bundleErr != nil {
+				log.Error("Failed to send transaction", "rpcEndpoint", rpcEndpoint, "error", bundleErr)
+				recordFailure(recorder, signedTx, nil, blockNumber, header.BaseFee, bundleErr)
+			}
+			bidRequest, bidResult, err = sendPreconfBid(ctx, bidderClient, bidStrategy, bidOpts, header, signedTx.Hash().String(), int64(blockNumber))
+		}
+
+		if err != nil {
+			recordFailure(recorder, signedTx, bidRequest, blockNumber, header.BaseFee, err)
+		}
+		if verifyInclusionEnabled && bidOpts.succeeded(bidResult, err) {
+			go verifyInclusion(client, header, signedTx, blockNumber, inclusionTolerance, offsets, cycleOffset)
+		}
+		logBidCycle(header.Number.Uint64(), blockNumber, signedTx, bidRequest, bidResult, time.Since(cycleStart), errors.Join(bundleErr, err))
+	}
+
+	// runCycle builds one transaction per target block with contiguous nonces, then bids on them together
+	runCycle := func(ctx context.Context, client *ethclient.Client, header *types.Header) {
+		if err := nonces.Reconcile(ctx, client); err != nil {
+			log.Error("failed to sync nonce", "err", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		var targets []uint64
+		retries := bb.NewRetryBudget(int(cycleRetryBudget), cycleRetryWait)
+		cycleOffset := offsets.current()
+		for i := uint64(0); i < bidHorizon; i++ {
+			// Stop building once the cycle has been abandoned, bids already sent keep running
+			if ctx.Err() != nil {
+				break
+			}
+			if retries.Exhausted() {
+				log.Warn("aborting bid cycle, its retry budget is exhausted", "block", header.Number, "cycleRetryBudget", cycleRetryBudget, "cycleRetryWait", cycleRetryWait)
+				break
+			}
+			cycleStart := time.Now()
+			nonce := nonces.Next()
+			if coinbasePayment != nil {
+				// The coinbase payment follows the transaction, so it takes the next nonce
+				nonces.Next()
+			}
+			opts := txOpts
+			opts.Nonce = &nonce
+
+			var signedTx *types.Transaction
+			var blockNumber uint64
+			err := bb.RetryWithBudget(ctx, int(txBuildMaxRetries), retries, func(context.Context) error {
+				var err error
+				if ethTransfer == "true" {
+					signedTx, blockNumber, err = ee.SelfETHTransfer(client, authAcct, txValue, cycleOffset+i, opts)
+				} else if blob == "true" {
+					// Execute Blob Transaction
+					signedTx, blockNumber, err = ee.ExecuteBlobTransaction(client, authAcct, numBlobs, cycleOffset+i, opts)
+				}
+				return err
+			})
+
+			// Check for errors before using signedTx. Later targets would leave a nonce gap, so stop here.
+			if err != nil {
+				log.Error("failed to execute transaction", "err", err)
+				recordFailure(recorder, nil, nil, blockNumber, header.BaseFee, err)
+				logBidCycle(header.Number.Uint64(), blockNumber, nil, nil, nil, time.Since(cycleStart), err)
+				break
+			}
+
+			if signedTx == nil {
+				log.Error("transaction was not signed or created", "block", header.Number)
+				break
+			}
+
+			bundle := []*types.Transaction{signedTx}
+			if coinbasePayment != nil {
+				bundle, err = ee.AppendCoinbasePayment(bundle, authAcct, builderCoinbase, coinbasePayment)
+				if err != nil {
+					log.Error("failed to build coinbase payment", "err", err)
+					recordFailure(recorder, signedTx, nil, blockNumber, header.BaseFee, err)
+					logBidCycle(header.Number.Uint64(), blockNumber, signedTx, nil, nil, time.Since(cycleStart), err)
+					break
+				}
+			}
+
+			sampler.Info(header.Number.Uint64(), "Transaction fee values",
+				"txHash", signedTx.Hash().String(),
+				"blockNumber", blockNumber,
+				"nonce", nonce)
+
+			dispatched := dispatcher.dispatch(ctx, &wg, func(ctx context.Context) {
+				submitBid(ctx, client, header, signedTx, bundle, blockNumber, cycleStart, cycleOffset, retries)
+			})
+			if !dispatched {
+				log.Warn("dropping bid, no bid slot freed up in time", "block", blockNumber, "maxInFlightBids", maxInFlightBids, "inFlightBidWait", inFlightWait)
+				break
+			}
+			targets = append(targets, blockNumber)
+		}
+		wg.Wait()
+
+		if len(targets) > 0 {
+			sampler.Info(header.Number.Uint64(), "bid on target blocks", "block", header.Number, "targets", targets)
+		}
+	}
 
 	for {
 		select {
 		case <-timer.C:
 			log.Info("Stopping the loop.")
+			dispatcher.wait()
+			bidderClient.Flush()
+			return
+		case sig := <-shutdown:
+			log.Info("Shutting down", "signal", sig)
+			dispatcher.wait()
+			bidderClient.Flush()
 			return
 		case err := <-sub.Err():
+			if headerReplayFile != "" {
+				log.Info("header replay finished", "err", err)
+				dispatcher.wait()
+				bidderClient.Flush()
+				return
+			}
 			log.Warn("subscription error", "err", err)
-			wsClient, sub = reconnectWSClient(wsEndpoint, headers)
+			wsClient, sub = reconnectWSClient(wsEndpoint, dialTimeout, headers)
 			continue
 		case header := <-headers:
-			log.Info("new block generated", "block", header.Number)
-
-			amount := new(big.Int).SetInt64(1e15)
-			var signedTx *types.Transaction
-			var blockNumber uint64
-			if ethTransfer == "true" {
-				signedTx, blockNumber, err = ee.SelfETHTransfer(wsClient, authAcct, amount, offset)
-				println("eth transfer here")
-			} else if blob == "true" {
-				// Execute Blob Transaction
-				signedTx, blockNumber, err = ee.ExecuteBlobTransaction(wsClient, authAcct, NUM_BLOBS, offset)
-				println("blob here?")
+			if headerRecorder != nil {
+				if err := headerRecorder.Record(header); err != nil {
+					log.Warn("failed to record head", "block", header.Number, "err", err)
+				}
+			}
+			if ok, reason := heads.accept(header); !ok {
+				log.Info("skipping head", "block", header.Number, "hash", header.Hash(), "reason", reason)
+				continue
+			}
+			sampler.Info(header.Number.Uint64(), "new block generated", "block", header.Number)
+			if windowHeads != nil {
+				select {
+				case windowHeads <- header.Number.Uint64():
+				default:
+				}
+			}
+			if lag, ok := headLag.observe(header); ok {
+				status.setHeadLag(lag)
 			}
 
-			if signedTx == nil {
-				fmt.Println("Transaction was not signed or created.")
-			} else {
-				// Proceed with the rest of your logic
+			fees := ee.FeeSnapshot(header)
+			sampler.Debug(header.Number.Uint64(), "block fees", "block", header.Number, "baseFee", fees.BaseFee, "blobBaseFee", fees.BlobBaseFee)
+			status.setFees(header.Number.Uint64(), fees)
+
+			if warm.observe(header.Number.Uint64()) {
+				log.Info("skipping bid, warming up", "block", header.Number)
+				continue
 			}
 
-			// Check for errors before using signedTx
-			if err != nil {
-				log.Error("failed to execute transaction", "err", err)
+			if exceedsMaxBaseFee(fees, maxBaseFee) {
+				log.Info("skipping bid, base fee above maximum", "block", header.Number, "baseFee (gwei)", ee.FormatGwei(fees.BaseFee), "maxBaseFee (gwei)", ee.FormatGwei(maxBaseFee))
+				continue
 			}
 
-			log.Info("Transaction fee values",
-				"txHash", signedTx.Hash().String(),
-				"blockNumber", blockNumber)
+			if status.paused.Load() {
+				log.Debug("skipping bid, bidding is paused", "block", header.Number)
+				continue
+			}
 
+			if syncing.check(wsClient) {
+				log.Info("skipping bid, the node is syncing", "block", header.Number)
+				continue
+			}
 
-			if usePayload {
-				// If use-payload is true, send the transaction payload to mev-commit. Don't send bundle
-				sendPreconfBid(bidderClient, signedTx, int64(blockNumber))
-			} else {
-				// send as a flashbots bundle and send the preconf bid with the transaction hash
-				_, err = ee.SendBundle(rpcEndpoint, signedTx, blockNumber)
-				if err != nil {
-					log.Error("Failed to send transaction", "rpcEndpoint", rpcEndpoint, "error", err)
-				}
-				sendPreconfBid(bidderClient, signedTx.Hash().String(), int64(blockNumber))
+			if cycles.busy() {
+				log.Warn("skipping bid, the abandoned bid cycle is still running", "block", header.Number)
+				continue
 			}
 
-			// handle ExecuteBlob error
-			if err != nil {
-				log.Error("failed to execute blob tx", "err", err)
-				continue // Skip to the next endpoint
+			if !throttle.allow() {
+				log.Debug("skipping bid, minimum bid interval not elapsed", "block", header.Number, "minBidInterval", minBidInterval)
+				continue
+			}
+			// Abandon the cycle if it runs past CYCLE_TIMEOUT, so a slow cycle doesn't stall the loop
+			client := wsClient
+			if !cycles.run(func(ctx context.Context) { runCycle(ctx, client, header) }) {
+				log.Warn("abandoning bid cycle, it exceeded the cycle timeout", "block", header.Number, "cycleTimeout", cycleTimeout)
 			}
 		}
 	}
 }
 
+// recordFailure writes a replay recording of a failed bid cycle if a recorder is configured.
+func recordFailure(recorder *bb.ReplayRecorder, signedTx *types.Transaction, bidRequest *pb.Bid, targetBlock uint64, baseFee *big.Int, cause error) {
+	if recorder == nil {
+		return
+	}
+
+	rec := &bb.ReplayRecord{
+		Timestamp:   time.Now().Unix(),
+		BidRequest:  bidRequest,
+		TargetBlock: targetBlock,
+		Error:       cause.Error(),
+	}
+	if baseFee != nil {
+		rec.BaseFee = baseFee.String()
+	}
+	if signedTx != nil {
+		raw, err := signedTx.MarshalBinary()
+		if err == nil {
+			rec.RawTx = hexutil.Encode(raw)
+		}
+		rec.TxHash = signedTx.Hash().Hex()
+		rec.ChainID = signedTx.ChainId().String()
+	}
+
+	if _, err := recorder.Record(rec); err != nil {
+		log.Warn("failed to record bid cycle for replay", "err", err)
+	}
+}
+
+// runReplay loads a recorded bid cycle and re-sends it. Bids made by transaction hash
+// also re-send the recorded transaction as a bundle when RPC_ENDPOINT is set.
+func runReplay(path string) {
+	rec, err := bb.LoadReplayRecord(path)
+	if err != nil {
+		log.Crit("failed to load replay record", "err", err)
+	}
+
+	bidderAddress := os.Getenv("BIDDER_ADDRESS")
+	if bidderAddress == "" {
+		bidderAddress = "mev-commit-bidder:13524"
+	}
+
+	bidderClient, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: bidderAddress})
+	if err != nil {
+		log.Crit("failed to connect to mev-commit bidder API", "err", err)
+	}
+
+	rpcEndpoint := os.Getenv("RPC_ENDPOINT")
+	if rec.BidRequest != nil && len(rec.BidRequest.RawTransactions) == 0 && rpcEndpoint != "" {
+		signedTx, err := rec.Transaction()
+		if err != nil {
+			log.Crit("failed to reconstruct recorded transaction", "err", err)
+		}
+		if _, err := ee.SendBundle(rpcEndpoint, signedTx, rec.TargetBlock); err != nil {
+			log.Error("Failed to send transaction", "rpcEndpoint", rpcEndpoint, "error", err)
+		}
+	}
+
+	_, err = bidderClient.Replay(context.Background(), rec)
+	bidderClient.Flush()
+	if err != nil {
+		log.Crit("failed to replay bid", "err", err)
+	}
+	log.Info("replayed bid", "file", path, "txHash", rec.TxHash)
+}
+
 func connectRPCClientWithRetries(rpcEndpoint string, maxRetries int, timeout time.Duration) *ethclient.Client {
 	var rpcClient *ethclient.Client
 	var err error
@@ -226,24 +930,24 @@ func connectRPCClientWithRetries(rpcEndpoint string, maxRetries int, timeout tim
 	return nil
 }
 
-func connectWSClient(wsEndpoint string) (*ethclient.Client, error) {
-	wsClient, err := bb.NewGethClient(wsEndpoint)
+func connectWSClient(wsEndpoint string, dialTimeout time.Duration) (*ethclient.Client, error) {
+	wsClient, err := bb.NewGethClientWithTimeout(wsEndpoint, dialTimeout)
 	if err != nil {
 		log.Warn("failed to connect to websocket client", "err", err)
 		// sleep for 10 seconds
 		time.Sleep(10 * time.Second)
-		return connectWSClient(wsEndpoint)
+		return connectWSClient(wsEndpoint, dialTimeout)
 	}
 	return wsClient, nil
 }
 
-func reconnectWSClient(wsEndpoint string, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription) {
+func reconnectWSClient(wsEndpoint string, dialTimeout time.Duration, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription) {
 	var wsClient *ethclient.Client
 	var sub ethereum.Subscription
 	var err error
 
 	for i := 0; i < 10; i++ { // Retry logic for WebSocket connection
-		wsClient, err = connectWSClient(wsEndpoint)
+		wsClient, err = connectWSClient(wsEndpoint, dialTimeout)
 		if err == nil {
 			log.Info("(ws) geth client reconnected")
 			sub, err = wsClient.SubscribeNewHead(context.Background(), headers)
@@ -258,60 +962,90 @@ func reconnectWSClient(wsEndpoint string, headers chan *types.Header) (*ethclien
 	return nil, nil
 }
 
-func sendPreconfBid(bidderClient *bb.Bidder, input interface{}, blockNumber int64) {
-	// Seed the random number generator
-	rand.Seed(uint64(time.Now().UnixNano()))
-
-	// Generate a random number
-	minAmount := 0.04
-	maxAmount := 0.11
-	randomEthAmount := minAmount + rand.Float64()*(maxAmount-minAmount)
+func sendPreconfBid(ctx context.Context, bidderClient *bb.Bidder, bidStrategy bb.BidStrategy, opts bidOptions, head *types.Header, input interface{}, blockNumber int64) (*pb.Bid, *bb.BidResult, error) {
+	if opts.dedup != nil {
+		if txHash, ok := bidTxHash(input); ok && !opts.dedup.Observe(txHash) {
+			log.Warn("skipping duplicate bid", "tx", txHash, "block", blockNumber)
+			return nil, nil, errDuplicateBid
+		}
+	}
 
-	// Convert the random ETH amount to wei (1 ETH = 10^18 wei)
-	randomWeiAmount := int64(randomEthAmount * 1e18)
+	bidAmount, err := bidStrategy.BidAmount(uint64(blockNumber))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !opts.budget.spend(uint64(blockNumber), bidAmount) {
+		return nil, nil, errBidBudgetExhausted
+	}
 
 	// Convert the amount to a string for the bidder
-	amount := fmt.Sprintf("%d", randomWeiAmount)
-
-	// Get current time in milliseconds
-	currentTime := time.Now().UnixMilli()
+	amount := bidAmount.String()
 
-	// Define bid decay start and end
-	decayStart := currentTime
-	decayEnd := currentTime + int64(time.Duration(36*time.Second).Milliseconds()) // bid decay is 36 seconds (2 blocks)
+	// Define bid decay start and end in milliseconds
+	decayStart, decayEnd := opts.decay.window(time.Now(), head, uint64(blockNumber))
 
 	// Determine how to handle the input
-	var err error
+	var bidRequest *pb.Bid
 	switch v := input.(type) {
 	case string:
 		// Input is a string, process it as a transaction hash
 		txHash := strings.TrimPrefix(v, "0x")
 		log.Info("sending bid with transaction hash", "tx", input)
-		// Send the bid with tx hash string
-		_, err = bidderClient.SendBid([]string{txHash}, amount, blockNumber, decayStart, decayEnd)
+		// Build the bid with tx hash string
+		bidRequest, err = bb.NewBidRequest([]string{txHash}, amount, blockNumber, decayStart, decayEnd)
 
 	case *types.Transaction:
 		// Input is a transaction object, send the transaction object
 		log.Info("sending bid with tx payload", "tx", input.(*types.Transaction).Hash().String())
-		// Send the bid with the full transaction object
-		_, err = bidderClient.SendBid([]*types.Transaction{v}, amount, blockNumber, decayStart, decayEnd)
+		// Build the bid with the full transaction object
+		bidRequest, err = bb.NewBidRequest([]*types.Transaction{v}, amount, blockNumber, decayStart, decayEnd)
 
 	default:
 		log.Warn("unsupported input type, must be string or *types.Transaction")
-		return
+		return nil, nil, fmt.Errorf("unsupported input type: %T", input)
 	}
-
 	if err != nil {
+		log.Warn("failed to build bid", "err", err)
+		return nil, nil, err
+	}
+
+	result, err := bidderClient.SendBidRequestWithRetryBudget(ctx, bidRequest, opts.maxRetries, opts.retries)
+	bidStrategy.Observe(uint64(blockNumber), opts.recordBidOutcome(blockNumber, result, err))
+	if errors.Is(err, bb.ErrNoCommitment) {
+		log.Warn("sent preconfirmation bid, but no provider committed to it", "block", blockNumber, "amount (ETH)", ee.FormatEther(bidAmount))
+	} else if err != nil {
 		log.Warn("failed to send bid", "err", err)
 	} else {
-		log.Info("sent preconfirmation bid", "block", blockNumber, "amount (ETH)", randomEthAmount)
+		log.Info("sent preconfirmation bid", "block", blockNumber, "amount (ETH)", ee.FormatEther(bidAmount), "amount (wei)", amount)
+	}
+	return bidRequest, result, err
+}
+
+// bidTxHash returns the hash of the transaction a bid input refers to, either a transaction hash
+// or a transaction.
+func bidTxHash(input interface{}) (common.Hash, bool) {
+	switch v := input.(type) {
+	case string:
+		return common.HexToHash(v), true
+	case *types.Transaction:
+		return v.Hash(), true
+	default:
+		return common.Hash{}, false
 	}
 }
 
 func parseBoolEnvVar(name, value string) (bool, error) {
 	parsedValue, err := strconv.ParseBool(value)
 	if err != nil {
-		return false, fmt.Errorf("environment variable %s must be true or false, got '%s'", name, value)
+		return false, &envError{Name: name, Value: value, Reason: "must be true or false"}
+	}
+	return parsedValue, nil
+}
+
+func parseFloatEnvVar(name, value string) (float64, error) {
+	parsedValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s must be a number, got '%s'", name, value)
 	}
 	return parsedValue, nil
 }
@@ -319,7 +1053,15 @@ func parseBoolEnvVar(name, value string) (bool, error) {
 func parseUintEnvVar(name, value string) (uint64, error) {
 	parsedValue, err := strconv.ParseUint(value, 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("environment variable %s must be a positive integer, got '%s'", name, value)
+		return 0, &envError{Name: name, Value: value, Reason: "must be a positive integer"}
+	}
+	return parsedValue, nil
+}
+
+func parseDurationEnvVar(name, value string) (time.Duration, error) {
+	parsedValue, err := time.ParseDuration(value)
+	if err != nil || parsedValue < 0 {
+		return 0, fmt.Errorf("environment variable %s must be a non-negative duration (e.g. 6s), got '%s'", name, value)
 	}
 	return parsedValue, nil
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// headHistory is the number of recent block hashes kept to tell reorgs from stale heads.
+const headHistory = 64
+
+// headTracker filters the heads delivered by the subscription so that each block is bid on
+// once. Heads that are not strictly newer than the last processed head are skipped, unless
+// they replace a processed block (a reorg) and reorg handling is enabled.
+type headTracker struct {
+	handleReorgs bool                   // Process heads that replace an already processed block.
+	last         uint64                 // Number of the last processed head.
+	hashes       map[uint64]common.Hash // Hashes of recently processed heads by number.
+}
+
+// newHeadTracker creates a headTracker.
+func newHeadTracker(handleReorgs bool) *headTracker {
+	return &headTracker{
+		handleReorgs: handleReorgs,
+		hashes:       make(map[uint64]common.Hash),
+	}
+}
+
+// accept reports whether header should be processed. When it should not, the returned reason
+// is "duplicate", "stale" or "reorg" (a reorg that is not being handled).
+func (t *headTracker) accept(header *types.Header) (bool, string) {
+	number := header.Number.Uint64()
+	hash := header.Hash()
+
+	if len(t.hashes) > 0 && number <= t.last {
+		seen, ok := t.hashes[number]
+		switch {
+		case ok && seen == hash:
+			return false, "duplicate"
+		case !ok:
+			return false, "stale"
+		case !t.handleReorgs:
+			return false, "reorg"
+		}
+
+		// The head replaces a processed block; forget the reverted blocks above it
+		for n := number + 1; n <= t.last; n++ {
+			delete(t.hashes, n)
+		}
+	}
+
+	t.last = number
+	t.hashes[number] = hash
+	if number >= headHistory {
+		delete(t.hashes, number-headHistory)
+	}
+	return true, ""
+}
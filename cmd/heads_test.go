@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testHeader returns a header for block number, made distinct by extra.
+func testHeader(number uint64, extra byte) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte{extra}}
+}
+
+func TestHeadTracker(t *testing.T) {
+	type step struct {
+		header *types.Header
+		want   bool
+		reason string
+	}
+	tests := []struct {
+		name         string
+		handleReorgs bool
+		steps        []step
+	}{
+		{
+			name: "in order",
+			steps: []step{
+				{header: testHeader(10, 0), want: true},
+				{header: testHeader(11, 0), want: true},
+				{header: testHeader(13, 0), want: true}, // A missed head is not waited for.
+			},
+		},
+		{
+			name: "duplicate",
+			steps: []step{
+				{header: testHeader(10, 0), want: true},
+				{header: testHeader(10, 0), reason: "duplicate"},
+			},
+		},
+		{
+			name: "out of order",
+			steps: []step{
+				{header: testHeader(10, 0), want: true},
+				{header: testHeader(12, 0), want: true},
+				{header: testHeader(11, 0), reason: "stale"},
+				{header: testHeader(9, 0), reason: "stale"},
+			},
+		},
+		{
+			name: "reorg ignored",
+			steps: []step{
+				{header: testHeader(10, 0), want: true},
+				{header: testHeader(11, 0), want: true},
+				{header: testHeader(11, 1), reason: "reorg"},
+				{header: testHeader(12, 0), want: true},
+			},
+		},
+		{
+			name:         "reorg handled",
+			handleReorgs: true,
+			steps: []step{
+				{header: testHeader(10, 0), want: true},
+				{header: testHeader(11, 0), want: true},
+				{header: testHeader(12, 0), want: true},
+				{header: testHeader(11, 1), want: true},
+				{header: testHeader(11, 1), reason: "duplicate"},
+				{header: testHeader(12, 1), want: true}, // Block 12 was reverted, so its replacement is new.
+			},
+		},
+		{
+			name: "older than the history",
+			steps: []step{
+				{header: testHeader(10, 0), want: true},
+				{header: testHeader(10+headHistory, 0), want: true},
+				{header: testHeader(10, 0), reason: "stale"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newHeadTracker(tt.handleReorgs)
+			for i, s := range tt.steps {
+				got, reason := tracker.accept(s.header)
+				if got != s.want || reason != s.reason {
+					t.Fatalf("step %d: accept(%d) = %v, %q, want %v, %q", i, s.header.Number, got, reason, s.want, s.reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
+	"os"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// ANSI escape sequences used to colorize the inspect output.
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// runInspect prints a readable summary of the saved bid requests and the commitments received
+// for them. Commitments are matched to a bid by block number and bid amount.
+func runInspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	bidsFile := flags.String("bids", bb.DefaultBidRequestsFile, "file the bid requests were saved to")
+	responsesFile := flags.String("responses", bb.DefaultBidResponsesFile, "file the bid responses were saved to")
+	fromBlock := flags.Int64("from", 0, "first block to show")
+	toBlock := flags.Int64("to", 0, "last block to show, 0 for no limit")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	requests, err := bb.LoadBidRequests(*bidsFile)
+	if err != nil {
+		return err
+	}
+	commitments, err := bb.LoadBidResponses(*responsesFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	color := os.Getenv("NO_COLOR") == ""
+	shown := 0
+	for _, req := range requests {
+		bid := req.BidRequest
+		if bid == nil || bid.BlockNumber < *fromBlock || (*toBlock > 0 && bid.BlockNumber > *toBlock) {
+			continue
+		}
+		printBid(os.Stdout, req, matchCommitments(bid, commitments), color)
+		shown++
+	}
+	fmt.Printf("%d of %d bids shown\n", shown, len(requests))
+	return nil
+}
+
+// matchCommitments returns the commitments received for the bid.
+func matchCommitments(bid *pb.Bid, commitments []*pb.Commitment) []*pb.Commitment {
+	var matched []*pb.Commitment
+	for _, c := range commitments {
+		if c.BlockNumber == bid.BlockNumber && c.BidAmount == bid.Amount {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// printBid writes the summary of one bid and its commitments to w.
+func printBid(w io.Writer, req bb.SavedBidRequest, commitments []*pb.Commitment, color bool) {
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return code + text + colorReset
+	}
+
+	bid := req.BidRequest
+	amount := bid.Amount
+	if wei, ok := new(big.Int).SetString(bid.Amount, 10); ok {
+		amount = ee.FormatEther(wei) + " ETH"
+	}
+
+	status := paint(colorRed, "no commitments")
+	if len(commitments) > 0 {
+		status = paint(colorGreen, fmt.Sprintf("%d commitment(s)", len(commitments)))
+	}
+
+	fmt.Fprintf(w, "%s  block %s  %s  %s\n",
+		time.Unix(req.Timestamp, 0).Format(time.DateTime),
+		paint(colorBold, fmt.Sprint(bid.BlockNumber)),
+		paint(colorYellow, amount),
+		status,
+	)
+	for _, c := range commitments {
+		fmt.Fprintf(w, "    provider %s  dispatched %s\n",
+			c.ProviderAddress,
+			time.UnixMilli(c.DispatchTimestamp).Format(time.DateTime),
+		)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// bidJitter delays each bid by a random time up to max, so that many bots do not all bid the
+// instant a head arrives.
+type bidJitter struct {
+	clock clock               // Source of the delay.
+	max   time.Duration       // The longest delay. Zero disables the jitter.
+	rand  func(n int64) int64 // Returns a random number in [0, n).
+}
+
+// newBidJitter creates a bidJitter with delays up to max on the given clock.
+func newBidJitter(clock clock, max time.Duration) bidJitter {
+	return bidJitter{clock: clock, max: max, rand: rand.Int63n}
+}
+
+// delay returns a random delay in [0, max].
+func (j bidJitter) delay() time.Duration {
+	if j.max <= 0 {
+		return 0
+	}
+	return time.Duration(j.rand(int64(j.max) + 1))
+}
+
+// wait sleeps for a random delay, or returns the context error if ctx is done first.
+func (j bidJitter) wait(ctx context.Context) error {
+	d := j.delay()
+	if d == 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-j.clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
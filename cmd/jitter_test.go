@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose time only moves when the test advances it.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration // The durations passed to After, in order.
+	fire  bool            // Whether After fires right away.
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if c.fire {
+		ch <- c.now.Add(d)
+	}
+	return ch
+}
+
+func TestBidJitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       time.Duration
+		random    int64 // The random number drawn.
+		cancelled bool
+		wantDelay time.Duration
+		wantErr   error
+	}{
+		{name: "disabled", max: 0, wantDelay: 0},
+		{name: "no delay drawn", max: time.Second, random: 0, wantDelay: 0},
+		{name: "delay", max: time.Second, random: int64(300 * time.Millisecond), wantDelay: 300 * time.Millisecond},
+		{name: "longest delay", max: time.Second, random: int64(time.Second), wantDelay: time.Second},
+		{name: "cancelled while waiting", max: time.Second, random: int64(time.Millisecond), cancelled: true, wantDelay: time.Millisecond, wantErr: context.Canceled},
+		{name: "cancelled without delay", max: 0, cancelled: true, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{fire: !tt.cancelled}
+			j := newBidJitter(clock, tt.max)
+			var bound int64
+			j.rand = func(n int64) int64 {
+				bound = n
+				return tt.random
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			if err := j.wait(ctx); err != tt.wantErr {
+				t.Fatalf("wait error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The delay is drawn from [0, max], and only waited for when it is not zero
+			if tt.max > 0 && bound != int64(tt.max)+1 {
+				t.Fatalf("random bound = %d, want %d", bound, int64(tt.max)+1)
+			}
+			var wantWaits []time.Duration
+			if tt.wantDelay > 0 {
+				wantWaits = []time.Duration{tt.wantDelay}
+			}
+			if len(clock.waits) != len(wantWaits) || (len(wantWaits) == 1 && clock.waits[0] != wantWaits[0]) {
+				t.Fatalf("waited %v, want %v", clock.waits, wantWaits)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// runLadder bids on one zero-value transfer to self with several amounts for the same block, and
+// prints how many commitments each amount received. It is meant for price discovery: the lowest
+// committed amount hints at what providers currently accept.
+func runLadder(args []string) error {
+	flags := flag.NewFlagSet("ladder", flag.ContinueOnError)
+	amountsFlag := flags.String("amounts", "", "comma-separated bid amounts, e.g. 0.01,0.02,0.04")
+	unit := flags.String("unit", "eth", "unit of the amounts: wei, gwei or eth")
+	offset := flags.Uint64("offset", 1, "blocks ahead of the current head to target")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *amountsFlag == "" {
+		return errors.New("--amounts is required")
+	}
+	var amounts []string
+	for _, value := range strings.Split(*amountsFlag, ",") {
+		amount, err := ee.ParseAmount(strings.TrimSpace(value), *unit)
+		if err != nil {
+			return fmt.Errorf("invalid amount '%s': %w", value, err)
+		}
+		amounts = append(amounts, amount.String())
+	}
+	if missing := missingEnvVars("WS_ENDPOINT"); len(missing) > 0 {
+		return fmt.Errorf("required environment variables are not set: %v", missing)
+	}
+
+	privateKeyHex, err := privateKeyFromEnv()
+	if err != nil {
+		return err
+	}
+	authAcct, err := bb.AuthenticateAddress(privateKeyHex)
+	if err != nil {
+		return err
+	}
+	chainID, err := chainIDOverrideFromEnv()
+	if err != nil {
+		return err
+	}
+	client, err := bb.NewGethClient(os.Getenv("WS_ENDPOINT"))
+	if err != nil {
+		return fmt.Errorf("failed to connect to geth client: %w", err)
+	}
+	defer client.Close()
+
+	tx, target, err := ee.SelfETHTransfer(client, authAcct, new(big.Int), *offset, ee.TxOptions{ChainID: chainID})
+	if err != nil {
+		return err
+	}
+
+	bidderAddress := os.Getenv("BIDDER_ADDRESS")
+	if bidderAddress == "" {
+		bidderAddress = "mev-commit-bidder:13524"
+	}
+	bidderClient, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: bidderAddress})
+	if err != nil {
+		return fmt.Errorf("failed to connect to mev-commit bidder API: %w", err)
+	}
+	defer bidderClient.Flush()
+
+	now := time.Now()
+	rungs, err := bidderClient.SendBidLadder(context.Background(), []*types.Transaction{tx}, int64(target), amounts, now.UnixMilli(), now.Add(fixedBidDecay).UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("tx %s, target block %d\n", tx.Hash(), target)
+	var failed int
+	for _, rung := range rungs {
+		amount, _ := new(big.Int).SetString(rung.Amount, 10)
+		switch {
+		case rung.Err != nil && !errors.Is(rung.Err, bb.ErrNoCommitment):
+			failed++
+			fmt.Printf("%-12s ETH  error: %v\n", ee.FormatEther(amount), rung.Err)
+		default:
+			fmt.Printf("%-12s ETH  %d commitments\n", ee.FormatEther(amount), len(rung.Result.Commitments))
+		}
+	}
+	if failed == len(rungs) {
+		return fmt.Errorf("all %d bids failed", failed)
+	}
+	return nil
+}
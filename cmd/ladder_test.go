@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunLadderRejectsInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		vars    map[string]string
+		wantErr string
+	}{
+		{name: "unknown flag", args: []string{"-amounts", "0.01", "-blobs", "1"}, wantErr: "flag provided but not defined"},
+		{name: "no amounts", wantErr: "--amounts is required"},
+		{name: "invalid amount", args: []string{"-amounts", "0.01,lots"}, wantErr: "invalid amount 'lots'"},
+		{name: "empty rung", args: []string{"-amounts", "0.01,,0.02"}, wantErr: "invalid amount ''"},
+		{name: "unknown unit", args: []string{"-amounts", "1", "-unit", "finney"}, wantErr: "unknown unit"},
+		{name: "finer than the unit", args: []string{"-amounts", "1.5", "-unit", "wei"}, wantErr: "invalid amount '1.5'"},
+		{name: "no endpoint", args: []string{"-amounts", "0.01"}, vars: map[string]string{"WS_ENDPOINT": ""}, wantErr: "WS_ENDPOINT"},
+		{
+			name:    "invalid chain ID override",
+			args:    []string{"-amounts", "0.01"},
+			vars:    map[string]string{"PRIVATE_KEY": "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", "CHAIN_ID_OVERRIDE": "zero"},
+			wantErr: "CHAIN_ID_OVERRIDE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WS_ENDPOINT", "ws://127.0.0.1:1")
+			setenv(t, tt.vars)
+			err := runLadder(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("runLadder error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
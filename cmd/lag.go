@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// slotDuration is the time between two L1 blocks.
+	slotDuration = 12 * time.Second
+	// lagSamples is how many heads are observed before judging the offset.
+	lagSamples = 5
+	// maxSafeLag is the head observation lag above which a bid for the next block is unlikely
+	// to reach providers while that block is still being built.
+	maxSafeLag = slotDuration / 2
+)
+
+// headLagMonitor measures how long after its timestamp each head is observed and warns once if
+// the lag makes the configured offset too small for bids to land.
+type headLagMonitor struct {
+	clock   clock           // Source of the current time.
+	offset  uint64          // The configured number of blocks ahead to bid for.
+	samples []time.Duration // Lags measured so far, up to lagSamples.
+	median  time.Duration   // The median lag once lagSamples heads were observed.
+	warned  bool            // Whether the offset warning was already logged.
+}
+
+// newHeadLagMonitor creates a headLagMonitor for the configured offset.
+func newHeadLagMonitor(clock clock, offset uint64) *headLagMonitor {
+	return &headLagMonitor{clock: clock, offset: offset}
+}
+
+// observe records the lag of a newly observed head. It returns the median lag and true once
+// enough heads were sampled; the median is not updated after that.
+func (m *headLagMonitor) observe(header *types.Header) (time.Duration, bool) {
+	if len(m.samples) < lagSamples {
+		lag := m.clock.Now().Sub(time.Unix(int64(header.Time), 0))
+		m.samples = append(m.samples, lag)
+		if len(m.samples) < lagSamples {
+			return 0, false
+		}
+
+		sorted := append([]time.Duration(nil), m.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		m.median = sorted[len(sorted)/2]
+		m.check()
+	}
+	return m.median, true
+}
+
+// check warns once if the median lag leaves too little of the slot for the configured offset.
+func (m *headLagMonitor) check() {
+	if m.warned {
+		return
+	}
+	// Each block of offset beyond the first gives the bid one more slot to arrive
+	available := time.Duration(m.offset)*slotDuration - slotDuration + maxSafeLag
+	if m.median <= available {
+		return
+	}
+
+	suggested := m.offset + uint64((m.median-available+slotDuration-1)/slotDuration)
+	log.Warn("Heads are observed late, the configured OFFSET is likely too small for bids to be accepted",
+		"medianLag", m.median, "offset", m.offset, "suggestedOffset", suggested)
+	m.warned = true
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// runMempool bids by hash on the transactions entering the mempool of the node at WS_ENDPOINT,
+// instead of building and sending its own. MEMPOOL_MIN_VALUE and MEMPOOL_TO select the
+// transactions, MEMPOOL_BID_INTERVAL and MEMPOOL_MAX_INFLIGHT bound the rate of bids, and the
+// amounts come from the bid strategy like in the main bot. It needs no private key and runs until
+// interrupted.
+func runMempool() error {
+	if missing := missingEnvVars("WS_ENDPOINT"); len(missing) > 0 {
+		return fmt.Errorf("required environment variables are not set: %v", missing)
+	}
+	bidderAddress := os.Getenv("BIDDER_ADDRESS")
+	if bidderAddress == "" {
+		bidderAddress = "mev-commit-bidder:13524"
+	}
+
+	env := &envConfig{}
+	filter := mempoolFilterFromEnv(env)
+	cfg := bb.MempoolBidderConfig{
+		Filter:      filter,
+		Offset:      env.uintVar("OFFSET", 1),
+		MinInterval: env.durationVar("MEMPOOL_BID_INTERVAL", 0),
+		MaxInFlight: int(env.uintVar("MEMPOOL_MAX_INFLIGHT", bb.DefaultMempoolMaxInFlight)),
+	}
+	if cfg.MaxInFlight == 0 {
+		env.fail("MEMPOOL_MAX_INFLIGHT", "must be at least 1")
+	}
+	unit, minAmount, maxAmount := bidAmountsFromEnv(env)
+	// The strategy only needs the bidder's own address to ignore its commitments as competition
+	var self common.Address
+	if privateKeyHex, err := privateKeyFromEnv(); err == nil {
+		if authAcct, err := bb.AuthenticateAddress(privateKeyHex); err == nil {
+			self = authAcct.Address
+		}
+	}
+	var strategyName string
+	cfg.Strategy, strategyName = bidStrategyFromEnv(env, unit, minAmount, maxAmount, self)
+	if err := env.Validate(); err != nil {
+		return err
+	}
+
+	if competition, ok := cfg.Strategy.(*bb.CompetitionAwareBidStrategy); ok {
+		if err := watchCompetition(competition); err != nil {
+			return err
+		}
+	}
+
+	client, err := bb.NewGethClient(os.Getenv("WS_ENDPOINT"))
+	if err != nil {
+		return fmt.Errorf("failed to connect to websocket client: %w", err)
+	}
+	defer client.Close()
+
+	bidderClient, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: bidderAddress})
+	if err != nil {
+		return fmt.Errorf("failed to connect to mev-commit bidder API: %w", err)
+	}
+	defer bidderClient.Flush()
+
+	mempool, err := bb.NewMempoolBidder(bidderClient, bb.NewPendingTxSource(client.Client()), client, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("Bidding on mempool transactions",
+		"wsEndpoint", os.Getenv("WS_ENDPOINT"),
+		"minValue", filter.MinValue,
+		"to", filter.To,
+		"offset", cfg.Offset,
+		"interval", cfg.MinInterval,
+		"maxInFlight", cfg.MaxInFlight,
+		"strategy", strategyName,
+	)
+	if err := mempool.Run(ctx, nil); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	log.Info("Stopped bidding on mempool transactions")
+	return nil
+}
+
+// mempoolFilterFromEnv returns the filter set by MEMPOOL_MIN_VALUE, in ETH, and MEMPOOL_TO, a
+// comma-separated list of recipient addresses. An unset variable does not filter.
+func mempoolFilterFromEnv(env *envConfig) bb.MempoolFilter {
+	var filter bb.MempoolFilter
+	if os.Getenv("MEMPOOL_MIN_VALUE") != "" {
+		filter.MinValue = env.etherVar("MEMPOOL_MIN_VALUE", "0")
+	}
+	toEnv := os.Getenv("MEMPOOL_TO")
+	if toEnv == "" {
+		return filter
+	}
+	for _, value := range strings.Split(toEnv, ",") {
+		value = strings.TrimSpace(value)
+		if !common.IsHexAddress(value) {
+			env.invalid("MEMPOOL_TO", fmt.Errorf("'%s' is not an address", value))
+			return filter
+		}
+		filter.To = append(filter.To, common.HexToAddress(value))
+	}
+	return filter
+}
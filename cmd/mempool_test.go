@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMempoolFilterFromEnv(t *testing.T) {
+	a := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	b := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	tests := []struct {
+		name     string
+		vars     map[string]string
+		minValue string // Empty for no minimum.
+		to       []common.Address
+		wantErr  bool
+	}{
+		{name: "unset", vars: map[string]string{}},
+		{name: "minimum value", vars: map[string]string{"MEMPOOL_MIN_VALUE": "0.5"}, minValue: "500000000000000000"},
+		{name: "recipients", vars: map[string]string{"MEMPOOL_TO": a.Hex() + ", " + b.Hex()}, to: []common.Address{a, b}},
+		{name: "invalid minimum value", vars: map[string]string{"MEMPOOL_MIN_VALUE": "lots"}, minValue: "0", wantErr: true},
+		{name: "invalid recipient", vars: map[string]string{"MEMPOOL_TO": a.Hex() + ",0x1234"}, to: []common.Address{a}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, map[string]string{"MEMPOOL_MIN_VALUE": "", "MEMPOOL_TO": ""})
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			filter := mempoolFilterFromEnv(env)
+			minValue := ""
+			if filter.MinValue != nil {
+				minValue = filter.MinValue.String()
+			}
+			if minValue != tt.minValue || !reflect.DeepEqual(filter.To, tt.to) {
+				t.Errorf("mempoolFilterFromEnv = %s, %v, want %s, %v", minValue, filter.To, tt.minValue, tt.to)
+			}
+			if err := env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// notifyTimeout bounds how long a webhook notification may take.
+const notifyTimeout = 10 * time.Second
+
+// notifier delivers alerts about the health of the bot to its operator.
+type notifier interface {
+	notify(msg string) error
+}
+
+// newNotifier returns a notifier posting to webhookURL, or one that only logs the alerts if
+// webhookURL is empty.
+func newNotifier(webhookURL string) notifier {
+	if webhookURL == "" {
+		return logNotifier{}
+	}
+	return &webhookNotifier{url: webhookURL, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+// logNotifier logs alerts as warnings.
+type logNotifier struct{}
+
+// notify logs msg.
+func (logNotifier) notify(msg string) error {
+	log.Warn("alert", "msg", msg)
+	return nil
+}
+
+// webhookNotifier posts alerts as {"text": msg} JSON, the payload Slack and compatible incoming
+// webhooks accept. Alerts are logged as well.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// notify logs msg and posts it to the webhook.
+func (n *webhookNotifier) notify(msg string) error {
+	log.Warn("alert", "msg", msg)
+	body, err := json.Marshal(map[string]string{"text": msg})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
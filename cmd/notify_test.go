@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusBadRequest, wantErr: true},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request %s with content type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode alert: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			n := newNotifier(server.URL)
+			if err := n.notify("bid acceptance rate is 0%"); (err != nil) != tt.wantErr {
+				t.Fatalf("notify error = %v, want error %v", err, tt.wantErr)
+			}
+			if got["text"] != "bid acceptance rate is 0%" || len(got) != 1 {
+				t.Fatalf("posted %v, want the alert as text", got)
+			}
+		})
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	if _, ok := newNotifier("").(logNotifier); !ok {
+		t.Fatal("newNotifier without a webhook does not only log")
+	}
+	if err := newNotifier("").notify("alert"); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	// An unreachable webhook fails the notification
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	if err := newNotifier(server.URL).notify("alert"); err == nil {
+		t.Fatal("notify succeeded with an unreachable webhook")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// runObserve listens for CommitmentStored events on the mev-commit chain and logs and saves each
+// one, without bidding. Settlements of opened commitments are logged too. It needs no private key
+// and runs until interrupted. With EVENTS_ADDR set, the commitments are also streamed as
+// Server-Sent Events on /events. With VERIFY_COMMITMENT_SIGNATURES set, commitments that were
+// not signed by their committer are reported.
+func runObserve() error {
+	// The commitments are stored on the mev-commit chain
+	wsEndpoint := os.Getenv("MEV_COMMIT_WS_ENDPOINT")
+	if wsEndpoint == "" {
+		wsEndpoint = os.Getenv("WS_ENDPOINT")
+	}
+	if wsEndpoint == "" {
+		return fmt.Errorf("MEV_COMMIT_WS_ENDPOINT or WS_ENDPOINT environment variable is required")
+	}
+
+	var confirmationDepth uint64
+	if depthEnv := os.Getenv("CONFIRMATION_DEPTH"); depthEnv != "" {
+		var err error
+		confirmationDepth, err = parseUintEnvVar("CONFIRMATION_DEPTH", depthEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	var verifySignatures bool
+	if verifyEnv := os.Getenv("VERIFY_COMMITMENT_SIGNATURES"); verifyEnv != "" {
+		var err error
+		verifySignatures, err = parseBoolEnvVar("VERIFY_COMMITMENT_SIGNATURES", verifyEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := bb.NewGethClient(wsEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to websocket client: %w", err)
+	}
+	defer client.Close()
+
+	listener, err := bb.NewCommitmentListener(client, bb.ListenerConfig{ConfirmationDepth: confirmationDepth})
+	if err != nil {
+		return err
+	}
+	settlementListener, err := bb.NewSettlementListener(client, common.Address{})
+	if err != nil {
+		return err
+	}
+	storage := bb.NewFileStorage(bb.DefaultBidRequestsFile, bb.DefaultBidResponsesFile, bb.DefaultObservedCommitmentsFile)
+
+	var broker *eventBroker
+	if eventsAddr := os.Getenv("EVENTS_ADDR"); eventsAddr != "" {
+		broker = newEventBroker()
+		startEventServer(eventsAddr, broker)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	events := make(chan bb.CommitmentEvent)
+	settlements := make(chan bb.SettlementEvent)
+	runErr := make(chan error, 2)
+	go func() {
+		runErr <- listener.Run(ctx, events)
+	}()
+	go func() {
+		runErr <- settlementListener.Run(ctx, settlements)
+	}()
+
+	log.Info("Observing commitments", "wsEndpoint", wsEndpoint, "confirmationDepth", confirmationDepth, "file", bb.DefaultObservedCommitmentsFile)
+	for {
+		select {
+		case e := <-events:
+			observed := bb.NewObservedCommitment(e, time.Now())
+			log.Info("Commitment observed",
+				"status", observed.Status,
+				"commitmentIndex", observed.CommitmentIndex,
+				"bidder", observed.Bidder,
+				"committer", observed.Committer,
+				"bid", observed.Bid,
+				"block", observed.BlockNumber,
+				"bidHash", observed.BidHash,
+				"txnHash", observed.TxnHash,
+			)
+			if verifySignatures {
+				verifyCommitmentSignature(e)
+			}
+			storage.SaveObservedCommitment(observed)
+			if broker != nil {
+				broker.publish(observed)
+			}
+		case s := <-settlements:
+			log.Info("Commitment settled",
+				"outcome", s.Outcome,
+				"commitmentDigest", common.Hash(s.CommitmentDigest),
+				"bidder", s.Bidder,
+				"provider", s.Provider,
+				"window", s.Window,
+				"amount", s.Amount,
+				"removed", s.Log.Removed,
+			)
+		case err := <-runErr:
+			if errors.Is(err, context.Canceled) {
+				log.Info("Stopped observing commitments")
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// verifyCommitmentSignature logs an error for a commitment whose signature is not its committer's,
+// and a warning if the signature cannot be checked.
+func verifyCommitmentSignature(e bb.CommitmentEvent) {
+	valid, err := bb.VerifyCommitmentSignature(&e.Event, e.Event.Commiter)
+	switch {
+	case err != nil:
+		log.Warn("Failed to verify commitment signature", "commitmentIndex", common.Hash(e.Event.CommitmentIndex), "committer", e.Event.Commiter, "err", err)
+	case !valid:
+		log.Error("Commitment not signed by its committer", "commitmentIndex", common.Hash(e.Event.CommitmentIndex), "committer", e.Event.Commiter, "txHash", e.Log.TxHash)
+	default:
+		log.Debug("Commitment signature verified", "commitmentIndex", common.Hash(e.Event.CommitmentIndex), "committer", e.Event.Commiter)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// defaultOffsetDamping is the weight of each new inclusion in the adaptive offset estimate.
+	defaultOffsetDamping = 0.2
+	// offsetMinSamples is how many inclusions are observed before the offset is first adjusted.
+	offsetMinSamples = 5
+)
+
+// offsetController tunes the offset bids target from the inclusions of committed transactions.
+// Each inclusion is a sample of the offset that would have hit the block the transaction landed
+// in: the offset of its cycle plus how many blocks after its target it landed. A transaction that
+// did not land counts as needing one more block of lead. The samples are smoothed with an
+// exponential moving average weighted by damping, and once offsetMinSamples were observed the
+// offset moves at most one block per inclusion towards the rounded estimate, within [min, max].
+// When not adaptive it keeps the configured offset. It is safe for concurrent use.
+type offsetController struct {
+	mu       sync.Mutex
+	adaptive bool    // Whether inclusions adjust the offset.
+	offset   uint64  // The offset bids target.
+	min, max uint64  // The bounds of the offset.
+	damping  float64 // The weight of a new sample in the estimate, in (0, 1].
+	estimate float64 // The smoothed ideal offset.
+	samples  int     // The inclusions observed so far.
+}
+
+// newOffsetController creates an offsetController starting at offset.
+func newOffsetController(offset uint64, adaptive bool, min, max uint64, damping float64) *offsetController {
+	return &offsetController{adaptive: adaptive, offset: offset, min: min, max: max, damping: damping}
+}
+
+// current returns the offset the next cycle should target.
+func (c *offsetController) current() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offset
+}
+
+// observe records where a transaction bid with the cycle offset cycleOffset landed: in block
+// landed if it was included, or nowhere near targetBlock if landed is zero. It returns the
+// offset after the observation.
+func (c *offsetController) observe(cycleOffset, targetBlock, landed uint64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.adaptive {
+		return c.offset
+	}
+
+	sample := float64(cycleOffset + 1)
+	if landed != 0 {
+		sample = math.Max(float64(cycleOffset)+float64(landed)-float64(targetBlock), 0)
+	}
+	if c.samples == 0 {
+		c.estimate = sample
+	} else {
+		c.estimate += c.damping * (sample - c.estimate)
+	}
+	c.samples++
+	if c.samples < offsetMinSamples {
+		return c.offset
+	}
+
+	target := uint64(math.Round(c.estimate))
+	target = max(c.min, min(c.max, target))
+	previous := c.offset
+	switch {
+	case target > c.offset:
+		c.offset++
+	case target < c.offset:
+		c.offset--
+	default:
+		return c.offset
+	}
+	log.Info("adjusted offset from observed inclusions", "from", previous, "to", c.offset, "estimate", math.Round(c.estimate*100)/100, "samples", c.samples)
+	return c.offset
+}
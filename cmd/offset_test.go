@@ -0,0 +1,109 @@
+package main
+
+import "testing"
+
+func TestOffsetController(t *testing.T) {
+	// inclusion is where the transaction of a cycle bid with offset landed, zero if it did not
+	type inclusion struct {
+		offset, target, landed uint64
+		want                   uint64 // The offset after the inclusion.
+	}
+	late := func(offset, want uint64) inclusion {
+		return inclusion{offset: offset, target: 100, landed: 102, want: want}
+	}
+	tests := []struct {
+		name       string
+		offset     uint64
+		adaptive   bool
+		min, max   uint64
+		damping    float64
+		inclusions []inclusion
+	}{
+		{
+			name: "not adaptive", offset: 2, min: 1, max: 5, damping: 0.2,
+			inclusions: []inclusion{late(2, 2), late(2, 2), late(2, 2), late(2, 2), late(2, 2), late(2, 2)},
+		},
+		{
+			name: "landing late raises the offset", offset: 2, adaptive: true, min: 1, max: 5, damping: 0.2,
+			inclusions: []inclusion{
+				late(2, 2), late(2, 2), late(2, 2), late(2, 2), late(2, 3),
+				{offset: 3, target: 100, landed: 101, want: 4},
+				{offset: 4, target: 100, landed: 100, want: 4},
+			},
+		},
+		{
+			name: "not landing asks for one more block", offset: 2, adaptive: true, min: 1, max: 5, damping: 0.2,
+			inclusions: []inclusion{
+				{offset: 2, target: 100, want: 2},
+				{offset: 2, target: 100, want: 2},
+				{offset: 2, target: 100, want: 2},
+				{offset: 2, target: 100, want: 2},
+				{offset: 2, target: 100, want: 3},
+				{offset: 3, target: 100, want: 3},
+			},
+		},
+		{
+			name: "landing early lowers the offset", offset: 4, adaptive: true, min: 1, max: 5, damping: 0.2,
+			inclusions: []inclusion{
+				{offset: 4, target: 100, landed: 98, want: 4},
+				{offset: 4, target: 100, landed: 98, want: 4},
+				{offset: 4, target: 100, landed: 98, want: 4},
+				{offset: 4, target: 100, landed: 98, want: 4},
+				{offset: 4, target: 100, landed: 98, want: 3},
+				{offset: 3, target: 100, landed: 99, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+			},
+		},
+		{
+			name: "held at the maximum", offset: 3, adaptive: true, min: 1, max: 3, damping: 0.2,
+			inclusions: []inclusion{late(3, 3), late(3, 3), late(3, 3), late(3, 3), late(3, 3), late(3, 3)},
+		},
+		{
+			name: "held at the minimum", offset: 1, adaptive: true, min: 1, max: 5, damping: 0.2,
+			inclusions: []inclusion{
+				{offset: 1, target: 100, landed: 90, want: 1},
+				{offset: 1, target: 100, landed: 90, want: 1},
+				{offset: 1, target: 100, landed: 90, want: 1},
+				{offset: 1, target: 100, landed: 90, want: 1},
+				{offset: 1, target: 100, landed: 90, want: 1},
+			},
+		},
+		{
+			name: "damping smooths an outlier", offset: 2, adaptive: true, min: 1, max: 10, damping: 0.1,
+			inclusions: []inclusion{
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 104, want: 2},
+			},
+		},
+		{
+			name: "one block per inclusion", offset: 2, adaptive: true, min: 1, max: 10, damping: 1,
+			inclusions: []inclusion{
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 100, want: 2},
+				{offset: 2, target: 100, landed: 104, want: 3},
+				{offset: 3, target: 100, landed: 103, want: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newOffsetController(tt.offset, tt.adaptive, tt.min, tt.max, tt.damping)
+			if got := c.current(); got != tt.offset {
+				t.Fatalf("current = %d, want %d", got, tt.offset)
+			}
+			for i, in := range tt.inclusions {
+				if got := c.observe(in.offset, in.target, in.landed); got != in.want {
+					t.Fatalf("inclusion %d: offset = %d, want %d", i, got, in.want)
+				}
+				if got := c.current(); got != in.want {
+					t.Fatalf("inclusion %d: current = %d, want %d", i, got, in.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// Metric names of the bid outcomes. They are only collected when go-ethereum metrics are enabled.
+const (
+	bidSuccessMetric = "bot/bid/success"
+	bidFailureMetric = "bot/bid/failure"
+	// bidIncludedMetric and bidNotIncludedMetric count committed transactions by whether they
+	// landed within the inclusion tolerance of their target block.
+	bidIncludedMetric    = "bot/bid/included"
+	bidNotIncludedMetric = "bot/bid/notincluded"
+)
+
+// bidOptions holds the settings applied to each preconfirmation bid.
+type bidOptions struct {
+	decay          bidDecay           // Decides the decay window of the bid.
+	maxRetries     int                // Retries of a bid failing with a transient error.
+	retries        *bb.RetryBudget    // Shared by the retries of all steps of a cycle. Nil leaves them unbounded.
+	minCommitments int                // Commitments a bid needs to count as successful.
+	budget         *windowBudget      // Caps the amount bid per window.
+	dedup          *bb.BidDeduper     // Skips transactions already bid on. Nil disables the check.
+	acceptance     *acceptanceMonitor // Alerts when few bids are accepted. Nil disables the alert.
+}
+
+// succeeded reports whether a bid received at least minCommitments commitments without failing.
+// A bid without any commitment is not a failure of its own, it only counts against minCommitments.
+func (o bidOptions) succeeded(result *bb.BidResult, err error) bool {
+	return (err == nil || errors.Is(err, bb.ErrNoCommitment)) && result != nil && len(result.Commitments) >= o.minCommitments
+}
+
+// recordBidOutcome classifies a bid as a success or failure, logs its commitment count against
+// the threshold and counts the outcome. It returns whether the bid succeeded.
+func (o bidOptions) recordBidOutcome(blockNumber int64, result *bb.BidResult, err error) bool {
+	commitments := 0
+	if result != nil {
+		commitments = len(result.Commitments)
+	}
+
+	success := o.succeeded(result, err)
+	if success {
+		metrics.GetOrRegisterCounter(bidSuccessMetric, nil).Inc(1)
+	} else {
+		metrics.GetOrRegisterCounter(bidFailureMetric, nil).Inc(1)
+	}
+	log.Info("bid outcome", "block", blockNumber, "success", success, "commitments", commitments, "minCommitments", o.minCommitments)
+	o.acceptance.observe(success)
+	return success
+}
+
+// verifyInclusion checks that a committed transaction landed within tolerance blocks after its
+// target block, logs the result and counts it, and tells offsets where the transaction of a cycle
+// bid with cycleOffset landed. It gives up a couple of slots after the tolerated range has passed,
+// in case the node stops following the chain.
+func verifyInclusion(client ee.ReceiptReader, head *types.Header, tx *types.Transaction, targetBlock, tolerance uint64, offsets *offsetController, cycleOffset uint64) {
+	slots := tolerance + 2
+	if headBlock := head.Number.Uint64(); targetBlock > headBlock {
+		slots += targetBlock - headBlock
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(slots)*slotDuration)
+	defer cancel()
+
+	included, block, err := ee.VerifyInclusion(ctx, client, tx.Hash(), targetBlock, tolerance)
+	if err != nil {
+		log.Warn("failed to verify inclusion", "txHash", tx.Hash(), "targetBlock", targetBlock, "err", err)
+		return
+	}
+	offsets.observe(cycleOffset, targetBlock, block)
+	if included {
+		metrics.GetOrRegisterCounter(bidIncludedMetric, nil).Inc(1)
+		log.Info("committed transaction included", "txHash", tx.Hash(), "targetBlock", targetBlock, "block", block)
+		return
+	}
+	metrics.GetOrRegisterCounter(bidNotIncludedMetric, nil).Inc(1)
+	log.Warn("committed transaction not included near its target block", "txHash", tx.Hash(), "targetBlock", targetBlock, "block", block, "tolerance", tolerance)
+}
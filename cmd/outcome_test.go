@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+func TestBidOutcomeSucceeded(t *testing.T) {
+	result := func(commitments int) *bb.BidResult {
+		return &bb.BidResult{Commitments: make([]*pb.Commitment, commitments)}
+	}
+	tests := []struct {
+		name           string
+		minCommitments int
+		result         *bb.BidResult
+		err            error
+		want           bool
+	}{
+		{name: "one of one", minCommitments: 1, result: result(1), want: true},
+		{name: "more than required", minCommitments: 2, result: result(3), want: true},
+		{name: "fewer than required", minCommitments: 3, result: result(2), want: false},
+		{name: "no commitment", minCommitments: 1, result: result(0), err: bb.ErrNoCommitment, want: false},
+		{name: "no commitment required", minCommitments: 0, result: result(0), err: fmt.Errorf("bid ended: %w", bb.ErrNoCommitment), want: true},
+		{name: "failed after commitments", minCommitments: 1, result: result(2), err: errors.New("stream reset"), want: false},
+		{name: "no result", minCommitments: 0, err: errors.New("bidder node unavailable"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := bidOptions{minCommitments: tt.minCommitments}
+			if got := opts.succeeded(tt.result, tt.err); got != tt.want {
+				t.Fatalf("succeeded = %v, want %v", got, tt.want)
+			}
+			if got := opts.recordBidOutcome(100, tt.result, tt.err); got != tt.want {
+				t.Fatalf("recordBidOutcome = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBidTxHash(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 7, Gas: 21000})
+	hash := common.HexToHash("0xabcd")
+	tests := []struct {
+		name   string
+		input  interface{}
+		want   common.Hash
+		wantOK bool
+	}{
+		{name: "hash", input: hash.Hex(), want: hash, wantOK: true},
+		{name: "hash without prefix", input: hash.Hex()[2:], want: hash, wantOK: true},
+		{name: "transaction", input: tx, want: tx.Hash(), wantOK: true},
+		{name: "transactions", input: []*types.Transaction{tx}},
+		{name: "nil", input: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bidTxHash(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("bidTxHash = %s, %v, want %s, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "github.com/ethereum/go-ethereum/core/types"
+
+// payloadPolicy decides per transaction type whether a bid carries the signed transaction payload
+// or only its hash, in which case the transaction is sent separately as a bundle.
+//
+// With the defaults, USE_PAYLOAD decides for every type:
+//
+//	USE_PAYLOAD  BLOB_USE_PAYLOAD  TRANSFER_USE_PAYLOAD  blob tx   transfer
+//	true         unset             unset                 payload   payload
+//	false        unset             unset                 hash      hash
+//	any          true              false                 payload   hash
+//	any          false             true                  hash      payload
+type payloadPolicy struct {
+	blob     bool // Whether bids on blob transactions carry the payload.
+	transfer bool // Whether bids on other transactions carry the payload.
+}
+
+// usePayload reports whether the bid on tx should carry the payload.
+func (p payloadPolicy) usePayload(tx *types.Transaction) bool {
+	if tx.Type() == types.BlobTxType {
+		return p.blob
+	}
+	return p.transfer
+}
+
+// usesHash reports whether bids on the selected transaction type are made by hash, which needs
+// RPC_ENDPOINT to send the bundle.
+func (p payloadPolicy) usesHash(blob bool) bool {
+	if blob {
+		return !p.blob
+	}
+	return !p.transfer
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestPayloadPolicy(t *testing.T) {
+	blobTx := types.NewTx(&types.BlobTx{})
+	transfers := []*types.Transaction{types.NewTx(&types.LegacyTx{}), types.NewTx(&types.DynamicFeeTx{})}
+
+	tests := []struct {
+		name                  string
+		policy                payloadPolicy
+		blobPayload, transfer bool // Whether bids on each type carry the payload.
+	}{
+		{name: "payload for all", policy: payloadPolicy{blob: true, transfer: true}, blobPayload: true, transfer: true},
+		{name: "hash for all", policy: payloadPolicy{}},
+		{name: "payload for blobs only", policy: payloadPolicy{blob: true}, blobPayload: true},
+		{name: "payload for transfers only", policy: payloadPolicy{transfer: true}, transfer: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.usePayload(blobTx); got != tt.blobPayload {
+				t.Errorf("usePayload(blob tx) = %v, want %v", got, tt.blobPayload)
+			}
+			for _, tx := range transfers {
+				if got := tt.policy.usePayload(tx); got != tt.transfer {
+					t.Errorf("usePayload(type %d tx) = %v, want %v", tx.Type(), got, tt.transfer)
+				}
+			}
+			// Bidding by hash needs RPC_ENDPOINT for the bundle
+			if got := tt.policy.usesHash(true); got != !tt.blobPayload {
+				t.Errorf("usesHash(blob) = %v, want %v", got, !tt.blobPayload)
+			}
+			if got := tt.policy.usesHash(false); got != !tt.transfer {
+				t.Errorf("usesHash(transfer) = %v, want %v", got, !tt.transfer)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// runResubmit sends the saved bid requests to the bidder node again. Malformed entries are
+// skipped. With --block or --shift the bids target other blocks, and their decay windows are
+// moved to start at submission, keeping their length.
+func runResubmit(args []string) error {
+	flags := flag.NewFlagSet("resubmit", flag.ContinueOnError)
+	bidsFile := flags.String("bids", bb.DefaultBidRequestsFile, "file the bid requests were saved to")
+	block := flags.Int64("block", 0, "target this block with every bid, 0 keeps the saved blocks")
+	shift := flags.Int64("shift", 0, "add this many blocks to the saved block of every bid")
+	interval := flags.Duration("interval", 0, "pause between bids")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *block < 0 {
+		return fmt.Errorf("invalid block %d", *block)
+	}
+	if *block != 0 && *shift != 0 {
+		return errors.New("--block and --shift cannot be used together")
+	}
+
+	saved, err := bb.LoadBidRequests(*bidsFile)
+	if err != nil {
+		return err
+	}
+	bids, err := bb.ReconstructBids(saved)
+	if err != nil {
+		log.Warn("skipping malformed bids", "file", *bidsFile, "skipped", len(saved)-len(bids), "err", err)
+	}
+	if len(bids) == 0 {
+		return fmt.Errorf("no valid bids in %s", *bidsFile)
+	}
+
+	bidderAddress := os.Getenv("BIDDER_ADDRESS")
+	if bidderAddress == "" {
+		bidderAddress = "mev-commit-bidder:13524"
+	}
+	bidderClient, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: bidderAddress})
+	if err != nil {
+		return fmt.Errorf("failed to connect to mev-commit bidder API: %w", err)
+	}
+	defer bidderClient.Flush()
+
+	var failed int
+	for i, bid := range bids {
+		if i > 0 && *interval > 0 {
+			time.Sleep(*interval)
+		}
+		if retarget(bid, *block, *shift, time.Now()) {
+			log.Debug("retargeted bid", "block", bid.BlockNumber, "decayStart", bid.DecayStartTimestamp, "decayEnd", bid.DecayEndTimestamp)
+		}
+		if bid.BlockNumber <= 0 {
+			log.Warn("skipping bid shifted before the first block", "index", i, "block", bid.BlockNumber)
+			failed++
+			continue
+		}
+		result, err := bidderClient.SendBidRequest(context.Background(), bid)
+		if err != nil {
+			log.Error("failed to resubmit bid", "index", i, "block", bid.BlockNumber, "err", err)
+			failed++
+			continue
+		}
+		log.Info("resubmitted bid", "index", i, "block", bid.BlockNumber, "amount", bid.Amount, "commitments", len(result.Commitments))
+	}
+
+	log.Info("resubmitted saved bids", "file", *bidsFile, "sent", len(bids)-failed, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d bids failed", failed, len(bids))
+	}
+	return nil
+}
+
+// retarget points the bid at block, or shifts its block by shift, and restarts its decay window
+// at now when the block changed. It reports whether the bid was changed.
+func retarget(bid *pb.Bid, block, shift int64, now time.Time) bool {
+	target := bid.BlockNumber + shift
+	if block != 0 {
+		target = block
+	}
+	if target == bid.BlockNumber {
+		return false
+	}
+	bid.BlockNumber = target
+	decay := bid.DecayEndTimestamp - bid.DecayStartTimestamp
+	bid.DecayStartTimestamp = now.UnixMilli()
+	bid.DecayEndTimestamp = bid.DecayStartTimestamp + decay
+	return true
+}
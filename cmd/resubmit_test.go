@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+func TestRetarget(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	tests := []struct {
+		name        string
+		block       int64
+		shift       int64
+		wantChanged bool
+		wantBlock   int64
+	}{
+		{name: "unchanged", wantBlock: 100},
+		{name: "block", block: 200, wantChanged: true, wantBlock: 200},
+		{name: "same block", block: 100, wantBlock: 100},
+		{name: "shift forward", shift: 5, wantChanged: true, wantBlock: 105},
+		{name: "shift back", shift: -100, wantChanged: true, wantBlock: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bid := &pb.Bid{BlockNumber: 100, DecayStartTimestamp: 1_000, DecayEndTimestamp: 13_000}
+			if changed := retarget(bid, tt.block, tt.shift, now); changed != tt.wantChanged {
+				t.Fatalf("retarget = %v, want %v", changed, tt.wantChanged)
+			}
+			if bid.BlockNumber != tt.wantBlock {
+				t.Fatalf("block = %d, want %d", bid.BlockNumber, tt.wantBlock)
+			}
+
+			// Retargeted bids decay from now, over the same length
+			wantStart, wantEnd := int64(1_000), int64(13_000)
+			if tt.wantChanged {
+				wantStart, wantEnd = now.UnixMilli(), now.UnixMilli()+12_000
+			}
+			if bid.DecayStartTimestamp != wantStart || bid.DecayEndTimestamp != wantEnd {
+				t.Fatalf("decay = %d to %d, want %d to %d", bid.DecayStartTimestamp, bid.DecayEndTimestamp, wantStart, wantEnd)
+			}
+		})
+	}
+}
+
+func TestRunResubmitRejectsInvalidInput(t *testing.T) {
+	dir := t.TempDir()
+	malformed := filepath.Join(dir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`[{"timestamp":1,"bidRequest":{"amount":"-1","blockNumber":10,"txHashes":["0x01"]}}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	notJSON := filepath.Join(dir, "bids.txt")
+	if err := os.WriteFile(notJSON, []byte("bids"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "unknown flag", args: []string{"-blocks", "1"}, wantErr: "flag provided but not defined"},
+		{name: "negative block", args: []string{"-block", "-1"}, wantErr: "invalid block"},
+		{name: "block and shift", args: []string{"-block", "10", "-shift", "1"}, wantErr: "cannot be used together"},
+		{name: "missing file", args: []string{"-bids", filepath.Join(dir, "missing.json")}, wantErr: "failed to read bid requests"},
+		{name: "not json", args: []string{"-bids", notJSON}, wantErr: "failed to decode bid requests"},
+		{name: "only malformed bids", args: []string{"-bids", malformed}, wantErr: "no valid bids"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runResubmit(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("runResubmit error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "github.com/ethereum/go-ethereum/log"
+
+// logSampler logs the routine per-block messages of the main loop only every n blocks, to keep
+// long runs readable. Warnings and errors are logged directly and never sampled.
+type logSampler struct {
+	every uint64 // Log on blocks that are a multiple of every. Zero or one logs every block.
+}
+
+// sampled reports whether the routine messages of block are logged.
+func (s logSampler) sampled(block uint64) bool {
+	return s.every <= 1 || block%s.every == 0
+}
+
+// Info logs msg at info level if block is sampled.
+func (s logSampler) Info(block uint64, msg string, ctx ...interface{}) {
+	if s.sampled(block) {
+		log.Info(msg, ctx...)
+	}
+}
+
+// Debug logs msg at debug level if block is sampled.
+func (s logSampler) Debug(block uint64, msg string, ctx ...interface{}) {
+	if s.sampled(block) {
+		log.Debug(msg, ctx...)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestLogSamplerSampled(t *testing.T) {
+	tests := []struct {
+		every uint64
+		block uint64
+		want  bool
+	}{
+		{every: 0, block: 7, want: true},
+		{every: 1, block: 7, want: true},
+		{every: 10, block: 100, want: true},
+		{every: 10, block: 0, want: true},
+		{every: 10, block: 101, want: false},
+		{every: 10, block: 109, want: false},
+		{every: 3, block: 9, want: true},
+	}
+	for _, tt := range tests {
+		if got := (logSampler{every: tt.every}).sampled(tt.block); got != tt.want {
+			t.Errorf("sampled(%d) every %d = %v, want %v", tt.block, tt.every, got, tt.want)
+		}
+	}
+}
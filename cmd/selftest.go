@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// Outcomes of a self-test step.
+const (
+	stepPass = "PASS"
+	stepFail = "FAIL"
+	stepSkip = "SKIP"
+)
+
+// selftestStep is the outcome of one step of the self-test.
+type selftestStep struct {
+	name   string        // What the step checks.
+	status string        // stepPass, stepFail or stepSkip.
+	detail string        // What was found, or why the step failed or was skipped.
+	took   time.Duration // How long the step ran.
+}
+
+// selftest runs the steps of the self-test in order and collects their outcomes.
+type selftest struct {
+	steps  []selftestStep
+	failed bool // Whether a step failed.
+}
+
+// run runs fn as the named step and reports whether it passed. The detail fn returns is shown
+// for a passing step, its error for a failing one.
+func (s *selftest) run(name string, fn func() (string, error)) bool {
+	start := time.Now()
+	detail, err := fn()
+	step := selftestStep{name: name, status: stepPass, detail: detail, took: time.Since(start)}
+	if err != nil {
+		step.status = stepFail
+		step.detail = err.Error()
+		s.failed = true
+	}
+	s.steps = append(s.steps, step)
+	return err == nil
+}
+
+// skip records the named step as skipped for reason.
+func (s *selftest) skip(name, reason string) {
+	s.steps = append(s.steps, selftestStep{name: name, status: stepSkip, detail: reason})
+}
+
+// report writes one line per step to w and returns an error if a step failed.
+func (s *selftest) report(w io.Writer) error {
+	for _, step := range s.steps {
+		fmt.Fprintf(w, "%-4s  %-22s %8s  %s\n", step.status, step.name, step.took.Round(time.Millisecond), step.detail)
+	}
+	if s.failed {
+		fmt.Fprintln(w, "self-test failed")
+		return errors.New("self-test failed")
+	}
+	fmt.Fprintln(w, "self-test passed")
+	return nil
+}
+
+// runSelftest runs one full bid cycle against a devnet and reports each step: connecting to
+// WS_ENDPOINT, building a transaction, checking (and with --deposit making) the bidder deposit on
+// MEV_COMMIT_RPC_ENDPOINT, bidding through BIDDER_ADDRESS, sending a bundle to RPC_ENDPOINT with
+// --bundle, and verifying inclusion. The transaction is a zero-value transfer to self, so the
+// test only spends gas and the bid amount and can be run repeatedly.
+func runSelftest(args []string) error {
+	flags := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	amount := flags.String("amount", "0.04", "bid amount in ETH")
+	deposit := flags.Bool("deposit", false, "deposit the minimum into the current window if the deposit is below it")
+	sendBundle := flags.Bool("bundle", false, "also send the transaction as a bundle to RPC_ENDPOINT")
+	tolerance := flags.Uint64("inclusion-tolerance", 2, "blocks after the target block the transaction may land in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	bidAmount, err := ee.ParseEther(*amount)
+	if err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+	if missing := missingEnvVars("WS_ENDPOINT"); len(missing) > 0 {
+		return fmt.Errorf("required environment variables are not set: %v", missing)
+	}
+	if *sendBundle && os.Getenv("RPC_ENDPOINT") == "" {
+		return errors.New("RPC_ENDPOINT environment variable is required with --bundle")
+	}
+	if *deposit && os.Getenv("MEV_COMMIT_RPC_ENDPOINT") == "" {
+		return errors.New("MEV_COMMIT_RPC_ENDPOINT environment variable is required with --deposit")
+	}
+
+	var (
+		test     selftest
+		authAcct bb.AuthAcct
+		client   *ethclient.Client
+		tx       *types.Transaction
+		target   uint64
+	)
+
+	connected := test.run("connect L1", func() (string, error) {
+		privateKeyHex, err := privateKeyFromEnv()
+		if err != nil {
+			return "", err
+		}
+		acct, err := bb.AuthenticateAddress(privateKeyHex)
+		if err != nil {
+			return "", err
+		}
+		client, err = bb.NewGethClient(os.Getenv("WS_ENDPOINT"))
+		if err != nil {
+			return "", err
+		}
+		// A devnet has its own chain ID, so sign for whichever chain the node is on
+		chainID, err := client.ChainID(context.Background())
+		if err != nil {
+			return "", fmt.Errorf("failed to get chain ID: %w", err)
+		}
+		if authAcct, err = acct.ForChain(chainID); err != nil {
+			return "", err
+		}
+		balance, err := client.BalanceAt(context.Background(), authAcct.Address, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get balance: %w", err)
+		}
+		if balance.Sign() == 0 {
+			return "", fmt.Errorf("account %s is not funded", authAcct.Address)
+		}
+		return fmt.Sprintf("chain %s, account %s, balance %s ETH", chainID, authAcct.Address, ee.FormatEther(balance)), nil
+	})
+	if client != nil {
+		defer client.Close()
+	}
+
+	built := connected && test.run("build transaction", func() (string, error) {
+		var err error
+		tx, target, err = ee.SelfETHTransfer(client, authAcct, new(big.Int), 1, ee.TxOptions{})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("tx %s, nonce %d, target block %d", tx.Hash(), tx.Nonce(), target), nil
+	})
+	if !connected {
+		test.skip("build transaction", "not connected")
+	}
+
+	if mevCommitRPCEndpoint := os.Getenv("MEV_COMMIT_RPC_ENDPOINT"); mevCommitRPCEndpoint == "" {
+		test.skip("bidder deposit", "MEV_COMMIT_RPC_ENDPOINT is not set")
+	} else if connected {
+		test.run("bidder deposit", func() (string, error) {
+			return checkSelftestDeposit(mevCommitRPCEndpoint, authAcct, *deposit)
+		})
+	} else {
+		test.skip("bidder deposit", "not connected")
+	}
+
+	committed := false
+	if built {
+		committed = test.run("send bid", func() (string, error) {
+			bidderAddress := os.Getenv("BIDDER_ADDRESS")
+			if bidderAddress == "" {
+				bidderAddress = "mev-commit-bidder:13524"
+			}
+			bidderClient, err := bb.NewBidderClient(bb.BidderConfig{ServerAddress: bidderAddress})
+			if err != nil {
+				return "", err
+			}
+			defer bidderClient.Flush()
+
+			now := time.Now()
+			result, err := bidderClient.SendBidAndCollect(context.Background(), []*types.Transaction{tx}, bidAmount.String(), int64(target), now.UnixMilli(), now.Add(fixedBidDecay).UnixMilli())
+			if err != nil {
+				return "", err
+			}
+			if len(result.Commitments) == 0 {
+				return "", fmt.Errorf("no provider committed to the bid on block %d", target)
+			}
+			return fmt.Sprintf("%d commitments, first after %s", len(result.Commitments), result.FirstCommitmentLatency.Round(time.Millisecond)), nil
+		})
+	} else {
+		test.skip("send bid", "no transaction")
+	}
+
+	switch {
+	case !*sendBundle:
+		test.skip("send bundle", "--bundle is not set")
+	case !built:
+		test.skip("send bundle", "no transaction")
+	default:
+		test.run("send bundle", func() (string, error) {
+			return ee.SendBundle(os.Getenv("RPC_ENDPOINT"), tx, target)
+		})
+	}
+
+	if committed || (built && *sendBundle) {
+		test.run("verify inclusion", func() (string, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*tolerance+3)*slotDuration)
+			defer cancel()
+			included, block, err := ee.VerifyInclusion(ctx, client, tx.Hash(), target, *tolerance)
+			if err != nil {
+				return "", err
+			}
+			if !included {
+				return "", fmt.Errorf("not included within %d blocks of block %d (landed in block %d)", *tolerance, target, block)
+			}
+			return fmt.Sprintf("included in block %d", block), nil
+		})
+	} else {
+		test.skip("verify inclusion", "the transaction was neither committed nor bundled")
+	}
+
+	return test.report(os.Stdout)
+}
+
+// checkSelftestDeposit checks the bidder's deposit in the current window, depositing the
+// minimum first if it is short and deposit is set.
+func checkSelftestDeposit(endpoint string, authAcct bb.AuthAcct, deposit bool) (string, error) {
+	client, err := bb.NewGethClient(endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	window, err := bb.WindowHeight(client)
+	if err != nil {
+		return "", fmt.Errorf("failed to get current window: %w", err)
+	}
+	amount, err := bb.GetDepositAmount(client, authAcct.Address, *window)
+	if err != nil {
+		return "", err
+	}
+	minDeposit, err := bb.GetMinDeposit(client)
+	if err != nil {
+		return "", err
+	}
+	if amount.Cmp(minDeposit) >= 0 {
+		return fmt.Sprintf("window %s, deposit %s wei, minimum %s wei", window, amount, minDeposit), nil
+	}
+	if !deposit {
+		return "", fmt.Errorf("deposit %s wei in window %s is below the minimum %s wei, run with --deposit to top it up", amount, window, minDeposit)
+	}
+
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to get mev-commit chain ID: %w", err)
+	}
+	fundAcct, err := authAcct.ForChain(chainID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := bb.DepositIntoWindow(client, window, &fundAcct); err != nil {
+		return "", fmt.Errorf("failed to deposit into window %s: %w", window, err)
+	}
+	if err := bb.WaitForSufficientDeposit(context.Background(), client, authAcct.Address, 2*time.Minute); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deposited %s wei into window %s", minDeposit, window), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSelftestReport(t *testing.T) {
+	pass := func() (string, error) { return "fine", nil }
+	fail := func() (string, error) { return "ignored", errors.New("broken") }
+	tests := []struct {
+		name      string
+		steps     func(s *selftest)
+		wantLines []string // The prefix of each line, in order.
+		wantErr   bool
+	}{
+		{
+			name:      "all pass",
+			steps:     func(s *selftest) { s.run("connect L1", pass); s.run("send bid", pass) },
+			wantLines: []string{"PASS  connect L1", "PASS  send bid", "self-test passed"},
+		},
+		{
+			name:      "skipped steps pass",
+			steps:     func(s *selftest) { s.run("connect L1", pass); s.skip("send bundle", "--bundle is not set") },
+			wantLines: []string{"PASS  connect L1", "SKIP  send bundle", "self-test passed"},
+		},
+		{
+			name:      "a failure fails the test",
+			steps:     func(s *selftest) { s.run("connect L1", fail); s.skip("build transaction", "not connected") },
+			wantLines: []string{"FAIL  connect L1", "SKIP  build transaction", "self-test failed"},
+			wantErr:   true,
+		},
+		{
+			name:      "no steps",
+			steps:     func(*selftest) {},
+			wantLines: []string{"self-test passed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s selftest
+			tt.steps(&s)
+			var out bytes.Buffer
+			if err := s.report(&out); (err != nil) != tt.wantErr {
+				t.Fatalf("report error = %v, want error %v", err, tt.wantErr)
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != len(tt.wantLines) {
+				t.Fatalf("report:\n%s\nwant %d lines", out.String(), len(tt.wantLines))
+			}
+			for i, want := range tt.wantLines {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Fatalf("line %d = %q, want it to start with %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+
+	// A failing step shows its error, a passing one its detail
+	var s selftest
+	if s.run("connect L1", fail) || !s.run("send bid", pass) {
+		t.Fatal("run reported the wrong outcome")
+	}
+	if s.steps[0].detail != "broken" || s.steps[1].detail != "fine" {
+		t.Fatalf("details = %q, %q, want \"broken\", \"fine\"", s.steps[0].detail, s.steps[1].detail)
+	}
+}
+
+func TestRunSelftestRejectsInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		vars    map[string]string
+		wantErr string
+	}{
+		{name: "unknown flag", args: []string{"-blobs", "1"}, wantErr: "flag provided but not defined"},
+		{name: "invalid amount", args: []string{"-amount", "lots"}, wantErr: "invalid amount"},
+		{name: "no endpoint", vars: map[string]string{"WS_ENDPOINT": ""}, wantErr: "WS_ENDPOINT"},
+		{name: "bundle without rpc", args: []string{"-bundle"}, vars: map[string]string{"RPC_ENDPOINT": ""}, wantErr: "RPC_ENDPOINT"},
+		{name: "deposit without mev-commit rpc", args: []string{"-deposit"}, vars: map[string]string{"MEV_COMMIT_RPC_ENDPOINT": ""}, wantErr: "MEV_COMMIT_RPC_ENDPOINT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WS_ENDPOINT", "ws://127.0.0.1:1")
+			setenv(t, tt.vars)
+			err := runSelftest(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("runSelftest error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
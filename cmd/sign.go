@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// runSign builds and signs one transaction like the bot would, and writes its raw encoding to
+// stdout or a file instead of sending it. The nonce and fee caps can be supplied, otherwise they
+// are read from WS_ENDPOINT.
+func runSign(args []string) error {
+	flags := flag.NewFlagSet("sign", flag.ContinueOnError)
+	numBlobs := flags.Int("blobs", 0, "blobs of a blob transaction, 0 for an ETH transfer to self")
+	maxBlobs := flags.Int("max-blobs", ee.MaxBlobsPerTransaction, "the fork's limit on blobs per transaction")
+	value := flags.String("value", "0", "ETH transferred to self, ignored for blob transactions")
+	nonce := flags.Int64("nonce", -1, "nonce to use, -1 to read the pending nonce from the node")
+	feeCap := flags.String("fee-cap-gwei", "", "max fee per gas in gwei, derived from the base fee if unset")
+	tipCap := flags.String("tip-cap-gwei", "", "tip per gas in gwei, no tip if unset")
+	out := flags.String("out", "", "file to write the raw transaction to, stdout if unset")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if missing := missingEnvVars("WS_ENDPOINT"); len(missing) > 0 {
+		return fmt.Errorf("required environment variables are not set: %v", missing)
+	}
+
+	chainID, err := chainIDOverrideFromEnv()
+	if err != nil {
+		return err
+	}
+	opts := ee.TxOptions{MaxBlobs: *maxBlobs, ChainID: chainID}
+	if *nonce >= 0 {
+		n := uint64(*nonce)
+		opts.Nonce = &n
+	}
+	if *feeCap != "" {
+		if opts.Fees.FeeCap, err = ee.ParseGwei(*feeCap); err != nil {
+			return fmt.Errorf("invalid fee cap: %w", err)
+		}
+	}
+	if *tipCap != "" {
+		if opts.Fees.TipCap, err = ee.ParseGwei(*tipCap); err != nil {
+			return fmt.Errorf("invalid tip cap: %w", err)
+		}
+	}
+	if err := opts.Fees.Validate(); err != nil {
+		return err
+	}
+	transferValue, err := ee.ParseEther(*value)
+	if err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	if *numBlobs > 0 {
+		if err := ee.ValidateBlobCountMax(*numBlobs, opts.MaxBlobs); err != nil {
+			return err
+		}
+		ee.InitKZG()
+	}
+
+	privateKeyHex, err := privateKeyFromEnv()
+	if err != nil {
+		return err
+	}
+	authAcct, err := bb.AuthenticateAddress(privateKeyHex)
+	if err != nil {
+		return err
+	}
+	client, err := bb.NewGethClient(os.Getenv("WS_ENDPOINT"))
+	if err != nil {
+		return fmt.Errorf("failed to connect to geth client: %w", err)
+	}
+	defer client.Close()
+
+	rawTx, err := ee.BuildSignedTx(client, authAcct, *numBlobs, transferValue, opts)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		fmt.Println(rawTx)
+		return nil
+	}
+	return os.WriteFile(*out, []byte(rawTx+"\n"), 0600)
+}
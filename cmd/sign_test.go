@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunSignRejectsInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		noWS    bool
+		key     string // PRIVATE_KEY.
+		keyFile string // PRIVATE_KEY_FILE.
+		wantErr string
+	}{
+		{name: "no endpoint", noWS: true, wantErr: "WS_ENDPOINT"},
+		{name: "unknown flag", args: []string{"-fee", "1"}, wantErr: "flag provided but not defined"},
+		{name: "invalid fee cap", args: []string{"-fee-cap-gwei", "ten"}, wantErr: "invalid fee cap"},
+		{name: "invalid tip cap", args: []string{"-tip-cap-gwei", "-1"}, wantErr: "invalid tip cap"},
+		{name: "fee cap below tip cap", args: []string{"-fee-cap-gwei", "1", "-tip-cap-gwei", "2"}, wantErr: "lower than tip cap"},
+		{name: "invalid value", args: []string{"-value", "1e18"}, wantErr: "invalid value"},
+		{name: "too many blobs", args: []string{"-blobs", "7"}, wantErr: "blob"},
+		{name: "above max blobs", args: []string{"-blobs", "3", "-max-blobs", "2"}, wantErr: "too many blobs"},
+		{name: "above the Prague maximum", args: []string{"-blobs", "10", "-max-blobs", "9"}, wantErr: "too many blobs"},
+		{name: "no private key", wantErr: "one of PRIVATE_KEY and PRIVATE_KEY_FILE must be set"},
+		{name: "key and key file", key: "abc", keyFile: "key", wantErr: "only one of PRIVATE_KEY and PRIVATE_KEY_FILE"},
+		{name: "missing key file", keyFile: "/nonexistent/key", wantErr: "failed to read PRIVATE_KEY_FILE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := "ws://127.0.0.1:1"
+			if tt.noWS {
+				ws = ""
+			}
+			setenv(t, map[string]string{"WS_ENDPOINT": ws, "CHAIN_ID": "", "PRIVATE_KEY": tt.key, "PRIVATE_KEY_FILE": tt.keyFile})
+
+			err := runSign(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("runSign error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
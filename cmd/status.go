@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// statusSnapshot is the JSON document served by the status endpoint.
+type statusSnapshot struct {
+	LastBlock uint64        `json:"lastBlock"`
+	Fees      *ee.BlockFees `json:"fees,omitempty"`
+	Providers []string      `json:"providers"`
+	HeadLag   string        `json:"headLag,omitempty"`
+	Paused    bool          `json:"paused"`
+	Syncing   bool          `json:"syncing"`
+	Sync      *syncStatus   `json:"sync,omitempty"`
+}
+
+// syncStatus is the progress of a syncing node, as served by the status endpoint.
+type syncStatus struct {
+	CurrentBlock uint64 `json:"currentBlock"`
+	HighestBlock uint64 `json:"highestBlock"`
+}
+
+// botStatus holds the latest bot state reported by the status endpoint.
+type botStatus struct {
+	mu           sync.RWMutex
+	snap         statusSnapshot
+	minProviders int         // Connected providers required before /healthz reports ready.
+	paused       atomic.Bool // Whether bidding was paused through /pause.
+}
+
+// setFees records the fee snapshot of the latest head.
+func (s *botStatus) setFees(block uint64, fees ee.BlockFees) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap.LastBlock = block
+	s.snap.Fees = &fees
+}
+
+// setHeadLag records the median lag between a head's timestamp and its observation.
+func (s *botStatus) setHeadLag(lag time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap.HeadLag = lag.String()
+}
+
+// setSyncing records the sync progress of the node, nil once it is synced.
+func (s *botStatus) setSyncing(progress *ethereum.SyncProgress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap.Syncing = progress != nil
+	s.snap.Sync = nil
+	if progress != nil {
+		s.snap.Sync = &syncStatus{CurrentBlock: progress.CurrentBlock, HighestBlock: progress.HighestBlock}
+	}
+}
+
+// setProviders records the providers the bidder node is connected to.
+func (s *botStatus) setProviders(providers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap.Providers = providers
+}
+
+// healthz reports ready once the bidder node is connected to at least minProviders providers,
+// since bids are pointless otherwise.
+func (s *botStatus) healthz(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	providers := len(s.snap.Providers)
+	s.mu.RUnlock()
+
+	if providers < s.minProviders {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "not ready: %d of %d required providers connected\n", providers, s.minProviders)
+		return
+	}
+	fmt.Fprintf(w, "ok: %d providers connected\n", providers)
+}
+
+// setPaused pauses or resumes bidding, logging the change if the state changed.
+func (s *botStatus) setPaused(paused bool) {
+	if s.paused.Swap(paused) == paused {
+		return
+	}
+	if paused {
+		log.Warn("bidding paused")
+	} else {
+		log.Info("bidding resumed")
+	}
+}
+
+// control returns a handler that pauses or resumes bidding on POST.
+func (s *botStatus) control(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.setPaused(paused)
+		fmt.Fprintf(w, "paused: %t\n", paused)
+	}
+}
+
+// ServeHTTP writes the current status as JSON.
+func (s *botStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	snap := s.snap
+	s.mu.RUnlock()
+	snap.Paused = s.paused.Load()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		log.Warn("failed to encode status", "err", err)
+	}
+}
+
+// startStatusServer serves the bot status on addr in the background, along with the collected
+// metrics in the Prometheus format and the /pause and /resume controls.
+func startStatusServer(addr string, status *botStatus) {
+	mux := http.NewServeMux()
+	mux.Handle("/status", status)
+	mux.HandleFunc("/healthz", status.healthz)
+	mux.Handle("/pause", status.control(true))
+	mux.Handle("/resume", status.control(false))
+	mux.Handle("/debug/metrics/prometheus", metricsHandler())
+
+	go func() {
+		log.Info("status endpoint listening", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Error("status endpoint stopped", "err", err)
+		}
+	}()
+}
+
+// metricsHandler serves the go-ethereum metrics in the Prometheus format, followed by the
+// metrics of the default Prometheus registry, such as the bundle latency histograms, so both are
+// scraped from one endpoint.
+func metricsHandler() http.Handler {
+	gethMetrics := prometheus.Handler(metrics.DefaultRegistry)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gethMetrics.ServeHTTP(w, r)
+
+		families, err := promclient.DefaultGatherer.Gather()
+		if err != nil {
+			log.Warn("failed to gather metrics", "err", err)
+		}
+		enc := expfmt.NewEncoder(w, expfmt.FmtText)
+		for _, family := range families {
+			if err := enc.Encode(family); err != nil {
+				log.Warn("failed to encode metrics", "err", err)
+				return
+			}
+		}
+	})
+}
+
+// pollProviders refreshes the providers the bidder node is connected to every interval. A failed
+// query clears them, so readiness is not reported on stale data.
+func pollProviders(bidderClient *bb.Bidder, status *botStatus, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		topology, err := bidderClient.Topology(context.Background())
+		if err != nil {
+			log.Warn("failed to query bidder node topology", "err", err)
+			status.setProviders(nil)
+			continue
+		}
+		log.Debug("bidder node topology", "providers", len(topology.Providers))
+		status.setProviders(topology.Providers)
+	}
+}
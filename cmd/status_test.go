@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthz(t *testing.T) {
+	tests := []struct {
+		name         string
+		providers    []string
+		minProviders int
+		wantCode     int
+		wantBody     string
+	}{
+		{name: "enough providers", providers: []string{"0x01", "0x02"}, minProviders: 2, wantCode: http.StatusOK, wantBody: "ok: 2 providers"},
+		{name: "more than enough", providers: []string{"0x01", "0x02"}, minProviders: 1, wantCode: http.StatusOK, wantBody: "ok: 2 providers"},
+		{name: "too few providers", providers: []string{"0x01"}, minProviders: 2, wantCode: http.StatusServiceUnavailable, wantBody: "1 of 2"},
+		{name: "no providers", minProviders: 1, wantCode: http.StatusServiceUnavailable, wantBody: "0 of 1"},
+		{name: "no minimum", minProviders: 0, wantCode: http.StatusOK, wantBody: "ok: 0 providers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &botStatus{minProviders: tt.minProviders}
+			status.setProviders(tt.providers)
+
+			rec := httptest.NewRecorder()
+			status.healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if body := rec.Body.String(); !strings.Contains(body, tt.wantBody) {
+				t.Fatalf("body = %q, want it to contain %q", body, tt.wantBody)
+			}
+		})
+	}
+
+	// Losing providers makes the bot unready again
+	status := &botStatus{minProviders: 1}
+	status.setProviders([]string{"0x01"})
+	status.setProviders(nil)
+	rec := httptest.NewRecorder()
+	status.healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status code after losing providers = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestStatusControl(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+	}
+	tests := []struct {
+		name       string
+		requests   []request
+		wantCode   int // The status code of the last request.
+		wantPaused bool
+	}{
+		{name: "pause", requests: []request{{http.MethodPost, "/pause"}}, wantCode: http.StatusOK, wantPaused: true},
+		{name: "pause twice", requests: []request{{http.MethodPost, "/pause"}, {http.MethodPost, "/pause"}}, wantCode: http.StatusOK, wantPaused: true},
+		{name: "resume", requests: []request{{http.MethodPost, "/pause"}, {http.MethodPost, "/resume"}}, wantCode: http.StatusOK},
+		{name: "resume without pause", requests: []request{{http.MethodPost, "/resume"}}, wantCode: http.StatusOK},
+		{name: "get rejected", requests: []request{{http.MethodGet, "/pause"}}, wantCode: http.StatusMethodNotAllowed},
+		{name: "get does not resume", requests: []request{{http.MethodPost, "/pause"}, {http.MethodGet, "/resume"}}, wantCode: http.StatusMethodNotAllowed, wantPaused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &botStatus{}
+			handlers := map[string]http.Handler{"/pause": status.control(true), "/resume": status.control(false)}
+
+			var rec *httptest.ResponseRecorder
+			for _, req := range tt.requests {
+				rec = httptest.NewRecorder()
+				handlers[req.path].ServeHTTP(rec, httptest.NewRequest(req.method, req.path, nil))
+			}
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if got := status.paused.Load(); got != tt.wantPaused {
+				t.Fatalf("paused = %v, want %v", got, tt.wantPaused)
+			}
+
+			// The status snapshot reports the state too
+			rec = httptest.NewRecorder()
+			status.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+			var snap statusSnapshot
+			if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+				t.Fatalf("decoding status: %v", err)
+			}
+			if snap.Paused != tt.wantPaused {
+				t.Fatalf("status paused = %v, want %v", snap.Paused, tt.wantPaused)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// syncReader reports the sync progress of a node, nil once it is synced. *ethclient.Client
+// implements it.
+type syncReader interface {
+	SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error)
+}
+
+// syncGate holds off bidding while the node is syncing, since its latest header is stale then
+// and bids would target blocks that are long gone. The node is queried at most once per interval;
+// a failed query keeps the previous state.
+type syncGate struct {
+	clock    clock         // Source of the current time.
+	interval time.Duration // The least time between two queries.
+	timeout  time.Duration // The deadline of each query.
+	status   *botStatus    // Receives the sync state for the status endpoint.
+	checked  time.Time     // When the node was last queried.
+	syncing  bool          // Whether the node was syncing at the last successful query.
+}
+
+// newSyncGate creates a syncGate querying the node every interval.
+func newSyncGate(clock clock, interval, timeout time.Duration, status *botStatus) *syncGate {
+	return &syncGate{clock: clock, interval: interval, timeout: timeout, status: status}
+}
+
+// check queries the node if the interval has passed since the last query, logs when it starts or
+// stops syncing, and reports whether it is syncing. A nil syncGate never reports syncing.
+func (g *syncGate) check(reader syncReader) bool {
+	if g == nil {
+		return false
+	}
+	now := g.clock.Now()
+	if !g.checked.IsZero() && now.Sub(g.checked) < g.interval {
+		return g.syncing
+	}
+	g.checked = now
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+	progress, err := reader.SyncProgress(ctx)
+	if err != nil {
+		log.Warn("failed to query node sync progress", "err", err)
+		return g.syncing
+	}
+
+	syncing := progress != nil
+	switch {
+	case syncing && !g.syncing:
+		log.Warn("node is syncing, bidding paused until it is synced", "currentBlock", progress.CurrentBlock, "highestBlock", progress.HighestBlock)
+	case !syncing && g.syncing:
+		log.Info("node is synced, bidding resumed")
+	case syncing:
+		log.Debug("node is still syncing", "currentBlock", progress.CurrentBlock, "highestBlock", progress.HighestBlock)
+	}
+	g.syncing = syncing
+	if g.status != nil {
+		g.status.setSyncing(progress)
+	}
+	return syncing
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// fakeSyncReader answers each query with the next of its responses.
+type fakeSyncReader struct {
+	responses []syncResponse
+	queries   int
+}
+
+type syncResponse struct {
+	progress *ethereum.SyncProgress
+	err      error
+}
+
+func (f *fakeSyncReader) SyncProgress(context.Context) (*ethereum.SyncProgress, error) {
+	r := f.responses[f.queries]
+	f.queries++
+	return r.progress, r.err
+}
+
+func TestSyncGate(t *testing.T) {
+	behind := &ethereum.SyncProgress{CurrentBlock: 90, HighestBlock: 100}
+	type step struct {
+		advance     time.Duration // Passed before the check.
+		queried     bool          // Whether the check queries the node.
+		wantSyncing bool
+	}
+	tests := []struct {
+		name      string
+		responses []syncResponse
+		steps     []step
+		wantSync  *syncStatus // The progress reported by the status endpoint at the end.
+	}{
+		{
+			name:      "synced",
+			responses: []syncResponse{{}},
+			steps:     []step{{queried: true}},
+		},
+		{
+			name:      "syncing",
+			responses: []syncResponse{{progress: behind}},
+			steps:     []step{{queried: true, wantSyncing: true}},
+			wantSync:  &syncStatus{CurrentBlock: 90, HighestBlock: 100},
+		},
+		{
+			name:      "queried once per interval",
+			responses: []syncResponse{{progress: behind}, {}},
+			steps: []step{
+				{queried: true, wantSyncing: true},
+				{advance: 5 * time.Second, wantSyncing: true},
+				{advance: 7 * time.Second, queried: true},
+				{advance: time.Second},
+			},
+		},
+		{
+			name:      "failed query keeps the state",
+			responses: []syncResponse{{progress: behind}, {err: errors.New("connection refused")}, {}},
+			steps: []step{
+				{queried: true, wantSyncing: true},
+				{advance: 12 * time.Second, queried: true, wantSyncing: true},
+				{advance: 12 * time.Second, queried: true},
+			},
+		},
+		{
+			name:      "starts syncing",
+			responses: []syncResponse{{}, {progress: behind}},
+			steps: []step{
+				{queried: true},
+				{advance: 12 * time.Second, queried: true, wantSyncing: true},
+			},
+			wantSync: &syncStatus{CurrentBlock: 90, HighestBlock: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1700000000, 0)}
+			status := &botStatus{}
+			g := newSyncGate(clock, 12*time.Second, time.Second, status)
+			reader := &fakeSyncReader{responses: tt.responses}
+			for i, s := range tt.steps {
+				clock.now = clock.now.Add(s.advance)
+				queries := reader.queries
+				if got := g.check(reader); got != s.wantSyncing {
+					t.Fatalf("check %d = %v, want %v", i, got, s.wantSyncing)
+				}
+				if got := reader.queries > queries; got != s.queried {
+					t.Fatalf("check %d queried the node %v, want %v", i, got, s.queried)
+				}
+			}
+
+			snap := status.snap
+			if snap.Syncing != (tt.wantSync != nil) {
+				t.Fatalf("status syncing = %v, want %v", snap.Syncing, tt.wantSync != nil)
+			}
+			if (snap.Sync == nil) != (tt.wantSync == nil) || (snap.Sync != nil && *snap.Sync != *tt.wantSync) {
+				t.Fatalf("status sync = %+v, want %+v", snap.Sync, tt.wantSync)
+			}
+		})
+	}
+}
+
+func TestSyncGateDisabled(t *testing.T) {
+	var g *syncGate
+	reader := &fakeSyncReader{}
+	if g.check(reader) {
+		t.Fatal("a nil syncGate reported syncing")
+	}
+	if reader.queries != 0 {
+		t.Fatal("a nil syncGate queried the node")
+	}
+}
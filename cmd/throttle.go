@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math/big"
+	"time"
+
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+)
+
+// bidThrottle enforces a minimum interval between submitted bids, coalescing heads that
+// arrive in quick succession.
+type bidThrottle struct {
+	clock       clock         // Source of the current time.
+	minInterval time.Duration // Minimum time between two bids. Zero disables throttling.
+	last        time.Time     // Time of the last allowed bid.
+}
+
+// allow reports whether a bid may be submitted now, and records it if so.
+func (t *bidThrottle) allow() bool {
+	now := t.clock.Now()
+	if t.minInterval > 0 && !t.last.IsZero() && now.Sub(t.last) < t.minInterval {
+		return false
+	}
+	t.last = now
+	return true
+}
+
+// exceedsMaxBaseFee reports whether a head's base fee is above the configured maximum. A nil
+// maximum disables the check, as does a head without a base fee.
+func exceedsMaxBaseFee(fees ee.BlockFees, maxBaseFee *big.Int) bool {
+	return maxBaseFee != nil && fees.BaseFee != nil && fees.BaseFee.Cmp(maxBaseFee) > 0
+}
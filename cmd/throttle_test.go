@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+)
+
+func TestExceedsMaxBaseFee(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	tests := []struct {
+		name       string
+		baseFee    *big.Int
+		maxBaseFee *big.Int
+		want       bool
+	}{
+		{name: "below", baseFee: gwei(9), maxBaseFee: gwei(10), want: false},
+		{name: "at maximum", baseFee: gwei(10), maxBaseFee: gwei(10), want: false},
+		{name: "one wei above", baseFee: new(big.Int).Add(gwei(10), big.NewInt(1)), maxBaseFee: gwei(10), want: true},
+		{name: "far above", baseFee: gwei(200), maxBaseFee: gwei(10), want: true},
+		{name: "zero maximum", baseFee: big.NewInt(1), maxBaseFee: new(big.Int), want: true},
+		{name: "no maximum", baseFee: gwei(200), want: false},
+		{name: "no base fee", maxBaseFee: gwei(10), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exceedsMaxBaseFee(ee.BlockFees{BaseFee: tt.baseFee}, tt.maxBaseFee); got != tt.want {
+				t.Fatalf("exceedsMaxBaseFee(%v, %v) = %v, want %v", tt.baseFee, tt.maxBaseFee, got, tt.want)
+			}
+		})
+	}
+}
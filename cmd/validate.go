@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+)
+
+// envError is an environment variable whose value is invalid.
+type envError struct {
+	Name   string // The name of the variable.
+	Value  string // The offending value.
+	Reason string // What the value must be.
+}
+
+// Error describes the variable, the expected value and the offending value.
+func (e *envError) Error() string {
+	return fmt.Sprintf("environment variable %s %s, got '%s'", e.Name, e.Reason, e.Value)
+}
+
+// envConfig reads configuration values from the environment and collects every invalid one,
+// so Validate can report them all at once instead of stopping at the first.
+type envConfig struct {
+	errs []error
+}
+
+// boolVar returns the boolean value of the named variable, or value if it is unset or invalid.
+func (c *envConfig) boolVar(name string, value bool) bool {
+	env := os.Getenv(name)
+	if env == "" {
+		return value
+	}
+	parsed, err := parseBoolEnvVar(name, env)
+	if err != nil {
+		c.errs = append(c.errs, err)
+		return value
+	}
+	return parsed
+}
+
+// uintVar returns the unsigned integer value of the named variable, or value if it is unset or
+// invalid.
+func (c *envConfig) uintVar(name string, value uint64) uint64 {
+	env := os.Getenv(name)
+	if env == "" {
+		return value
+	}
+	parsed, err := parseUintEnvVar(name, env)
+	if err != nil {
+		c.errs = append(c.errs, err)
+		return value
+	}
+	return parsed
+}
+
+// durationVar returns the duration value of the named variable, or value if it is unset or
+// invalid.
+func (c *envConfig) durationVar(name string, value time.Duration) time.Duration {
+	env := os.Getenv(name)
+	if env == "" {
+		return value
+	}
+	parsed, err := parseDurationEnvVar(name, env)
+	if err != nil {
+		c.errs = append(c.errs, err)
+		return value
+	}
+	return parsed
+}
+
+// positiveDurationVar is like durationVar, but also rejects a zero duration.
+func (c *envConfig) positiveDurationVar(name string, value time.Duration) time.Duration {
+	parsed := c.durationVar(name, value)
+	if parsed == 0 {
+		c.fail(name, "must be a positive duration (e.g. 6s)")
+		return value
+	}
+	return parsed
+}
+
+// floatVar returns the numeric value of the named variable, or value if it is unset or invalid.
+func (c *envConfig) floatVar(name string, value float64) float64 {
+	env := os.Getenv(name)
+	if env == "" {
+		return value
+	}
+	parsed, err := parseFloatEnvVar(name, env)
+	if err != nil {
+		c.errs = append(c.errs, err)
+		return value
+	}
+	return parsed
+}
+
+// fractionVar returns the value of the named variable, which must be above 0 and at most 1, or
+// value if it is unset or invalid.
+func (c *envConfig) fractionVar(name string, value float64) float64 {
+	if os.Getenv(name) == "" {
+		return value
+	}
+	parsed := c.floatVar(name, value)
+	if !(parsed > 0 && parsed <= 1) {
+		c.fail(name, "must be above 0 and at most 1")
+		return value
+	}
+	return parsed
+}
+
+// gweiVar returns the value of the named variable, a decimal amount in gwei, in wei, or nil if
+// it is unset or invalid.
+func (c *envConfig) gweiVar(name string) *big.Int {
+	env := os.Getenv(name)
+	if env == "" {
+		return nil
+	}
+	amount, err := ee.ParseGwei(env)
+	if err != nil {
+		c.invalid(name, err)
+		return nil
+	}
+	return amount
+}
+
+// weiVar returns the value of the named variable, a non-negative integer amount in wei, or nil if
+// it is unset or invalid.
+func (c *envConfig) weiVar(name string) *big.Int {
+	env := os.Getenv(name)
+	if env == "" {
+		return nil
+	}
+	amount, ok := new(big.Int).SetString(env, 10)
+	if !ok || amount.Sign() < 0 {
+		c.fail(name, "must be a non-negative amount in wei")
+		return nil
+	}
+	return amount
+}
+
+// amountVar returns the value of the named variable, a decimal amount in unit, in wei, or the
+// default given in ETH if it is unset or invalid.
+func (c *envConfig) amountVar(name, unit, defaultEther string) *big.Int {
+	fallback, err := ee.ParseEther(defaultEther)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default %s amount: %v", name, err))
+	}
+	env := os.Getenv(name)
+	if env == "" {
+		return fallback
+	}
+	amount, err := ee.ParseAmount(env, unit)
+	if err != nil {
+		c.invalid(name, err)
+		return fallback
+	}
+	return amount
+}
+
+// etherVar returns the value of the named variable, a decimal amount in ETH, in wei, or the
+// default if it is unset or invalid.
+func (c *envConfig) etherVar(name, defaultEther string) *big.Int {
+	return c.amountVar(name, "eth", defaultEther)
+}
+
+// invalid records the named variable as invalid because of err, which does not name it. A nil
+// err is ignored.
+func (c *envConfig) invalid(name string, err error) {
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("invalid %s value: %w", name, err))
+	}
+}
+
+// fail records the named variable as invalid, for checks beyond parsing.
+func (c *envConfig) fail(name, reason string) {
+	c.errs = append(c.errs, &envError{Name: name, Value: os.Getenv(name), Reason: reason})
+}
+
+// Validate returns every error collected so far, joined, or nil if all values were valid.
+func (c *envConfig) Validate() error {
+	return errors.Join(c.errs...)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEnvConfigValidate(t *testing.T) {
+	// read reads the variables like main does, failing OFFSET when it is zero
+	read := func(env *envConfig) {
+		env.boolVar("USE_PAYLOAD", true)
+		if env.uintVar("OFFSET", 1) == 0 {
+			env.fail("OFFSET", "must be at least 1")
+		}
+		env.uintVar("BID_HORIZON", 1)
+		env.boolVar("HANDLE_REORGS", false)
+	}
+	tests := []struct {
+		name      string
+		vars      map[string]string
+		wantNames []string // The invalid variables, in the order they were read.
+	}{
+		{name: "defaults"},
+		{name: "valid", vars: map[string]string{"USE_PAYLOAD": "false", "OFFSET": "2", "BID_HORIZON": "3", "HANDLE_REORGS": "true"}},
+		{name: "one invalid", vars: map[string]string{"BID_HORIZON": "many"}, wantNames: []string{"BID_HORIZON"}},
+		{
+			name:      "all reported together",
+			vars:      map[string]string{"USE_PAYLOAD": "yes", "OFFSET": "0", "BID_HORIZON": "-1", "HANDLE_REORGS": "1"},
+			wantNames: []string{"USE_PAYLOAD", "OFFSET", "BID_HORIZON"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, tt.vars)
+			env := &envConfig{}
+			read(env)
+
+			err := env.Validate()
+			if (err != nil) != (len(tt.wantNames) > 0) {
+				t.Fatalf("Validate = %v, want errors for %v", err, tt.wantNames)
+			}
+			var names []string
+			for _, err := range env.errs {
+				var envErr *envError
+				if !errors.As(err, &envErr) {
+					t.Fatalf("error %v is not an envError", err)
+				}
+				if envErr.Value != tt.vars[envErr.Name] {
+					t.Errorf("%s reported with value '%s', want '%s'", envErr.Name, envErr.Value, tt.vars[envErr.Name])
+				}
+				names = append(names, envErr.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Fatalf("invalid variables = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// printVersion prints the build information and the protocol defaults compiled into the binary.
+func printVersion() {
+	version, commit, modified := "unknown", "unknown", ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				commit = setting.Value
+			case "vcs.modified":
+				if setting.Value == "true" {
+					modified = " (modified)"
+				}
+			}
+		}
+	}
+
+	fmt.Printf("version:          %s\n", version)
+	fmt.Printf("commit:           %s%s\n", commit, modified)
+	fmt.Printf("mev-commit:       %s\n", bb.ProtocolVersion)
+	fmt.Printf("default chain ID: %d\n", bb.HOLESKY_CHAIN_ID)
+
+	addresses := bb.ContractAddresses()
+	names := make([]string, 0, len(addresses))
+	for name := range addresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%-17s %s\n", name+":", addresses[name])
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// warmup holds off bidding after startup for a number of heads and a duration, so connections
+// can settle and deposits confirm while heads are still observed. Both must pass before bidding
+// starts; a zero value of either does not hold bidding back.
+type warmup struct {
+	clock    clock         // Source of the current time.
+	blocks   uint64        // The heads to observe before bidding.
+	duration time.Duration // How long after start to wait before bidding.
+	start    time.Time     // When the warmup started.
+	seen     uint64        // The heads observed so far.
+	done     bool          // Whether the warmup is over.
+}
+
+// newWarmup starts a warmup of blocks heads and duration.
+func newWarmup(clock clock, blocks uint64, duration time.Duration) *warmup {
+	return &warmup{clock: clock, blocks: blocks, duration: duration, start: clock.Now()}
+}
+
+// observe counts a head and reports whether the warmup is still in progress, in which case the
+// head should not be bid on. The end of the warmup is logged once.
+func (w *warmup) observe(blockNumber uint64) bool {
+	if w.done {
+		return false
+	}
+	elapsed := w.clock.Now().Sub(w.start)
+	if w.seen < w.blocks || elapsed < w.duration {
+		w.seen++
+		return true
+	}
+	w.done = true
+	if w.blocks > 0 || w.duration > 0 {
+		log.Info("warmup complete, starting to bid", "block", blockNumber, "heads", w.seen, "elapsed", elapsed.Round(time.Second))
+	}
+	return false
+}
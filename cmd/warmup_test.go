@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	type head struct {
+		after    time.Duration // Time passed since the previous head.
+		wantSkip bool
+	}
+	tests := []struct {
+		name     string
+		blocks   uint64
+		duration time.Duration
+		heads    []head
+	}{
+		{name: "disabled", heads: []head{{}, {after: time.Second}}},
+		{name: "blocks", blocks: 2, heads: []head{{wantSkip: true}, {wantSkip: true}, {}, {}}},
+		{
+			name:     "duration",
+			duration: 30 * time.Second,
+			heads:    []head{{after: 12 * time.Second, wantSkip: true}, {after: 12 * time.Second, wantSkip: true}, {after: 12 * time.Second}},
+		},
+		{
+			name:     "blocks outlast the duration",
+			blocks:   3,
+			duration: 10 * time.Second,
+			heads:    []head{{after: 12 * time.Second, wantSkip: true}, {after: 12 * time.Second, wantSkip: true}, {after: 12 * time.Second, wantSkip: true}, {after: 12 * time.Second}},
+		},
+		{
+			name:     "duration outlasts the blocks",
+			blocks:   1,
+			duration: time.Minute,
+			heads:    []head{{after: 12 * time.Second, wantSkip: true}, {after: 12 * time.Second, wantSkip: true}, {after: 36 * time.Second}},
+		},
+		{
+			name:     "over for good",
+			duration: 20 * time.Second,
+			heads:    []head{{after: 30 * time.Second}, {}, {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+			w := newWarmup(clock, tt.blocks, tt.duration)
+			for i, h := range tt.heads {
+				clock.now = clock.now.Add(h.after)
+				if got := w.observe(uint64(100 + i)); got != h.wantSkip {
+					t.Fatalf("head %d: observe = %v, want %v", i, got, h.wantSkip)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// watchWindowSize feeds the detector with the window height read from the mev-commit chain at
+// each L1 head number received on heads, in the background. Heads arriving while a read is in
+// flight should be dropped by the sender; the detector ignores transitions spanning a gap.
+func watchWindowSize(client *ethclient.Client, detector *bb.WindowSizeDetector, heads <-chan uint64) {
+	go func() {
+		for blockNumber := range heads {
+			window, err := bb.WindowHeight(client)
+			if err != nil {
+				log.Debug("failed to read the window height", "block", blockNumber, "err", err)
+				continue
+			}
+			detector.Observe(window.Uint64(), blockNumber)
+		}
+	}()
+}
@@ -0,0 +1,111 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Encodings of the blob transactions of a bundle.
+const (
+	// BlobEncodingNetwork sends blob transactions in txs with their sidecar, in the network form
+	// of EIP-4844 that eth_sendRawTransaction takes. Relays accepting blob transactions in bundles
+	// need the blobs to build the block, so the canonical form without sidecar is not enough.
+	BlobEncodingNetwork = "network"
+	// BlobEncodingSeparate sends blob transactions in txs in their canonical form, without
+	// sidecar, and their blobs, commitments and proofs in a separate blobsBundle field, for
+	// relays that take the sidecars apart from the transactions.
+	BlobEncodingSeparate = "separate"
+)
+
+// ErrMissingBlobSidecar is returned when a blob transaction sent in a bundle has no sidecar, so
+// a builder could not include it.
+var ErrMissingBlobSidecar = errors.New("blob transaction has no sidecar")
+
+// BlobsBundle holds the sidecars of the blob transactions of a bundle sent with
+// BlobEncodingSeparate, concatenated in transaction order. It has the shape of the engine API's
+// BlobsBundleV1.
+type BlobsBundle struct {
+	Commitments []hexutil.Bytes `json:"commitments"`
+	Proofs      []hexutil.Bytes `json:"proofs"`
+	Blobs       []hexutil.Bytes `json:"blobs"`
+}
+
+// ValidateBlobEncoding checks that encoding is BlobEncodingNetwork or BlobEncodingSeparate.
+func ValidateBlobEncoding(encoding string) error {
+	switch encoding {
+	case BlobEncodingNetwork, BlobEncodingSeparate:
+		return nil
+	default:
+		return fmt.Errorf("unknown blob encoding '%s', must be %s or %s", encoding, BlobEncodingNetwork, BlobEncodingSeparate)
+	}
+}
+
+// EncodeBundleTxs encodes the transactions of a bundle as hex strings, checking that every blob
+// transaction carries a sidecar matching its blob hashes.
+//
+// Parameters:
+// - txs: The signed transactions of the bundle.
+// - encoding: How blob transactions are encoded, BlobEncodingNetwork or BlobEncodingSeparate.
+//
+// Returns:
+// - The encoded transactions, the sidecars if encoding is BlobEncodingSeparate and the bundle has blob transactions, and an error if a transaction cannot be encoded.
+func EncodeBundleTxs(txs []*types.Transaction, encoding string) ([]string, *BlobsBundle, error) {
+	if err := ValidateBlobEncoding(encoding); err != nil {
+		return nil, nil, err
+	}
+
+	rawTxs := make([]string, 0, len(txs))
+	var blobs *BlobsBundle
+	for i, tx := range txs {
+		if tx.Type() == types.BlobTxType {
+			if err := validateBlobSidecar(tx); err != nil {
+				return nil, nil, fmt.Errorf("transaction %d (%s): %w", i, tx.Hash(), err)
+			}
+			if encoding == BlobEncodingSeparate {
+				if blobs == nil {
+					blobs = &BlobsBundle{}
+				}
+				sidecar := tx.BlobTxSidecar()
+				for j := range sidecar.Blobs {
+					blobs.Commitments = append(blobs.Commitments, sidecar.Commitments[j][:])
+					blobs.Proofs = append(blobs.Proofs, sidecar.Proofs[j][:])
+					blobs.Blobs = append(blobs.Blobs, sidecar.Blobs[j][:])
+				}
+				tx = tx.WithoutBlobTxSidecar()
+			}
+		}
+
+		binary, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode transaction %d: %w", i, err)
+		}
+		rawTxs = append(rawTxs, hexutil.Encode(binary))
+	}
+	return rawTxs, blobs, nil
+}
+
+// validateBlobSidecar checks that a blob transaction has a sidecar with a commitment and proof
+// for each blob, whose versioned hashes are the blob hashes of the transaction.
+func validateBlobSidecar(tx *types.Transaction) error {
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return ErrMissingBlobSidecar
+	}
+	if len(sidecar.Commitments) != len(sidecar.Blobs) || len(sidecar.Proofs) != len(sidecar.Blobs) {
+		return fmt.Errorf("sidecar has %d blobs, %d commitments and %d proofs", len(sidecar.Blobs), len(sidecar.Commitments), len(sidecar.Proofs))
+	}
+	hashes := tx.BlobHashes()
+	sidecarHashes := sidecar.BlobHashes()
+	if len(hashes) != len(sidecarHashes) {
+		return fmt.Errorf("transaction has %d blob hashes, sidecar %d blobs", len(hashes), len(sidecarHashes))
+	}
+	for i := range hashes {
+		if hashes[i] != sidecarHashes[i] {
+			return fmt.Errorf("blob %d does not match blob hash %s", i, hashes[i])
+		}
+	}
+	return nil
+}
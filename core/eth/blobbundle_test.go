@@ -0,0 +1,121 @@
+package eth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+)
+
+// blobTx returns an unsigned blob transaction carrying sidecar, with the blob hashes of hashes.
+func blobTx(nonce uint64, sidecar *types.BlobTxSidecar, hashes []common.Hash) *types.Transaction {
+	return types.NewTx(&types.BlobTx{
+		ChainID:    uint256.NewInt(17000),
+		Nonce:      nonce,
+		Gas:        21000,
+		GasFeeCap:  uint256.NewInt(2),
+		GasTipCap:  uint256.NewInt(1),
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: hashes,
+		Sidecar:    sidecar,
+	})
+}
+
+func TestEncodeBundleTxs(t *testing.T) {
+	one, two := makeSidecar(randBlobs(1)), makeSidecar(randBlobs(2))
+	transfer := types.NewTx(&types.DynamicFeeTx{Nonce: 0, Gas: 21000})
+	short := *two
+	short.Proofs = short.Proofs[:1]
+	tests := []struct {
+		name      string
+		encoding  string
+		txs       []*types.Transaction
+		wantBlobs int // Blobs in the separate BlobsBundle, -1 for none.
+		wantErr   bool
+		errIs     error
+	}{
+		{name: "network", encoding: BlobEncodingNetwork, txs: []*types.Transaction{transfer, blobTx(1, two, two.BlobHashes())}, wantBlobs: -1},
+		{name: "separate", encoding: BlobEncodingSeparate, txs: []*types.Transaction{transfer, blobTx(1, two, two.BlobHashes())}, wantBlobs: 2},
+		{name: "separate from several transactions", encoding: BlobEncodingSeparate, txs: []*types.Transaction{blobTx(1, one, one.BlobHashes()), blobTx(2, two, two.BlobHashes())}, wantBlobs: 3},
+		{name: "separate without blob transactions", encoding: BlobEncodingSeparate, txs: []*types.Transaction{transfer}, wantBlobs: -1},
+		{name: "unknown encoding", encoding: "inline", txs: []*types.Transaction{transfer}, wantErr: true},
+		{name: "missing sidecar", encoding: BlobEncodingNetwork, txs: []*types.Transaction{blobTx(1, nil, two.BlobHashes())}, wantErr: true, errIs: ErrMissingBlobSidecar},
+		{name: "missing proof", encoding: BlobEncodingNetwork, txs: []*types.Transaction{blobTx(1, &short, two.BlobHashes())}, wantErr: true},
+		{name: "fewer blob hashes", encoding: BlobEncodingSeparate, txs: []*types.Transaction{blobTx(1, two, two.BlobHashes()[:1])}, wantErr: true},
+		{name: "other blob", encoding: BlobEncodingNetwork, txs: []*types.Transaction{blobTx(1, one, []common.Hash{two.BlobHashes()[0]})}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawTxs, blobs, err := EncodeBundleTxs(tt.txs, tt.encoding)
+			if (err != nil) != tt.wantErr || (tt.errIs != nil && !errors.Is(err, tt.errIs)) {
+				t.Fatalf("EncodeBundleTxs error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(rawTxs) != len(tt.txs) {
+				t.Fatalf("%d transactions encoded, want %d", len(rawTxs), len(tt.txs))
+			}
+			for i, raw := range rawTxs {
+				tx := new(types.Transaction)
+				if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+					t.Fatalf("transaction %d: %v", i, err)
+				}
+				if tx.Hash() != tt.txs[i].Hash() {
+					t.Fatalf("transaction %d hash = %s, want %s", i, tx.Hash(), tt.txs[i].Hash())
+				}
+				// The sidecar only travels with the transaction in the network encoding
+				if tx.Type() == types.BlobTxType && (tx.BlobTxSidecar() != nil) != (tt.encoding == BlobEncodingNetwork) {
+					t.Fatalf("transaction %d sent with sidecar %v in the %s encoding", i, tx.BlobTxSidecar() != nil, tt.encoding)
+				}
+			}
+
+			if tt.wantBlobs < 0 {
+				if blobs != nil {
+					t.Fatalf("blobsBundle = %+v, want none", blobs)
+				}
+				return
+			}
+			if blobs == nil || len(blobs.Blobs) != tt.wantBlobs || len(blobs.Commitments) != tt.wantBlobs || len(blobs.Proofs) != tt.wantBlobs {
+				t.Fatalf("blobsBundle = %+v, want %d blobs", blobs, tt.wantBlobs)
+			}
+			// The sidecars are concatenated in transaction order
+			var want []common.Hash
+			for _, tx := range tt.txs {
+				want = append(want, tx.BlobHashes()...)
+			}
+			for i, commitment := range blobs.Commitments {
+				var c kzg4844.Commitment
+				copy(c[:], commitment)
+				if got := common.Hash(kzg4844.CalcBlobHashV1(sha256.New(), &c)); got != want[i] {
+					t.Fatalf("blob %d hash = %s, want %s", i, got, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateBlobEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		wantErr  bool
+	}{
+		{encoding: BlobEncodingNetwork},
+		{encoding: BlobEncodingSeparate},
+		{encoding: "Network", wantErr: true},
+		{encoding: "", wantErr: true},
+		{encoding: "inline", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			if err := ValidateBlobEncoding(tt.encoding); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateBlobEncoding(%q) = %v, want error %v", tt.encoding, err, tt.wantErr)
+			}
+		})
+	}
+}
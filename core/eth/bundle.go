@@ -3,13 +3,15 @@ package eth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/log"
 )
 
 type FlashbotsPayload struct {
@@ -19,20 +21,163 @@ type FlashbotsPayload struct {
 	ID      int                      `json:"id"`
 }
 
-var httpClient = &http.Client{
-	Timeout: 12 * time.Second,
-	Transport: &http.Transport{
-		DisableKeepAlives:   false,
-		MaxIdleConnsPerHost: 1,
-		IdleConnTimeout:     12 * time.Second,
-		TLSHandshakeTimeout: 10 * time.Second,
-	},
+// DefaultBundleMethod is the JSON-RPC method bundles are submitted with unless configured otherwise.
+const DefaultBundleMethod = "eth_sendBundle"
+
+// DefaultBundleTimeout is the deadline of a bundle request, including reading the response.
+const DefaultBundleTimeout = 12 * time.Second
+
+// defaultBundleTransport is the transport of bundle clients that do not supply their own.
+var defaultBundleTransport = &http.Transport{
+	DisableKeepAlives:   false,
+	MaxIdleConnsPerHost: 1,
+	IdleConnTimeout:     12 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
+
+// BundleClientConfig holds the configuration settings for a BundleClient.
+type BundleClientConfig struct {
+	Transport http.RoundTripper // Sends the bundle requests, for example through a proxy or with auth headers. If nil, the default transport is used.
+	Timeout   time.Duration     // The deadline of each bundle request. Zero uses DefaultBundleTimeout.
+	Method    string            // The JSON-RPC method of bundle requests, e.g. "mev_sendBundle". Empty uses DefaultBundleMethod.
+
+	// BlobEncoding is how blob transactions are sent, BlobEncodingNetwork or BlobEncodingSeparate.
+	// Empty uses BlobEncodingNetwork.
+	BlobEncoding string
+
+	// Relays are the endpoints whose bundle latency is recorded under their own relay label.
+	// Latencies of other endpoints are recorded together, so the number of series stays bounded.
+	Relays []string
 }
 
+// BundleClient submits bundles to a relay's bundle endpoint.
+type BundleClient struct {
+	httpClient *http.Client      // The HTTP client the requests are sent with.
+	method     string            // The JSON-RPC method of the requests.
+	blobs      string            // The encoding of blob transactions.
+	relays     map[string]string // The metric names of the configured relays by endpoint.
+}
+
+// NewBundleClient creates a BundleClient.
+//
+// Parameters:
+// - cfg: The BundleClientConfig struct containing the transport, timeout and method.
+//
+// Returns:
+// - A pointer to a BundleClient, or an error if the timeout is negative, the method is blank or the blob encoding is unknown.
+func NewBundleClient(cfg BundleClientConfig) (*BundleClient, error) {
+	transport := cfg.Transport
+	if transport == nil {
+		transport = defaultBundleTransport
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultBundleTimeout
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("bundle timeout must be positive, got %s", timeout)
+	}
+	method := cfg.Method
+	if method == "" {
+		method = DefaultBundleMethod
+	}
+	if strings.ContainsAny(method, " \t\r\n") {
+		return nil, fmt.Errorf("bundle method must be a non-empty name without whitespace, got '%s'", method)
+	}
+	blobs := cfg.BlobEncoding
+	if blobs == "" {
+		blobs = BlobEncodingNetwork
+	}
+	if err := ValidateBlobEncoding(blobs); err != nil {
+		return nil, err
+	}
+	relays := make(map[string]string, len(cfg.Relays))
+	for _, endpoint := range cfg.Relays {
+		relays[endpoint] = relayName(endpoint)
+	}
+	return &BundleClient{httpClient: &http.Client{Timeout: timeout, Transport: transport}, method: method, blobs: blobs, relays: relays}, nil
+}
+
+// defaultBundleClient is used by the package-level SendBundle and SendBundleRange.
+var defaultBundleClient = &BundleClient{
+	httpClient: &http.Client{Timeout: DefaultBundleTimeout, Transport: defaultBundleTransport},
+	method:     DefaultBundleMethod,
+	blobs:      BlobEncodingNetwork,
+}
+
+// SendBundle submits a single transaction as a bundle for blkNum with the default bundle client.
 func SendBundle(RPCURL string, signedTx *types.Transaction, blkNum uint64) (string, error) {
-	binary, err := signedTx.MarshalBinary()
+	return defaultBundleClient.SendBundle(RPCURL, signedTx, blkNum)
+}
+
+// SendBundleRange submits a bundle for every block from `from` to `to` with the default bundle
+// client, see BundleClient.SendBundleRange.
+func SendBundleRange(RPCURL string, txs []*types.Transaction, from, to uint64) (map[uint64]string, error) {
+	return defaultBundleClient.SendBundleRange(RPCURL, txs, from, to)
+}
+
+// SendBundle submits a single transaction as a bundle for blkNum.
+func (c *BundleClient) SendBundle(RPCURL string, signedTx *types.Transaction, blkNum uint64) (string, error) {
+	return c.sendBundle(RPCURL, []*types.Transaction{signedTx}, blkNum)
+}
+
+// MaxBundleBlockRange is the largest number of blocks SendBundleRange submits a bundle for.
+const MaxBundleBlockRange = 32
+
+// SendBundleRange submits the same bundle once for every block from `from` to `to`, inclusive,
+// so the transactions get more than one chance at inclusion. Each submission is a separate
+// bundle request and its result is logged.
+//
+// Parameters:
+// - RPCURL: The URL of the bundle endpoint.
+// - txs: The signed transactions making up the bundle.
+// - from: The first block to target.
+// - to: The last block to target.
+//
+// Returns:
+// - The responses keyed by block number for the successful submissions, and the joined failures.
+func (c *BundleClient) SendBundleRange(RPCURL string, txs []*types.Transaction, from, to uint64) (map[uint64]string, error) {
+	blocks, err := bundleBlocks(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(map[uint64]string, len(blocks))
+	var errs []error
+	for _, blkNum := range blocks {
+		response, err := c.sendBundle(RPCURL, txs, blkNum)
+		if err != nil {
+			logger.Error("Failed to send bundle", "block", blkNum, "err", err)
+			errs = append(errs, fmt.Errorf("block %d: %w", blkNum, err))
+			continue
+		}
+		logger.Info("Sent bundle", "block", blkNum, "response", response)
+		responses[blkNum] = response
+	}
+
+	return responses, errors.Join(errs...)
+}
+
+// bundleBlocks expands an inclusive block range into the block numbers to target.
+func bundleBlocks(from, to uint64) ([]uint64, error) {
+	if to < from {
+		return nil, fmt.Errorf("invalid bundle block range: %d is before %d", to, from)
+	}
+	if to-from >= MaxBundleBlockRange {
+		return nil, fmt.Errorf("bundle block range %d-%d exceeds %d blocks", from, to, MaxBundleBlockRange)
+	}
+
+	blocks := make([]uint64, 0, to-from+1)
+	for blkNum := from; blkNum <= to; blkNum++ {
+		blocks = append(blocks, blkNum)
+	}
+	return blocks, nil
+}
+
+func (c *BundleClient) sendBundle(RPCURL string, txs []*types.Transaction, blkNum uint64) (string, error) {
+	rawTxs, blobs, err := EncodeBundleTxs(txs, c.blobs)
 	if err != nil {
-		log.Error("Error marshal transaction", "err", err)
+		logger.Error("Error marshal transaction", "err", err)
 		return "", err
 	}
 
@@ -40,17 +185,18 @@ func SendBundle(RPCURL string, signedTx *types.Transaction, blkNum uint64) (stri
 
 	payload := FlashbotsPayload{
 		Jsonrpc: "2.0",
-		Method:  "eth_sendBundle",
+		Method:  c.method,
 		Params: []map[string]interface{}{
 			{
-				"txs": []string{
-					hexutil.Encode(binary),
-				},
+				"txs":         rawTxs,
 				"blockNumber": blockNum,
 			},
 		},
 		ID: 1,
 	}
+	if blobs != nil {
+		payload.Params[0]["blobsBundle"] = blobs
+	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -59,22 +205,37 @@ func SendBundle(RPCURL string, signedTx *types.Transaction, blkNum uint64) (stri
 
 	req, err := http.NewRequest("POST", RPCURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		log.Error("an error occurred creating request", "err", err)
+		logger.Error("an error occurred creating request", "err", err)
+		return "", err
 	}
 	req.Header.Add("Content-Type", "application/json")
 
-	resp, err := httpClient.Do(req)
+	// Time the round trip, including reading the response, for the relay's latency histogram
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Error("an error occurred", "err", err)
+		c.recordLatency(RPCURL, time.Since(start), false)
+		logger.Error("an error occurred", "err", err)
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
+	c.recordLatency(RPCURL, time.Since(start), err == nil && bundleAccepted(resp.StatusCode, body))
 	if err != nil {
-		log.Error("an error occurred", "err", err)
+		logger.Error("an error occurred", "err", err)
 		return "", err
 	}
 
 	return string(body), nil
 }
+
+// recordLatency records the round-trip time of a bundle request to RPCURL, under the relay's
+// name if it was configured and as another relay otherwise.
+func (c *BundleClient) recordLatency(RPCURL string, latency time.Duration, success bool) {
+	relay, ok := c.relays[RPCURL]
+	if !ok {
+		relay = otherRelay
+	}
+	recordBundleLatency(relay, latency, success)
+}
@@ -0,0 +1,405 @@
+package eth_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+	"github.com/primev/preconf_blob_bidder/core/eth/ethtest"
+)
+
+var bundleTestSigner = types.NewCancunSigner(big.NewInt(17000))
+
+// signedTransfer returns a signed transfer with the given nonce.
+func signedTransfer(t *testing.T, nonce uint64) *types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	to := common.Address{1}
+	tx, err := types.SignNewTx(key, bundleTestSigner, &types.DynamicFeeTx{
+		ChainID: big.NewInt(17000), Nonce: nonce, To: &to, Value: big.NewInt(1), Gas: 21000, GasFeeCap: big.NewInt(2), GasTipCap: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("SignNewTx: %v", err)
+	}
+	return tx
+}
+
+// signedBlobTx returns a signed blob transaction carrying one empty blob with its sidecar.
+func signedBlobTx(t *testing.T) *types.Transaction {
+	t.Helper()
+	var blob kzg4844.Blob
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	if err != nil {
+		t.Fatalf("BlobToCommitment: %v", err)
+	}
+	proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
+	if err != nil {
+		t.Fatalf("ComputeBlobProof: %v", err)
+	}
+	sidecar := &types.BlobTxSidecar{Blobs: []kzg4844.Blob{blob}, Commitments: []kzg4844.Commitment{commitment}, Proofs: []kzg4844.Proof{proof}}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tx, err := types.SignNewTx(key, bundleTestSigner, &types.BlobTx{
+		ChainID:    uint256.NewInt(17000),
+		Gas:        21000,
+		GasFeeCap:  uint256.NewInt(2),
+		GasTipCap:  uint256.NewInt(1),
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+	if err != nil {
+		t.Fatalf("SignNewTx: %v", err)
+	}
+	return tx
+}
+
+// newRelay starts a MockRelay that is closed with the test and must not see malformed requests.
+func newRelay(t *testing.T) *ethtest.MockRelay {
+	t.Helper()
+	relay := ethtest.NewMockRelay()
+	t.Cleanup(func() {
+		relay.Close()
+		if err := relay.Err(); err != nil {
+			t.Errorf("relay received malformed requests: %v", err)
+		}
+	})
+	return relay
+}
+
+func TestSendBundle(t *testing.T) {
+	tx := signedTransfer(t, 0)
+	tests := []struct {
+		name string
+		resp ethtest.RelayResponse
+	}{
+		{name: "accepted", resp: ethtest.RelayResult(common.Hash{0xbb})},
+		{name: "rejected", resp: ethtest.RelayError(http.StatusBadRequest, -32000, "bundle rejected")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRelay(t)
+			relay.Respond(tt.resp)
+
+			// Error responses are returned as they are for the caller to log
+			response, err := ee.SendBundle(relay.URL(), tx, 0x1234)
+			if err != nil {
+				t.Fatalf("SendBundle: %v", err)
+			}
+			if response != tt.resp.Body {
+				t.Fatalf("response = %s, want %s", response, tt.resp.Body)
+			}
+			if err := relay.ExpectBundle(ee.DefaultBundleMethod, 0x1234, tx); err != nil {
+				t.Fatal(err)
+			}
+			if block := relay.Bundles()[0].Payload.Params[0]["blockNumber"]; block != "0x1234" {
+				t.Fatalf("blockNumber = %v, want 0x1234", block)
+			}
+		})
+	}
+}
+
+func TestSendBundleUnreachable(t *testing.T) {
+	relay := ethtest.NewMockRelay()
+	relay.Close()
+	if _, err := ee.SendBundle(relay.URL(), signedTransfer(t, 0), 1); err == nil {
+		t.Fatal("SendBundle to a closed relay succeeded")
+	}
+}
+
+func TestBundleMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		want   string
+	}{
+		{name: "default", want: ee.DefaultBundleMethod},
+		{name: "mev-share", method: "mev_sendBundle", want: "mev_sendBundle"},
+		{name: "explicit default", method: ee.DefaultBundleMethod, want: ee.DefaultBundleMethod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRelay(t)
+			client, err := ee.NewBundleClient(ee.BundleClientConfig{Method: tt.method})
+			if err != nil {
+				t.Fatalf("NewBundleClient: %v", err)
+			}
+			tx := signedTransfer(t, 0)
+			if _, err := client.SendBundle(relay.URL(), tx, 10); err != nil {
+				t.Fatalf("SendBundle: %v", err)
+			}
+			if err := relay.ExpectBundle(tt.want, 10, tx); err != nil {
+				t.Fatal(err)
+			}
+
+			// Every submission of a range uses the method too
+			if _, err := client.SendBundleRange(relay.URL(), []*types.Transaction{tx}, 11, 12); err != nil {
+				t.Fatalf("SendBundleRange: %v", err)
+			}
+			for _, block := range []uint64{11, 12} {
+				if err := relay.ExpectBundle(tt.want, block, tx); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBundleClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ee.BundleClientConfig
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "separate blobs", cfg: ee.BundleClientConfig{BlobEncoding: ee.BlobEncodingSeparate}},
+		{name: "negative timeout", cfg: ee.BundleClientConfig{Timeout: -1}, wantErr: true},
+		{name: "custom method", cfg: ee.BundleClientConfig{Method: "mev_sendBundle"}},
+		{name: "method with spaces", cfg: ee.BundleClientConfig{Method: "eth sendBundle"}, wantErr: true},
+		{name: "method with a tab", cfg: ee.BundleClientConfig{Method: "eth_sendBundle\t"}, wantErr: true},
+		{name: "method with a newline", cfg: ee.BundleClientConfig{Method: "eth_sendBundle\n"}, wantErr: true},
+		{name: "unknown blob encoding", cfg: ee.BundleClientConfig{BlobEncoding: "inline"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ee.NewBundleClient(tt.cfg); (err != nil) != tt.wantErr {
+				t.Fatalf("NewBundleClient error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendBundleRange(t *testing.T) {
+	txs := []*types.Transaction{signedTransfer(t, 0), signedTransfer(t, 1)}
+	tests := []struct {
+		name       string
+		from, to   uint64
+		wantBlocks []uint64
+		wantErr    bool
+	}{
+		{name: "one block", from: 100, to: 100, wantBlocks: []uint64{100}},
+		{name: "three blocks", from: 100, to: 102, wantBlocks: []uint64{100, 101, 102}},
+		{name: "widest range", from: 100, to: 100 + ee.MaxBundleBlockRange - 1, wantBlocks: make([]uint64, ee.MaxBundleBlockRange)},
+		{name: "too wide", from: 100, to: 100 + ee.MaxBundleBlockRange, wantErr: true},
+		{name: "reversed", from: 101, to: 100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRelay(t)
+			responses, err := ee.SendBundleRange(relay.URL(), txs, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendBundleRange error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if n := len(relay.Bundles()); n != 0 {
+					t.Fatalf("%d bundles sent for an invalid range", n)
+				}
+				return
+			}
+			if len(responses) != len(tt.wantBlocks) || len(relay.Bundles()) != len(tt.wantBlocks) {
+				t.Fatalf("%d responses and %d bundles, want %d", len(responses), len(relay.Bundles()), len(tt.wantBlocks))
+			}
+			for block := tt.from; block <= tt.to; block++ {
+				if err := relay.ExpectBundle(ee.DefaultBundleMethod, block, txs...); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSendBundleRangeToRelays(t *testing.T) {
+	tx := signedTransfer(t, 0)
+	relays := []*ethtest.MockRelay{newRelay(t), newRelay(t)}
+	client, err := ee.NewBundleClient(ee.BundleClientConfig{Relays: []string{relays[0].URL(), relays[1].URL()}})
+	if err != nil {
+		t.Fatalf("NewBundleClient: %v", err)
+	}
+	for _, relay := range relays {
+		if _, err := client.SendBundleRange(relay.URL(), []*types.Transaction{tx}, 7, 8); err != nil {
+			t.Fatalf("SendBundleRange: %v", err)
+		}
+	}
+	for i, relay := range relays {
+		for _, block := range []uint64{7, 8} {
+			if err := relay.ExpectBundle(ee.DefaultBundleMethod, block, tx); err != nil {
+				t.Fatalf("relay %d: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestBundleBlobEncoding(t *testing.T) {
+	blobTx := signedBlobTx(t)
+	transfer := signedTransfer(t, 0)
+	sidecar := blobTx.BlobTxSidecar()
+	tests := []struct {
+		name      string
+		encoding  string
+		txs       []*types.Transaction
+		sidecar   bool // Whether the blob transaction is sent with its sidecar.
+		wantBlobs int  // Blobs sent apart from the transactions.
+		wantErr   error
+	}{
+		{name: "network", encoding: ee.BlobEncodingNetwork, txs: []*types.Transaction{transfer, blobTx}, sidecar: true},
+		{name: "separate", encoding: ee.BlobEncodingSeparate, txs: []*types.Transaction{transfer, blobTx}, wantBlobs: 1},
+		{name: "no blob transactions", encoding: ee.BlobEncodingSeparate, txs: []*types.Transaction{transfer}},
+		{name: "missing sidecar", encoding: ee.BlobEncodingNetwork, txs: []*types.Transaction{blobTx.WithoutBlobTxSidecar()}, wantErr: ee.ErrMissingBlobSidecar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRelay(t)
+			client, err := ee.NewBundleClient(ee.BundleClientConfig{BlobEncoding: tt.encoding})
+			if err != nil {
+				t.Fatalf("NewBundleClient: %v", err)
+			}
+			_, err = client.SendBundleRange(relay.URL(), tt.txs, 5, 5)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendBundleRange error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if n := len(relay.Bundles()); n != 0 {
+					t.Fatalf("%d bundles sent without a sidecar", n)
+				}
+				return
+			}
+
+			// The transactions keep their hashes whether or not the sidecar travels with them
+			if err := relay.ExpectBundle(ee.DefaultBundleMethod, 5, tt.txs...); err != nil {
+				t.Fatal(err)
+			}
+			bundle := relay.Bundles()[0]
+			last := bundle.Txs[len(bundle.Txs)-1]
+			if got := last.BlobTxSidecar() != nil; last.Type() == types.BlobTxType && got != tt.sidecar {
+				t.Fatalf("blob transaction sent with sidecar %v, want %v", got, tt.sidecar)
+			}
+			if tt.wantBlobs == 0 {
+				if bundle.Blobs != nil {
+					t.Fatalf("unexpected blobsBundle %+v", bundle.Blobs)
+				}
+				return
+			}
+			if bundle.Blobs == nil || len(bundle.Blobs.Blobs) != tt.wantBlobs || len(bundle.Blobs.Commitments) != tt.wantBlobs || len(bundle.Blobs.Proofs) != tt.wantBlobs {
+				t.Fatalf("blobsBundle = %+v, want %d blobs", bundle.Blobs, tt.wantBlobs)
+			}
+			if !bytes.Equal(bundle.Blobs.Blobs[0], sidecar.Blobs[0][:]) || !bytes.Equal(bundle.Blobs.Commitments[0], sidecar.Commitments[0][:]) || !bytes.Equal(bundle.Blobs.Proofs[0], sidecar.Proofs[0][:]) {
+				t.Fatal("blobsBundle does not match the sidecar")
+			}
+		})
+	}
+}
+
+// failingTransport fails the bundle requests for one block and sends the others.
+type failingTransport struct {
+	block string // The hex block number whose requests fail.
+}
+
+func (f failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(body, []byte(`"blockNumber":"`+f.block+`"`)) {
+		return nil, errors.New("connection reset")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSendBundleRangePartialFailure(t *testing.T) {
+	relay := newRelay(t)
+	client, err := ee.NewBundleClient(ee.BundleClientConfig{Transport: failingTransport{block: "0x65"}})
+	if err != nil {
+		t.Fatalf("NewBundleClient: %v", err)
+	}
+	tx := signedTransfer(t, 0)
+
+	// A failed block does not stop the others from being submitted
+	responses, err := client.SendBundleRange(relay.URL(), []*types.Transaction{tx}, 100, 102)
+	if err == nil || !strings.Contains(err.Error(), "block 101") {
+		t.Fatalf("SendBundleRange error = %v, want the failure of block 101", err)
+	}
+	if _, ok := responses[101]; ok || len(responses) != 2 {
+		t.Fatalf("responses for blocks %v, want 100 and 102", responses)
+	}
+	for _, block := range []uint64{100, 102} {
+		if err := relay.ExpectBundle(ee.DefaultBundleMethod, block, tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// recordingTransport records the requests it sends, or fails them all with err.
+type recordingTransport struct {
+	mu   sync.Mutex
+	urls []string
+	err  error
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.urls = append(r.urls, req.URL.String())
+	r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestBundleClientTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport *recordingTransport // Nil uses the default transport.
+		wantErr   bool
+	}{
+		{name: "default"},
+		{name: "custom", transport: &recordingTransport{}},
+		{name: "custom failing", transport: &recordingTransport{err: errors.New("proxy refused")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRelay(t)
+			cfg := ee.BundleClientConfig{}
+			if tt.transport != nil {
+				cfg.Transport = tt.transport
+			}
+			client, err := ee.NewBundleClient(cfg)
+			if err != nil {
+				t.Fatalf("NewBundleClient: %v", err)
+			}
+			tx := signedTransfer(t, 0)
+
+			_, err = client.SendBundle(relay.URL(), tx, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendBundle error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.transport != nil && (len(tt.transport.urls) != 1 || !strings.HasPrefix(tt.transport.urls[0], relay.URL())) {
+				t.Fatalf("transport sent %v, want one request to %s", tt.transport.urls, relay.URL())
+			}
+			wantBundles := 1
+			if tt.wantErr {
+				wantBundles = 0
+			}
+			if len(relay.Bundles()) != wantBundles {
+				t.Fatalf("relay received %d bundles, want %d", len(relay.Bundles()), wantBundles)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// coinbasePaymentGas is the gas limit of a coinbase payment, a plain transfer.
+const coinbasePaymentGas = 21_000
+
+// AppendCoinbasePayment appends a transfer of amount wei to the builder's coinbase after the last
+// transaction of a bundle, so the builder is paid directly when it includes the bundle. The
+// payment is signed by the account that signed the last transaction, with the next nonce and the
+// same fee caps.
+//
+// The coinbase of a block is only known once it is built, so it must be configured: it is the
+// suggestedFeeRecipient the targeted builder uses.
+//
+// Parameters:
+// - txs: The signed bundle transactions; the last one must be signed by authAcct.
+// - authAcct: The account paying the builder.
+// - coinbase: The builder's coinbase address.
+// - amount: The payment in wei.
+//
+// Returns:
+// - The bundle with the payment appended, or an error if the payment cannot be built.
+func AppendCoinbasePayment(txs []*types.Transaction, authAcct bb.AuthAcct, coinbase common.Address, amount *big.Int) ([]*types.Transaction, error) {
+	if len(txs) == 0 {
+		return nil, errors.New("coinbase payment requires a transaction to follow")
+	}
+	if coinbase == (common.Address{}) {
+		return nil, errors.New("coinbase payment requires a coinbase address")
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, errors.New("coinbase payment must be positive")
+	}
+
+	last := txs[len(txs)-1]
+	signer := types.LatestSignerForChainID(last.ChainId())
+	sender, err := types.Sender(signer, last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover bundle sender: %w", err)
+	}
+	if sender != authAcct.Address {
+		return nil, fmt.Errorf("last bundle transaction is signed by %s, not %s", sender, authAcct.Address)
+	}
+
+	payment := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   last.ChainId(),
+		Nonce:     last.Nonce() + 1,
+		To:        &coinbase,
+		Value:     amount,
+		Gas:       coinbasePaymentGas,
+		GasFeeCap: last.GasFeeCap(),
+		GasTipCap: last.GasTipCap(),
+	})
+	signedPayment, err := types.SignTx(payment, signer, authAcct.PrivateKey)
+	if err != nil {
+		logger.Error("Failed to sign coinbase payment", "error", err)
+		return nil, err
+	}
+
+	return append(txs[:len(txs):len(txs)], signedPayment), nil
+}
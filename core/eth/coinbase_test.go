@@ -0,0 +1,91 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+func TestAppendCoinbasePayment(t *testing.T) {
+	authAcct := testAccount(t)
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	chainID := big.NewInt(17000)
+	signer := types.LatestSignerForChainID(chainID)
+	sign := func(key bb.AuthAcct, nonce uint64) *types.Transaction {
+		to := common.Address{0xee}
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID: chainID, Nonce: nonce, To: &to, Value: big.NewInt(1), Gas: 21000,
+			GasFeeCap: big.NewInt(30_000_000_000), GasTipCap: big.NewInt(2_000_000_000),
+		}), signer, key.PrivateKey)
+		if err != nil {
+			t.Fatalf("SignTx: %v", err)
+		}
+		return tx
+	}
+	other := bb.AuthAcct{PrivateKey: otherKey, Address: crypto.PubkeyToAddress(otherKey.PublicKey)}
+	coinbase := common.Address{0xcb}
+
+	tests := []struct {
+		name     string
+		txs      []*types.Transaction
+		coinbase common.Address
+		amount   *big.Int
+		wantErr  bool
+	}{
+		{name: "one transaction", txs: []*types.Transaction{sign(authAcct, 5)}, coinbase: coinbase, amount: big.NewInt(1e15)},
+		{name: "several transactions", txs: []*types.Transaction{sign(other, 0), sign(authAcct, 5), sign(authAcct, 6)}, coinbase: coinbase, amount: big.NewInt(1)},
+		{name: "empty bundle", coinbase: coinbase, amount: big.NewInt(1), wantErr: true},
+		{name: "no coinbase", txs: []*types.Transaction{sign(authAcct, 5)}, amount: big.NewInt(1), wantErr: true},
+		{name: "zero amount", txs: []*types.Transaction{sign(authAcct, 5)}, coinbase: coinbase, amount: new(big.Int), wantErr: true},
+		{name: "no amount", txs: []*types.Transaction{sign(authAcct, 5)}, coinbase: coinbase, wantErr: true},
+		{name: "last signed by another account", txs: []*types.Transaction{sign(authAcct, 5), sign(other, 0)}, coinbase: coinbase, amount: big.NewInt(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := AppendCoinbasePayment(tt.txs, authAcct, tt.coinbase, tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AppendCoinbasePayment error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(bundle) != len(tt.txs)+1 {
+				t.Fatalf("bundle has %d transactions, want %d", len(bundle), len(tt.txs)+1)
+			}
+			for i, tx := range tt.txs {
+				if bundle[i] != tx {
+					t.Fatalf("transaction %d changed", i)
+				}
+			}
+
+			last, payment := tt.txs[len(tt.txs)-1], bundle[len(bundle)-1]
+			sender, err := types.Sender(signer, payment)
+			if err != nil || sender != authAcct.Address {
+				t.Fatalf("payment sender = %s, %v, want %s", sender, err, authAcct.Address)
+			}
+			if *payment.To() != coinbase || payment.Value().Cmp(tt.amount) != 0 || payment.Nonce() != last.Nonce()+1 {
+				t.Fatalf("payment of %s to %s with nonce %d, want %s to %s with nonce %d", payment.Value(), payment.To(), payment.Nonce(), tt.amount, coinbase, last.Nonce()+1)
+			}
+			if payment.GasFeeCap().Cmp(last.GasFeeCap()) != 0 || payment.GasTipCap().Cmp(last.GasTipCap()) != 0 || payment.Gas() != coinbasePaymentGas {
+				t.Fatalf("payment fees = %s, %s, gas %d, want those of the last transaction", payment.GasFeeCap(), payment.GasTipCap(), payment.Gas())
+			}
+		})
+	}
+
+	// Appending does not write to the caller's backing array
+	txs := make([]*types.Transaction, 1, 2)
+	txs[0] = sign(authAcct, 5)
+	if _, err := AppendCoinbasePayment(txs, authAcct, coinbase, big.NewInt(1)); err != nil {
+		t.Fatalf("AppendCoinbasePayment: %v", err)
+	}
+	if extended := txs[:2]; extended[1] != nil {
+		t.Fatal("the payment was written to the caller's slice")
+	}
+}
@@ -0,0 +1,233 @@
+// Package ethtest provides helpers for exercising the eth package without live infrastructure.
+package ethtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ee "github.com/primev/preconf_blob_bidder/core/eth"
+)
+
+// RelayBundle is a bundle received by a MockRelay, decoded from its FlashbotsPayload.
+type RelayBundle struct {
+	Payload     ee.FlashbotsPayload  // The payload as it was received.
+	Method      string               // The JSON-RPC method of the request.
+	BlockNumber uint64               // The block the bundle targets.
+	Txs         []*types.Transaction // The transactions of the bundle, in order.
+	Blobs       *ee.BlobsBundle      // The sidecars sent apart from the transactions, if any.
+}
+
+// RelayResponse is the HTTP response a MockRelay answers a bundle with.
+type RelayResponse struct {
+	Status int    // The HTTP status code; zero means 200.
+	Body   string // The response body.
+}
+
+// RelayResult returns a successful JSON-RPC response carrying bundleHash.
+func RelayResult(bundleHash common.Hash) RelayResponse {
+	return RelayResponse{
+		Status: http.StatusOK,
+		Body:   fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"%s"}}`, bundleHash.Hex()),
+	}
+}
+
+// RelayError returns a JSON-RPC error response with the given HTTP status, code and message.
+func RelayError(status, code int, message string) RelayResponse {
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"error":   map[string]interface{}{"code": code, "message": message},
+	})
+	return RelayResponse{Status: status, Body: string(body)}
+}
+
+// MockRelay is an httptest server standing in for a bundle relay. It records every bundle it
+// receives and answers with the response chosen by its responder, so bundle submission can be
+// checked without a live relay. Use one MockRelay per relay to exercise multi-relay submission.
+type MockRelay struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	bundles   []RelayBundle
+	errs      []error
+	responder func(RelayBundle) RelayResponse
+}
+
+// NewMockRelay starts a MockRelay that accepts every bundle with a RelayResult of the zero hash.
+// It must be closed with Close.
+func NewMockRelay() *MockRelay {
+	r := &MockRelay{
+		responder: func(RelayBundle) RelayResponse { return RelayResult(common.Hash{}) },
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	return r
+}
+
+// URL returns the endpoint bundles should be sent to.
+func (r *MockRelay) URL() string {
+	return r.server.URL
+}
+
+// Close shuts the relay down.
+func (r *MockRelay) Close() {
+	r.server.Close()
+}
+
+// Respond sets the response for all following bundles.
+func (r *MockRelay) Respond(resp RelayResponse) {
+	r.RespondWith(func(RelayBundle) RelayResponse { return resp })
+}
+
+// RespondWith sets a function choosing the response for each following bundle, for example to
+// fail the first attempts of a retry or only some target blocks.
+func (r *MockRelay) RespondWith(fn func(RelayBundle) RelayResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responder = fn
+}
+
+// Bundles returns the bundles received so far, in order.
+func (r *MockRelay) Bundles() []RelayBundle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RelayBundle(nil), r.bundles...)
+}
+
+// Err returns the joined errors of requests the relay could not decode as a bundle.
+func (r *MockRelay) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.errs...)
+}
+
+// ExpectBundle checks that a bundle with exactly txs, in order, was received for blockNumber
+// under the given JSON-RPC method.
+//
+// Parameters:
+// - method: The expected JSON-RPC method, e.g. "eth_sendBundle".
+// - blockNumber: The block the bundle should target.
+// - txs: The expected transactions.
+//
+// Returns:
+// - An error describing the mismatch, or nil if such a bundle was received.
+func (r *MockRelay) ExpectBundle(method string, blockNumber uint64, txs ...*types.Transaction) error {
+	bundles := r.Bundles()
+	for _, bundle := range bundles {
+		if bundle.BlockNumber != blockNumber {
+			continue
+		}
+		if bundle.Method != method {
+			return fmt.Errorf("bundle for block %d sent with method %s, want %s", blockNumber, bundle.Method, method)
+		}
+		if len(bundle.Txs) != len(txs) {
+			return fmt.Errorf("bundle for block %d has %d transactions, want %d", blockNumber, len(bundle.Txs), len(txs))
+		}
+		for i, tx := range txs {
+			if bundle.Txs[i].Hash() != tx.Hash() {
+				return fmt.Errorf("bundle for block %d has transaction %s at %d, want %s", blockNumber, bundle.Txs[i].Hash(), i, tx.Hash())
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("no bundle received for block %d (%d bundles received)", blockNumber, len(bundles))
+}
+
+func (r *MockRelay) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	bundle, err := decodeBundle(req)
+	if err != nil {
+		r.mu.Lock()
+		r.errs = append(r.errs, err)
+		r.mu.Unlock()
+		resp := RelayError(http.StatusBadRequest, -32602, err.Error())
+		w.WriteHeader(resp.Status)
+		io.WriteString(w, resp.Body)
+		return
+	}
+
+	r.mu.Lock()
+	r.bundles = append(r.bundles, bundle)
+	responder := r.responder
+	r.mu.Unlock()
+
+	resp := responder(bundle)
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.Status)
+	io.WriteString(w, resp.Body)
+}
+
+// decodeBundle decodes a bundle request, checking it has the shape the eth package sends.
+func decodeBundle(req *http.Request) (RelayBundle, error) {
+	if req.Method != http.MethodPost {
+		return RelayBundle{}, fmt.Errorf("unexpected HTTP method %s", req.Method)
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/json" {
+		return RelayBundle{}, fmt.Errorf("unexpected content type '%s'", contentType)
+	}
+
+	var payload ee.FlashbotsPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		return RelayBundle{}, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if payload.Jsonrpc != "2.0" {
+		return RelayBundle{}, fmt.Errorf("unexpected jsonrpc version '%s'", payload.Jsonrpc)
+	}
+	if len(payload.Params) != 1 {
+		return RelayBundle{}, fmt.Errorf("expected 1 param, got %d", len(payload.Params))
+	}
+	params := payload.Params[0]
+
+	blockHex, ok := params["blockNumber"].(string)
+	if !ok {
+		return RelayBundle{}, errors.New("blockNumber is missing or not a string")
+	}
+	blockNumber, err := hexutil.DecodeUint64(blockHex)
+	if err != nil {
+		return RelayBundle{}, fmt.Errorf("invalid blockNumber '%s': %w", blockHex, err)
+	}
+
+	rawTxs, ok := params["txs"].([]interface{})
+	if !ok {
+		return RelayBundle{}, errors.New("txs is missing or not a list")
+	}
+	txs := make([]*types.Transaction, 0, len(rawTxs))
+	for i, rawTx := range rawTxs {
+		rawHex, ok := rawTx.(string)
+		if !ok {
+			return RelayBundle{}, fmt.Errorf("transaction %d is not a string", i)
+		}
+		binary, err := hexutil.Decode(rawHex)
+		if err != nil {
+			return RelayBundle{}, fmt.Errorf("transaction %d is not hex: %w", i, err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(binary); err != nil {
+			return RelayBundle{}, fmt.Errorf("failed to decode transaction %d: %w", i, err)
+		}
+		txs = append(txs, tx)
+	}
+
+	var blobs *ee.BlobsBundle
+	if rawBlobs, ok := params["blobsBundle"]; ok {
+		encoded, err := json.Marshal(rawBlobs)
+		if err != nil {
+			return RelayBundle{}, err
+		}
+		blobs = new(ee.BlobsBundle)
+		if err := json.Unmarshal(encoded, blobs); err != nil {
+			return RelayBundle{}, fmt.Errorf("failed to decode blobsBundle: %w", err)
+		}
+	}
+
+	return RelayBundle{Payload: payload, Method: payload.Method, BlockNumber: blockNumber, Txs: txs, Blobs: blobs}, nil
+}
@@ -0,0 +1,174 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BlockFees holds the base fee and blob base fee of a block, in wei.
+type BlockFees struct {
+	BaseFee     *big.Int `json:"baseFee"`     // nil for pre-London headers
+	BlobBaseFee *big.Int `json:"blobBaseFee"` // nil for pre-Dencun headers
+}
+
+// FeeSnapshot derives the base fee and blob base fee from a block header.
+// Fees that the header's fork does not define are left nil.
+func FeeSnapshot(header *types.Header) BlockFees {
+	var fees BlockFees
+	if header.BaseFee != nil {
+		fees.BaseFee = new(big.Int).Set(header.BaseFee)
+	}
+	if header.ExcessBlobGas != nil {
+		fees.BlobBaseFee = eip4844.CalcBlobFee(*header.ExcessBlobGas)
+	}
+	return fees
+}
+
+// DefaultPriorityFeeMultiplier and DefaultMaxFeeMultiplier are the fee multipliers used when a
+// FeeConfig leaves them unset.
+const (
+	DefaultPriorityFeeMultiplier = 2.0
+	DefaultMaxFeeMultiplier      = 2.0
+)
+
+// FeeConfig controls the fees of the transactions built by this package. The max priority fee
+// is the base fee times PriorityFeeMultiplier, and the max fee per gas is the base fee times
+// MaxFeeMultiplier plus the max priority fee, so the two multipliers can be tuned separately.
+// Zero multipliers use the defaults. FeeCap and TipCap pin exact values instead.
+type FeeConfig struct {
+	PriorityFeeMultiplier float64  // Multiplier applied to the base fee to get the max priority fee.
+	MaxFeeMultiplier      float64  // Multiplier applied to the base fee to get the max fee per gas, before adding the max priority fee.
+	FeeCap                *big.Int // If set, the max fee per gas in wei, replacing the multipliers.
+	TipCap                *big.Int // If set, the tip per gas in wei. Transactions tip nothing otherwise.
+	MinTip                *big.Int // If set, the least tip per gas in wei, so transactions always carry an incentive.
+}
+
+// TxOptions holds the optional settings of the transaction builders. The zero value uses the
+// defaults.
+type TxOptions struct {
+	Fees          FeeConfig       // The fee multipliers.
+	Nonce         *uint64         // The nonce to use. If nil, the account's pending nonce is read from the node.
+	Oracle        FeeOracle       // The source of the base fee and tip. If nil, the latest header's base fee is used.
+	BlobRecipient *common.Address // The recipient of blob transactions. If nil, they are sent to the account itself.
+	MaxBlobs      int             // The most blobs per transaction, e.g. PragueMaxBlobsPerTransaction after Prague. Zero uses MaxBlobsPerTransaction.
+	ChainID       *big.Int        // The chain ID transactions are signed for, for nodes that misreport it. If nil, the node's network ID is used.
+}
+
+// maxBlobs returns the most blobs a transaction built with the options may carry.
+func (o TxOptions) maxBlobs() int {
+	if o.MaxBlobs == 0 {
+		return MaxBlobsPerTransaction
+	}
+	return o.MaxBlobs
+}
+
+// Validate checks that the multipliers are either unset or at least 1.
+func (c FeeConfig) Validate() error {
+	if c.PriorityFeeMultiplier != 0 && !(c.PriorityFeeMultiplier >= 1) {
+		return fmt.Errorf("priority fee multiplier must be at least 1, got %v", c.PriorityFeeMultiplier)
+	}
+	if c.MaxFeeMultiplier != 0 && !(c.MaxFeeMultiplier >= 1) {
+		return fmt.Errorf("max fee multiplier must be at least 1, got %v", c.MaxFeeMultiplier)
+	}
+	if c.FeeCap != nil && c.FeeCap.Sign() < 0 {
+		return fmt.Errorf("fee cap must not be negative, got %s", c.FeeCap)
+	}
+	if c.TipCap != nil && c.TipCap.Sign() < 0 {
+		return fmt.Errorf("tip cap must not be negative, got %s", c.TipCap)
+	}
+	if c.FeeCap != nil && c.TipCap != nil && c.FeeCap.Cmp(c.TipCap) < 0 {
+		return fmt.Errorf("fee cap %s is lower than tip cap %s", c.FeeCap, c.TipCap)
+	}
+	if c.MinTip != nil && c.MinTip.Sign() < 0 {
+		return fmt.Errorf("min tip must not be negative, got %s", c.MinTip)
+	}
+	if c.FeeCap != nil && c.MinTip != nil && c.FeeCap.Cmp(c.MinTip) < 0 {
+		return fmt.Errorf("fee cap %s is lower than min tip %s", c.FeeCap, c.MinTip)
+	}
+	return nil
+}
+
+// Caps returns the tip cap and fee cap to set on a transaction built on a block with the given
+// base fee. Pinned values are used as they are; otherwise the fee cap is computed from the
+// multipliers and the tip is zero, since providers are paid through the preconfirmation bid.
+// A MinTip raises the tip to at least that much, and a computed fee cap to at least the base fee
+// plus the tip, so the tip is paid in full even when the base fee is near zero.
+//
+// Parameters:
+// - baseFee: The base fee of the block the transaction is built on, in wei.
+//
+// Returns:
+// - The tip cap and fee cap in wei, or an error if the config is invalid or the caps are inverted.
+func (c FeeConfig) Caps(baseFee *big.Int) (*big.Int, *big.Int, error) {
+	tipCap := new(big.Int)
+	if c.TipCap != nil {
+		tipCap.Set(c.TipCap)
+	}
+	if c.MinTip != nil && tipCap.Cmp(c.MinTip) < 0 {
+		tipCap.Set(c.MinTip)
+	}
+
+	var feeCap *big.Int
+	if c.FeeCap != nil {
+		if err := c.Validate(); err != nil {
+			return nil, nil, err
+		}
+		feeCap = new(big.Int).Set(c.FeeCap)
+	} else {
+		var err error
+		if _, feeCap, err = c.Fees(baseFee); err != nil {
+			return nil, nil, err
+		}
+		if c.MinTip != nil {
+			if minFeeCap := new(big.Int).Add(baseFee, tipCap); feeCap.Cmp(minFeeCap) < 0 {
+				feeCap = minFeeCap
+			}
+		}
+	}
+
+	if feeCap.Cmp(tipCap) < 0 {
+		return nil, nil, fmt.Errorf("fee cap %s is lower than tip cap %s", feeCap, tipCap)
+	}
+	return tipCap, feeCap, nil
+}
+
+// Fees computes the max priority fee and max fee per gas for a block with the given base fee.
+//
+// Parameters:
+// - baseFee: The base fee of the block the transaction is built on, in wei.
+//
+// Returns:
+// - The max priority fee and the max fee per gas in wei, or an error if the config is invalid.
+func (c FeeConfig) Fees(baseFee *big.Int) (*big.Int, *big.Int, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if baseFee == nil {
+		return nil, nil, fmt.Errorf("block has no base fee")
+	}
+
+	priorityMultiplier := c.PriorityFeeMultiplier
+	if priorityMultiplier == 0 {
+		priorityMultiplier = DefaultPriorityFeeMultiplier
+	}
+	maxFeeMultiplier := c.MaxFeeMultiplier
+	if maxFeeMultiplier == 0 {
+		maxFeeMultiplier = DefaultMaxFeeMultiplier
+	}
+
+	maxPriorityFee := mulFloat(baseFee, priorityMultiplier)
+	maxFeePerGas := new(big.Int).Add(mulFloat(baseFee, maxFeeMultiplier), maxPriorityFee)
+	return maxPriorityFee, maxFeePerGas, nil
+}
+
+// mulFloat multiplies a wei amount by a multiplier, rounding down to a whole wei.
+func mulFloat(amount *big.Int, multiplier float64) *big.Int {
+	product := new(big.Float).SetInt(amount)
+	product.Mul(product, big.NewFloat(multiplier))
+	result, _ := product.Int(nil)
+	return result
+}
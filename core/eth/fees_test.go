@@ -0,0 +1,114 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFeeConfigFees(t *testing.T) {
+	baseFee := big.NewInt(10_000_000_000)
+	tests := []struct {
+		name                string
+		cfg                 FeeConfig
+		baseFee             *big.Int
+		wantTip, wantMaxFee int64
+		wantErr             bool
+	}{
+		{name: "defaults", cfg: FeeConfig{}, baseFee: baseFee, wantTip: 20_000_000_000, wantMaxFee: 40_000_000_000},
+		{name: "priority multiplier only", cfg: FeeConfig{PriorityFeeMultiplier: 1.5}, baseFee: baseFee, wantTip: 15_000_000_000, wantMaxFee: 35_000_000_000},
+		{name: "max fee multiplier only", cfg: FeeConfig{MaxFeeMultiplier: 3}, baseFee: baseFee, wantTip: 20_000_000_000, wantMaxFee: 50_000_000_000},
+		{name: "both", cfg: FeeConfig{PriorityFeeMultiplier: 1, MaxFeeMultiplier: 1.25}, baseFee: baseFee, wantTip: 10_000_000_000, wantMaxFee: 22_500_000_000},
+		{name: "tip without raising the ceiling", cfg: FeeConfig{PriorityFeeMultiplier: 3, MaxFeeMultiplier: 1}, baseFee: baseFee, wantTip: 30_000_000_000, wantMaxFee: 40_000_000_000},
+		{name: "ceiling without raising the tip", cfg: FeeConfig{PriorityFeeMultiplier: 1, MaxFeeMultiplier: 3}, baseFee: baseFee, wantTip: 10_000_000_000, wantMaxFee: 40_000_000_000},
+		{name: "rounds down", cfg: FeeConfig{PriorityFeeMultiplier: 1.5, MaxFeeMultiplier: 1}, baseFee: big.NewInt(7), wantTip: 10, wantMaxFee: 17},
+		{name: "zero base fee", cfg: FeeConfig{}, baseFee: big.NewInt(0)},
+		{name: "priority multiplier below 1", cfg: FeeConfig{PriorityFeeMultiplier: 0.5}, baseFee: baseFee, wantErr: true},
+		{name: "max fee multiplier below 1", cfg: FeeConfig{MaxFeeMultiplier: 0.9}, baseFee: baseFee, wantErr: true},
+		{name: "negative multiplier", cfg: FeeConfig{MaxFeeMultiplier: -2}, baseFee: baseFee, wantErr: true},
+		{name: "no base fee", cfg: FeeConfig{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tip, maxFee, err := tt.cfg.Fees(tt.baseFee)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fees error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (tip.Int64() != tt.wantTip || maxFee.Int64() != tt.wantMaxFee) {
+				t.Fatalf("Fees = %s, %s, want %d, %d", tip, maxFee, tt.wantTip, tt.wantMaxFee)
+			}
+		})
+	}
+}
+
+func TestFeeConfigCaps(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	tests := []struct {
+		name                string
+		cfg                 FeeConfig
+		wantTip, wantFeeCap *big.Int
+		wantErr             bool
+	}{
+		{name: "multipliers", cfg: FeeConfig{}, wantTip: gwei(0), wantFeeCap: gwei(40)},
+		{name: "pinned fee cap", cfg: FeeConfig{FeeCap: gwei(15)}, wantTip: gwei(0), wantFeeCap: gwei(15)},
+		{name: "pinned tip cap", cfg: FeeConfig{TipCap: gwei(2)}, wantTip: gwei(2), wantFeeCap: gwei(40)},
+		{name: "both pinned", cfg: FeeConfig{FeeCap: gwei(12), TipCap: gwei(2)}, wantTip: gwei(2), wantFeeCap: gwei(12)},
+		{name: "pinned fee cap ignores multipliers", cfg: FeeConfig{FeeCap: gwei(12), MaxFeeMultiplier: 5}, wantTip: gwei(0), wantFeeCap: gwei(12)},
+		{name: "fee cap below tip cap", cfg: FeeConfig{FeeCap: gwei(1), TipCap: gwei(2)}, wantErr: true},
+		{name: "tip cap above computed fee cap", cfg: FeeConfig{TipCap: gwei(50)}, wantErr: true},
+		{name: "negative fee cap", cfg: FeeConfig{FeeCap: big.NewInt(-1)}, wantErr: true},
+		{name: "negative tip cap", cfg: FeeConfig{TipCap: big.NewInt(-1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tip, feeCap, err := tt.cfg.Caps(gwei(10))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Caps error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (tip.Cmp(tt.wantTip) != 0 || feeCap.Cmp(tt.wantFeeCap) != 0) {
+				t.Fatalf("Caps = %s, %s, want %s, %s", tip, feeCap, tt.wantTip, tt.wantFeeCap)
+			}
+		})
+	}
+
+	// The pinned values are copied, not aliased
+	cfg := FeeConfig{FeeCap: gwei(12), TipCap: gwei(2)}
+	tip, feeCap, err := cfg.Caps(gwei(10))
+	if err != nil {
+		t.Fatalf("Caps: %v", err)
+	}
+	tip.SetInt64(0)
+	feeCap.SetInt64(0)
+	if cfg.TipCap.Cmp(gwei(2)) != 0 || cfg.FeeCap.Cmp(gwei(12)) != 0 {
+		t.Fatalf("Caps changed the config to %s, %s", cfg.TipCap, cfg.FeeCap)
+	}
+}
+
+func TestFeeConfigCapsMinTip(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	tests := []struct {
+		name                string
+		cfg                 FeeConfig
+		baseFee             *big.Int
+		wantTip, wantFeeCap *big.Int
+		wantErr             bool
+	}{
+		{name: "raises a zero tip", cfg: FeeConfig{MinTip: gwei(1)}, baseFee: gwei(10), wantTip: gwei(1), wantFeeCap: gwei(40)},
+		{name: "keeps a higher tip", cfg: FeeConfig{TipCap: gwei(3), MinTip: gwei(1)}, baseFee: gwei(10), wantTip: gwei(3), wantFeeCap: gwei(40)},
+		{name: "raises a lower tip", cfg: FeeConfig{TipCap: gwei(1), MinTip: gwei(2)}, baseFee: gwei(10), wantTip: gwei(2), wantFeeCap: gwei(40)},
+		{name: "raises the fee cap near a zero base fee", cfg: FeeConfig{MinTip: gwei(1)}, baseFee: big.NewInt(1), wantTip: gwei(1), wantFeeCap: new(big.Int).Add(gwei(1), big.NewInt(1))},
+		{name: "pinned fee cap", cfg: FeeConfig{FeeCap: gwei(12), MinTip: gwei(2)}, baseFee: gwei(10), wantTip: gwei(2), wantFeeCap: gwei(12)},
+		{name: "pinned fee cap below min tip", cfg: FeeConfig{FeeCap: gwei(1), MinTip: gwei(2)}, baseFee: gwei(10), wantErr: true},
+		{name: "negative min tip", cfg: FeeConfig{MinTip: big.NewInt(-1)}, baseFee: gwei(10), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tip, feeCap, err := tt.cfg.Caps(tt.baseFee)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Caps error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (tip.Cmp(tt.wantTip) != 0 || feeCap.Cmp(tt.wantFeeCap) != 0) {
+				t.Fatalf("Caps = %s, %s, want %s, %s", tip, feeCap, tt.wantTip, tt.wantFeeCap)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package eth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// BlockSource delivers new chain heads. *ethclient.Client implements it with a live subscription,
+// ReplaySource with a recorded header sequence.
+type BlockSource interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// ReplaySource is a BlockSource that delivers a recorded header sequence at a fixed pace, to
+// reproduce specific network conditions deterministically. Its subscription ends with a nil
+// error once every header was delivered.
+type ReplaySource struct {
+	headers  []*types.Header // The recorded headers, in delivery order.
+	interval time.Duration   // The pause before each header.
+}
+
+// NewReplaySource loads a header sequence written by a HeaderRecorder.
+//
+// Parameters:
+// - filename: The file holding one JSON-encoded header per line.
+// - interval: The pause before each header is delivered. Zero delivers them back to back.
+//
+// Returns:
+// - A pointer to a ReplaySource, or an error if the file cannot be read or decoded.
+func NewReplaySource(filename string, interval time.Duration) (*ReplaySource, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open header recording: %w", err)
+	}
+	defer file.Close()
+
+	var headers []*types.Header
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		header := new(types.Header)
+		if err := json.Unmarshal(scanner.Bytes(), header); err != nil {
+			return nil, fmt.Errorf("failed to decode header on line %d: %w", line, err)
+		}
+		headers = append(headers, header)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read header recording: %w", err)
+	}
+	return &ReplaySource{headers: headers, interval: interval}, nil
+}
+
+// Len returns the number of recorded headers.
+func (s *ReplaySource) Len() int {
+	return len(s.headers)
+}
+
+// SubscribeNewHead delivers the recorded headers to ch, pausing interval before each one.
+func (s *ReplaySource) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, header := range s.headers {
+			select {
+			case <-time.After(s.interval):
+			case <-quit:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			select {
+			case ch <- header:
+			case <-quit:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}), nil
+}
+
+// HeaderRecorder appends observed headers to a file, in the format NewReplaySource reads.
+type HeaderRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewHeaderRecorder opens filename for appending headers, creating it if needed.
+//
+// Parameters:
+// - filename: The file headers are appended to.
+//
+// Returns:
+// - A pointer to a HeaderRecorder, or an error if the file cannot be opened.
+func NewHeaderRecorder(filename string) (*HeaderRecorder, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open header recording: %w", err)
+	}
+	return &HeaderRecorder{file: file}, nil
+}
+
+// Record appends a header as one JSON line.
+func (r *HeaderRecorder) Record(header *types.Header) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	return nil
+}
+
+// Close closes the recording file.
+func (r *HeaderRecorder) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,65 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultInclusionPollInterval is how often VerifyInclusion checks for the receipt.
+const DefaultInclusionPollInterval = 4 * time.Second
+
+// ReceiptReader is implemented by clients that can report transaction receipts and the chain head.
+type ReceiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// VerifyInclusion waits for a transaction to be mined and checks that it landed within tolerance
+// blocks after its target block. It gives up once the chain is past the tolerated range without
+// a receipt, so a transaction that was never included is reported without waiting for ctx.
+//
+// Parameters:
+// - ctx: The context bounding the wait.
+// - client: The client connected to the L1 node.
+// - txHash: The hash of the transaction.
+// - targetBlock: The block the transaction was bid for.
+// - tolerance: How many blocks after the target still count as included.
+//
+// Returns:
+// - Whether the transaction landed within tolerance, the block it landed in (zero if it was not mined in range), and an error if the node cannot be queried or ctx ends first.
+func VerifyInclusion(ctx context.Context, client ReceiptReader, txHash common.Hash, targetBlock, tolerance uint64) (bool, uint64, error) {
+	ticker := time.NewTicker(DefaultInclusionPollInterval)
+	defer ticker.Stop()
+
+	lastBlock := targetBlock + tolerance
+	for {
+		// Read the head first, so a receipt mined right after is not missed
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return false, 0, fmt.Errorf("failed to get block number: %w", err)
+		}
+
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		switch {
+		case err == nil:
+			block := receipt.BlockNumber.Uint64()
+			return block >= targetBlock && block <= lastBlock, block, nil
+		case !errors.Is(err, ethereum.NotFound):
+			return false, 0, fmt.Errorf("failed to get receipt: %w", err)
+		case head > lastBlock:
+			return false, 0, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeReceiptReader reports a fixed head and the receipt of a transaction mined in block, if any.
+type fakeReceiptReader struct {
+	head       uint64
+	headErr    error
+	block      uint64 // The block the transaction was mined in, zero if it was not.
+	receiptErr error
+}
+
+func (f fakeReceiptReader) BlockNumber(context.Context) (uint64, error) {
+	return f.head, f.headErr
+}
+
+func (f fakeReceiptReader) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	if f.receiptErr != nil {
+		return nil, f.receiptErr
+	}
+	if f.block == 0 {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{BlockNumber: new(big.Int).SetUint64(f.block)}, nil
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	tests := []struct {
+		name         string
+		client       fakeReceiptReader
+		cancelled    bool
+		wantIncluded bool
+		wantBlock    uint64
+		wantErr      bool
+	}{
+		{name: "target block", client: fakeReceiptReader{head: 100, block: 100}, wantIncluded: true, wantBlock: 100},
+		{name: "last tolerated block", client: fakeReceiptReader{head: 102, block: 102}, wantIncluded: true, wantBlock: 102},
+		{name: "before the target", client: fakeReceiptReader{head: 100, block: 99}, wantBlock: 99},
+		{name: "after the tolerance", client: fakeReceiptReader{head: 110, block: 103}, wantBlock: 103},
+		{name: "not mined in range", client: fakeReceiptReader{head: 103}},
+		{name: "still pending", client: fakeReceiptReader{head: 101}, cancelled: true, wantErr: true},
+		{name: "receipt error", client: fakeReceiptReader{head: 100, receiptErr: errors.New("node down")}, wantErr: true},
+		{name: "head error", client: fakeReceiptReader{headErr: errors.New("node down")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			included, block, err := VerifyInclusion(ctx, tt.client, common.Hash{1}, 100, 2)
+			if (err != nil) != tt.wantErr || (tt.cancelled && !errors.Is(err, context.Canceled)) {
+				t.Fatalf("VerifyInclusion error = %v, want error %v", err, tt.wantErr)
+			}
+			if included != tt.wantIncluded || block != tt.wantBlock {
+				t.Fatalf("VerifyInclusion = %v, %d, want %v, %d", included, block, tt.wantIncluded, tt.wantBlock)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package eth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// kzgOnce guards the one-time loading of the KZG trusted setup.
+var kzgOnce sync.Once
+
+// InitKZG loads the KZG trusted setup used to build blob sidecars. The setup is loaded once per
+// process and shared by every later makeSidecar call, so calling InitKZG at startup moves its
+// cost out of the first bid cycle. Calling it again is a no-op.
+func InitKZG() {
+	kzgOnce.Do(func() {
+		start := time.Now()
+		// The trusted setup is loaded lazily by the first commitment computed
+		var blob kzg4844.Blob
+		if _, err := kzg4844.BlobToCommitment(&blob); err != nil {
+			logger.Warn("Failed to warm up KZG trusted setup", "err", err)
+			return
+		}
+		logger.Info("KZG trusted setup loaded", "duration", time.Since(start))
+	})
+}
@@ -0,0 +1,42 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestMakeSidecar(t *testing.T) {
+	for _, n := range []int{1, 2, 6} {
+		sidecar := makeSidecar(randBlobs(n))
+		if len(sidecar.Blobs) != n || len(sidecar.Commitments) != n || len(sidecar.Proofs) != n {
+			t.Fatalf("%d blobs: sidecar has %d blobs, %d commitments and %d proofs", n, len(sidecar.Blobs), len(sidecar.Commitments), len(sidecar.Proofs))
+		}
+		for i := range sidecar.Blobs {
+			if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
+				t.Fatalf("%d blobs: proof %d does not verify: %v", n, i, err)
+			}
+		}
+	}
+}
+
+// BenchmarkInitKZG measures InitKZG once the trusted setup is loaded, which every makeSidecar
+// call pays. It stays a no-op instead of loading the setup again.
+func BenchmarkInitKZG(b *testing.B) {
+	InitKZG()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		InitKZG()
+	}
+}
+
+// BenchmarkMakeSidecar measures building the sidecar of a one-blob transaction, the KZG work of
+// each ExecuteBlobTransaction call, with the trusted setup already loaded.
+func BenchmarkMakeSidecar(b *testing.B) {
+	InitKZG()
+	blobs := randBlobs(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		makeSidecar(blobs)
+	}
+}
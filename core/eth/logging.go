@@ -0,0 +1,16 @@
+package eth
+
+import "github.com/ethereum/go-ethereum/log"
+
+// logger is the logger used by the package. It discards everything until SetLogger is called,
+// so importing the package does not produce any output of its own.
+var logger = log.NewLogger(log.DiscardHandler())
+
+// SetLogger sets the logger used by the package. It should be called before the package is used;
+// a nil logger discards all output.
+func SetLogger(l log.Logger) {
+	if l == nil {
+		l = log.NewLogger(log.DiscardHandler())
+	}
+	logger = l
+}
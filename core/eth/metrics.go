@@ -0,0 +1,68 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// otherRelay is the relay label of endpoints that were not configured on the BundleClient.
+const otherRelay = "other"
+
+// bundleLatency is the bundle round-trip latency histogram, labelled with the relay and the
+// outcome, success or failure. It is registered with the default Prometheus registry and only
+// updated when go-ethereum metrics are enabled.
+var bundleLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "eth",
+	Subsystem: "bundle",
+	Name:      "latency_seconds",
+	Help:      "Round-trip time of bundle requests, by relay and outcome.",
+	Buckets:   prometheus.ExponentialBuckets(0.025, 2, 10), // 25ms to 12.8s
+}, []string{"relay", "outcome"})
+
+func init() {
+	prometheus.MustRegister(bundleLatency)
+}
+
+// recordBundleLatency adds the round-trip time of a bundle request to relay to the latency
+// histogram, under the success or failure outcome.
+func recordBundleLatency(relay string, latency time.Duration, success bool) {
+	if !metrics.Enabled {
+		return
+	}
+	outcome := "success"
+	if !success {
+		outcome = "failure"
+	}
+	bundleLatency.WithLabelValues(relay, outcome).Observe(latency.Seconds())
+}
+
+// bundleAccepted reports whether a relay accepted a bundle: the response has a non-error status
+// and its JSON-RPC body carries no error. Relays report rejected bundles with a JSON-RPC error,
+// often along with a 200 status.
+func bundleAccepted(statusCode int, body []byte) bool {
+	if statusCode >= http.StatusBadRequest {
+		return false
+	}
+	var resp struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+	return len(resp.Error) == 0 || bytes.Equal(resp.Error, []byte("null"))
+}
+
+// relayName derives the relay label of an endpoint: its host. The path and query are left out,
+// since they may hold credentials.
+func relayName(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return endpoint
+}
@@ -0,0 +1,106 @@
+package eth
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestBundleAccepted(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{name: "result", status: http.StatusOK, body: `{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`, want: true},
+		{name: "null error", status: http.StatusOK, body: `{"jsonrpc":"2.0","id":1,"result":null,"error":null}`, want: true},
+		{name: "json-rpc error", status: http.StatusOK, body: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle rejected"}}`},
+		{name: "http error", status: http.StatusInternalServerError, body: `{"jsonrpc":"2.0","id":1,"result":"0x01"}`},
+		{name: "not json", status: http.StatusOK, body: `rate limited`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bundleAccepted(tt.status, []byte(tt.body)); got != tt.want {
+				t.Fatalf("bundleAccepted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelayName(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "https://relay.example.org/bundle?key=secret", want: "relay.example.org"},
+		{endpoint: "http://127.0.0.1:8545", want: "127.0.0.1:8545"},
+		{endpoint: "not a url", want: "not a url"},
+	}
+	for _, tt := range tests {
+		if got := relayName(tt.endpoint); got != tt.want {
+			t.Errorf("relayName(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestBundleLatencyLabels(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	t.Cleanup(func() { metrics.Enabled = enabled })
+	bundleLatency.Reset()
+
+	var body string
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer relay.Close()
+
+	client, err := NewBundleClient(BundleClientConfig{Relays: []string{relay.URL}})
+	if err != nil {
+		t.Fatalf("NewBundleClient: %v", err)
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1)})
+
+	body = `{"jsonrpc":"2.0","id":1,"result":"0x01"}`
+	if _, err := client.sendBundle(relay.URL, []*types.Transaction{tx}, 1); err != nil {
+		t.Fatalf("sendBundle: %v", err)
+	}
+	body = `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle rejected"}}`
+	if _, err := client.sendBundle(relay.URL, []*types.Transaction{tx}, 1); err != nil {
+		t.Fatalf("sendBundle: %v", err)
+	}
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	counts := make(map[string]uint64)
+	for _, family := range families {
+		if family.GetName() != "eth_bundle_latency_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["relay"] != relayName(relay.URL) {
+				t.Errorf("relay label = %q, want %q", labels["relay"], relayName(relay.URL))
+			}
+			counts[labels["outcome"]] = m.GetHistogram().GetSampleCount()
+			if len(m.GetHistogram().GetBucket()) == 0 {
+				t.Error("histogram has no buckets")
+			}
+		}
+	}
+	if counts["success"] != 1 || counts["failure"] != 1 {
+		t.Fatalf("sample counts = %v, want one success and one failure", counts)
+	}
+}
@@ -0,0 +1,164 @@
+package eth
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// testPrivateKey is the first Hardhat development key.
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// testAccount returns the account of testPrivateKey.
+func testAccount(t *testing.T) bb.AuthAcct {
+	t.Helper()
+	authAcct, err := bb.AuthenticateAddress(testPrivateKey)
+	if err != nil {
+		t.Fatalf("AuthenticateAddress: %v", err)
+	}
+	return authAcct
+}
+
+// fakeNode is an in-process L1 node serving the calls transactions are built with.
+type fakeNode struct {
+	mu        sync.Mutex
+	head      *types.Header // The latest header.
+	nonce     uint64        // The pending nonce of every account.
+	networkID int64         // The network ID, which is also the chain ID.
+	rewards   []*big.Int    // The tips returned by eth_feeHistory, one per block.
+	nextFee   *big.Int      // The base fee eth_feeHistory predicts for the next block.
+	receipts  map[common.Hash]*types.Receipt
+	sent      []*types.Transaction // The transactions sent with eth_sendRawTransaction.
+	sendErr   error                // The error eth_sendRawTransaction fails with.
+}
+
+// newFakeNode returns a Holesky node at block 100 with a 10 gwei base fee and no blob gas used.
+func newFakeNode() *fakeNode {
+	zero := uint64(0)
+	return &fakeNode{
+		head: &types.Header{
+			Number:        big.NewInt(100),
+			BaseFee:       big.NewInt(10_000_000_000),
+			Difficulty:    big.NewInt(0),
+			Time:          1_700_000_000,
+			ExcessBlobGas: &zero,
+			BlobGasUsed:   &zero,
+		},
+		networkID: 17000,
+		receipts:  make(map[common.Hash]*types.Receipt),
+	}
+}
+
+// dial serves the node in-process and returns a client connected to it.
+func (n *fakeNode) dial(t *testing.T) *ethclient.Client {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &fakeEthAPI{n}); err != nil {
+		t.Fatalf("register eth: %v", err)
+	}
+	if err := server.RegisterName("net", &fakeNetAPI{n}); err != nil {
+		t.Fatalf("register net: %v", err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return client
+}
+
+// sentTxs returns the transactions sent to the node so far.
+func (n *fakeNode) sentTxs() []*types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*types.Transaction(nil), n.sent...)
+}
+
+// fakeEthAPI serves the eth namespace of a fakeNode.
+type fakeEthAPI struct {
+	node *fakeNode
+}
+
+func (api *fakeEthAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(api.node.networkID))
+}
+
+func (api *fakeEthAPI) BlockNumber() hexutil.Uint64 {
+	api.node.mu.Lock()
+	defer api.node.mu.Unlock()
+	return hexutil.Uint64(api.node.head.Number.Uint64())
+}
+
+func (api *fakeEthAPI) GetBlockByNumber(number rpc.BlockNumber, _ bool) (*types.Header, error) {
+	api.node.mu.Lock()
+	defer api.node.mu.Unlock()
+	if number >= 0 && uint64(number) != api.node.head.Number.Uint64() {
+		return nil, nil
+	}
+	return api.node.head, nil
+}
+
+func (api *fakeEthAPI) GetTransactionCount(common.Address, rpc.BlockNumberOrHash) hexutil.Uint64 {
+	api.node.mu.Lock()
+	defer api.node.mu.Unlock()
+	return hexutil.Uint64(api.node.nonce)
+}
+
+// feeHistoryResult is the eth_feeHistory response.
+type feeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+func (api *fakeEthAPI) FeeHistory(_ hexutil.Uint64, _ rpc.BlockNumber, _ []float64) (*feeHistoryResult, error) {
+	api.node.mu.Lock()
+	defer api.node.mu.Unlock()
+	result := &feeHistoryResult{OldestBlock: (*hexutil.Big)(big.NewInt(0))}
+	for _, tip := range api.node.rewards {
+		result.Reward = append(result.Reward, []*hexutil.Big{(*hexutil.Big)(tip)})
+		result.BaseFee = append(result.BaseFee, (*hexutil.Big)(api.node.head.BaseFee))
+		result.GasUsedRatio = append(result.GasUsedRatio, 0.5)
+	}
+	if api.node.nextFee != nil {
+		result.BaseFee = append(result.BaseFee, (*hexutil.Big)(api.node.nextFee))
+	}
+	return result, nil
+}
+
+func (api *fakeEthAPI) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	api.node.mu.Lock()
+	defer api.node.mu.Unlock()
+	if api.node.sendErr != nil {
+		return common.Hash{}, api.node.sendErr
+	}
+	api.node.sent = append(api.node.sent, tx)
+	return tx.Hash(), nil
+}
+
+func (api *fakeEthAPI) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	api.node.mu.Lock()
+	defer api.node.mu.Unlock()
+	return api.node.receipts[hash], nil
+}
+
+// fakeNetAPI serves the net namespace of a fakeNode.
+type fakeNetAPI struct {
+	node *fakeNode
+}
+
+func (api *fakeNetAPI) Version() string {
+	return big.NewInt(api.node.networkID).String()
+}
@@ -0,0 +1,111 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceReader is implemented by clients that can report an account's pending nonce.
+type NonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out contiguous nonces for an account, so several transactions can be built
+// before any of them reaches the node's pending pool. Reconcile compares the local nonce with
+// the node's pending nonce before each batch and recovers from gaps left by transactions that
+// were dropped or never included.
+type NonceManager struct {
+	mu           sync.Mutex
+	address      common.Address // The account the nonces are for.
+	next         uint64         // The nonce the next transaction will use.
+	synced       bool           // Whether next was read from the node at least once.
+	gapTolerance uint64         // Consecutive checks a gap may persist before resyncing.
+	gapChecks    uint64         // Consecutive checks that found a gap so far.
+}
+
+// NewNonceManager creates a NonceManager for the account at address. Sync or Reconcile must be
+// called before the first nonce is taken.
+//
+// Parameters:
+// - address: The account the nonces are for.
+// - gapTolerance: How many consecutive Reconcile calls may find a nonce gap before resyncing. Zero resyncs on the first gap.
+//
+// Returns:
+// - A pointer to a NonceManager.
+func NewNonceManager(address common.Address, gapTolerance uint64) *NonceManager {
+	return &NonceManager{address: address, gapTolerance: gapTolerance}
+}
+
+// Sync resets the next nonce to the account's pending nonce on the node.
+//
+// Parameters:
+// - ctx: The context for the call.
+// - client: The client connected to the node.
+//
+// Returns:
+// - An error if the pending nonce cannot be read.
+func (m *NonceManager) Sync(ctx context.Context, client NonceReader) error {
+	nonce, err := client.PendingNonceAt(ctx, m.address)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next = nonce
+	m.synced = true
+	m.gapChecks = 0
+	return nil
+}
+
+// Reconcile compares the local nonce with the account's pending nonce on the node. A pending
+// nonce at or above the local one is adopted. A pending nonce below it means a gap: transactions
+// with the nonces in between were dropped or never included, and later ones can never be mined.
+// Once the gap has persisted for more checks than the gap tolerance, the local nonce is resynced
+// down to the pending nonce so the missing nonces are reused.
+//
+// Parameters:
+// - ctx: The context for the call.
+// - client: The client connected to the node.
+//
+// Returns:
+// - An error if the pending nonce cannot be read.
+func (m *NonceManager) Reconcile(ctx context.Context, client NonceReader) error {
+	pending, err := client.PendingNonceAt(ctx, m.address)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced || pending >= m.next {
+		m.next = pending
+		m.synced = true
+		m.gapChecks = 0
+		return nil
+	}
+
+	m.gapChecks++
+	if m.gapChecks <= m.gapTolerance {
+		logger.Debug("Local nonce ahead of pending nonce", "local", m.next, "pending", pending, "checks", m.gapChecks)
+		return nil
+	}
+
+	logger.Info("Nonce gap detected, resyncing to the pending nonce", "local", m.next, "pending", pending, "missing", m.next-pending)
+	m.next = pending
+	m.gapChecks = 0
+	return nil
+}
+
+// Next returns the next nonce and advances past it.
+func (m *NonceManager) Next() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nonce := m.next
+	m.next++
+	return nonce
+}
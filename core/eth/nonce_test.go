@@ -0,0 +1,120 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeNonceReader reports a settable pending nonce.
+type fakeNonceReader struct {
+	pending uint64
+	err     error
+}
+
+func (f *fakeNonceReader) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return f.pending, f.err
+}
+
+func TestNonceManagerReconcile(t *testing.T) {
+	type step struct {
+		pending uint64 // The node's pending nonce when reconciling.
+		take    int    // Nonces taken after reconciling.
+		want    uint64 // The next nonce after reconciling.
+	}
+	tests := []struct {
+		name      string
+		tolerance uint64
+		steps     []step
+	}{
+		{
+			name:  "first sync",
+			steps: []step{{pending: 5, want: 5}},
+		},
+		{
+			name:  "node catches up",
+			steps: []step{{pending: 5, take: 2, want: 5}, {pending: 7, want: 7}},
+		},
+		{
+			name:  "node ahead",
+			steps: []step{{pending: 5, take: 1, want: 5}, {pending: 9, want: 9}},
+		},
+		{
+			name:  "gap resynced at once",
+			steps: []step{{pending: 5, take: 3, want: 5}, {pending: 6, want: 6}},
+		},
+		{
+			name:      "gap tolerated",
+			tolerance: 2,
+			steps: []step{
+				{pending: 5, take: 3, want: 5},
+				{pending: 6, want: 8},
+				{pending: 6, want: 8},
+				{pending: 6, want: 6},
+			},
+		},
+		{
+			name:      "gap closes before the tolerance",
+			tolerance: 1,
+			steps: []step{
+				{pending: 5, take: 3, want: 5},
+				{pending: 6, want: 8},
+				{pending: 8, take: 1, want: 8},
+				{pending: 8, want: 9}, // The count of gap checks restarted.
+				{pending: 8, want: 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewNonceManager(common.Address{1}, tt.tolerance)
+			node := &fakeNonceReader{}
+			for i, s := range tt.steps {
+				node.pending = s.pending
+				if err := m.Reconcile(context.Background(), node); err != nil {
+					t.Fatalf("step %d: Reconcile: %v", i, err)
+				}
+				m.mu.Lock()
+				got := m.next
+				m.mu.Unlock()
+				if got != s.want {
+					t.Fatalf("step %d: next nonce = %d, want %d", i, got, s.want)
+				}
+				for j := 0; j < s.take; j++ {
+					m.Next()
+				}
+			}
+		})
+	}
+}
+
+func TestNonceManagerSync(t *testing.T) {
+	m := NewNonceManager(common.Address{1}, 5)
+	node := &fakeNonceReader{pending: 4}
+	if err := m.Sync(context.Background(), node); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	for want := uint64(4); want < 7; want++ {
+		if got := m.Next(); got != want {
+			t.Fatalf("Next = %d, want %d", got, want)
+		}
+	}
+
+	// Sync moves the nonce down regardless of the gap tolerance
+	if err := m.Sync(context.Background(), node); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := m.Next(); got != 4 {
+		t.Fatalf("Next after Sync = %d, want 4", got)
+	}
+
+	node.err = errors.New("connection refused")
+	if err := m.Sync(context.Background(), node); err == nil {
+		t.Fatal("Sync succeeded with a failing node")
+	}
+	if err := m.Reconcile(context.Background(), node); err == nil {
+		t.Fatal("Reconcile succeeded with a failing node")
+	}
+}
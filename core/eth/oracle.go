@@ -0,0 +1,119 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultFeeHistoryBlocks and DefaultFeeHistoryPercentile are used when a FeeHistoryOracle leaves
+// them unset.
+const (
+	DefaultFeeHistoryBlocks     = 20
+	DefaultFeeHistoryPercentile = 50.0
+)
+
+// FeeHistoryReader is implemented by clients that serve eth_feeHistory.
+type FeeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// FeeSuggestion is the base fee and tip a FeeOracle suggests for a transaction, in wei.
+type FeeSuggestion struct {
+	BaseFee *big.Int // The base fee to size the fee cap on.
+	Tip     *big.Int // The least tip per gas to pay, nil for no suggestion.
+}
+
+// FeeOracle suggests the fees of the transactions built by this package.
+type FeeOracle interface {
+	// SuggestFees returns the suggested fees for a transaction built on header. The client is
+	// the one the transaction is built with, for oracles that query the node.
+	SuggestFees(ctx context.Context, client FeeHistoryReader, header *types.Header) (FeeSuggestion, error)
+}
+
+// HeaderFeeOracle suggests the base fee of the latest header and no tip. It is the default oracle.
+type HeaderFeeOracle struct{}
+
+// SuggestFees returns the header's base fee.
+func (HeaderFeeOracle) SuggestFees(_ context.Context, _ FeeHistoryReader, header *types.Header) (FeeSuggestion, error) {
+	return FeeSuggestion{BaseFee: header.BaseFee}, nil
+}
+
+// FeeHistoryOracle suggests the tip paid by recent blocks, read with eth_feeHistory. The tip is
+// the median across Blocks blocks of each block's Percentile-th tip, and the base fee is the one
+// the node predicts for the next block.
+type FeeHistoryOracle struct {
+	Blocks     uint64  // How many blocks up to the header to sample. Zero uses DefaultFeeHistoryBlocks.
+	Percentile float64 // The tip percentile to read in each block. Zero uses DefaultFeeHistoryPercentile.
+}
+
+// SuggestFees reads the fee history up to the header and derives the tip from it.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client connected to the L1 node.
+// - header: The header the transaction is built on.
+//
+// Returns:
+// - The suggested fees, or an error if the history cannot be read or holds no rewards.
+func (o FeeHistoryOracle) SuggestFees(ctx context.Context, client FeeHistoryReader, header *types.Header) (FeeSuggestion, error) {
+	blocks := o.Blocks
+	if blocks == 0 {
+		blocks = DefaultFeeHistoryBlocks
+	}
+	percentile := o.Percentile
+	if percentile == 0 {
+		percentile = DefaultFeeHistoryPercentile
+	}
+	if percentile < 0 || percentile > 100 {
+		return FeeSuggestion{}, fmt.Errorf("fee history percentile must be between 0 and 100, got %v", percentile)
+	}
+
+	history, err := client.FeeHistory(ctx, blocks, header.Number, []float64{percentile})
+	if err != nil {
+		return FeeSuggestion{}, fmt.Errorf("failed to read fee history: %w", err)
+	}
+
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for _, rewards := range history.Reward {
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	if len(tips) == 0 {
+		return FeeSuggestion{}, fmt.Errorf("fee history up to block %s holds no rewards", header.Number)
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+
+	// The history holds one more base fee than blocks, the one predicted for the next block
+	baseFee := header.BaseFee
+	if n := len(history.BaseFee); n > 0 && history.BaseFee[n-1] != nil {
+		baseFee = history.BaseFee[n-1]
+	}
+
+	return FeeSuggestion{BaseFee: baseFee, Tip: new(big.Int).Set(tips[len(tips)/2])}, nil
+}
+
+// caps returns the tip cap and fee cap of a transaction built on header, from the fees the
+// oracle suggests and the fee config. A suggested tip raises the tip like a MinTip, unless the
+// config pins the tip.
+func (o TxOptions) caps(client FeeHistoryReader, header *types.Header) (*big.Int, *big.Int, error) {
+	oracle := o.Oracle
+	if oracle == nil {
+		oracle = HeaderFeeOracle{}
+	}
+	suggestion, err := oracle.SuggestFees(context.Background(), client, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fees := o.Fees
+	if fees.TipCap == nil && suggestion.Tip != nil && (fees.MinTip == nil || fees.MinTip.Cmp(suggestion.Tip) < 0) {
+		fees.MinTip = suggestion.Tip
+	}
+	return fees.Caps(suggestion.BaseFee)
+}
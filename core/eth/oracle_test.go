@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeFeeHistory serves a fixed fee history and records the request.
+type fakeFeeHistory struct {
+	history     *ethereum.FeeHistory
+	err         error
+	blocks      uint64
+	lastBlock   *big.Int
+	percentiles []float64
+}
+
+func (f *fakeFeeHistory) FeeHistory(_ context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	f.blocks, f.lastBlock, f.percentiles = blockCount, lastBlock, rewardPercentiles
+	return f.history, f.err
+}
+
+// feeHistory builds a history with one reward per tip and the given next base fee.
+func feeHistory(nextBaseFee int64, tips ...int64) *ethereum.FeeHistory {
+	history := &ethereum.FeeHistory{}
+	for _, tip := range tips {
+		history.Reward = append(history.Reward, []*big.Int{big.NewInt(tip)})
+		history.BaseFee = append(history.BaseFee, big.NewInt(10))
+	}
+	if nextBaseFee > 0 {
+		history.BaseFee = append(history.BaseFee, big.NewInt(nextBaseFee))
+	}
+	return history
+}
+
+func TestFeeHistoryOracle(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(10)}
+	tests := []struct {
+		name           string
+		oracle         FeeHistoryOracle
+		history        *ethereum.FeeHistory
+		err            error
+		wantBlocks     uint64
+		wantPercentile float64
+		wantBaseFee    int64
+		wantTip        int64
+		wantErr        bool
+	}{
+		{name: "defaults", history: feeHistory(12, 3, 1, 2), wantBlocks: DefaultFeeHistoryBlocks, wantPercentile: DefaultFeeHistoryPercentile, wantBaseFee: 12, wantTip: 2},
+		{name: "configured", oracle: FeeHistoryOracle{Blocks: 5, Percentile: 90}, history: feeHistory(12, 7), wantBlocks: 5, wantPercentile: 90, wantBaseFee: 12, wantTip: 7},
+		{name: "even count takes the upper median", history: feeHistory(12, 4, 1, 3, 2), wantBlocks: DefaultFeeHistoryBlocks, wantPercentile: DefaultFeeHistoryPercentile, wantBaseFee: 12, wantTip: 3},
+		{name: "no predicted base fee", history: &ethereum.FeeHistory{Reward: [][]*big.Int{{big.NewInt(5)}}}, wantBlocks: DefaultFeeHistoryBlocks, wantPercentile: DefaultFeeHistoryPercentile, wantBaseFee: 10, wantTip: 5},
+		{name: "empty rewards skipped", history: &ethereum.FeeHistory{Reward: [][]*big.Int{{}, {big.NewInt(6)}, {nil}}}, wantBlocks: DefaultFeeHistoryBlocks, wantPercentile: DefaultFeeHistoryPercentile, wantBaseFee: 10, wantTip: 6},
+		{name: "no rewards", history: feeHistory(12), wantErr: true},
+		{name: "percentile above 100", oracle: FeeHistoryOracle{Percentile: 101}, history: feeHistory(12, 1), wantErr: true},
+		{name: "negative percentile", oracle: FeeHistoryOracle{Percentile: -1}, history: feeHistory(12, 1), wantErr: true},
+		{name: "node error", err: errors.New("method not found"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeFeeHistory{history: tt.history, err: tt.err}
+			got, err := tt.oracle.SuggestFees(context.Background(), client, header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SuggestFees error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if client.blocks != tt.wantBlocks || client.lastBlock.Cmp(header.Number) != 0 || len(client.percentiles) != 1 || client.percentiles[0] != tt.wantPercentile {
+				t.Fatalf("requested %d blocks up to %s at %v, want %d up to %s at %v", client.blocks, client.lastBlock, client.percentiles, tt.wantBlocks, header.Number, tt.wantPercentile)
+			}
+			if got.BaseFee.Int64() != tt.wantBaseFee || got.Tip.Int64() != tt.wantTip {
+				t.Fatalf("SuggestFees = %s, %s, want %d, %d", got.BaseFee, got.Tip, tt.wantBaseFee, tt.wantTip)
+			}
+		})
+	}
+}
+
+func TestTxOptionsCaps(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	header := &types.Header{Number: big.NewInt(100), BaseFee: gwei(10)}
+	client := &fakeFeeHistory{history: &ethereum.FeeHistory{Reward: [][]*big.Int{{gwei(3)}}, BaseFee: []*big.Int{gwei(10), gwei(11)}}}
+	tests := []struct {
+		name                string
+		opts                TxOptions
+		wantTip, wantFeeCap *big.Int
+	}{
+		{name: "header oracle", opts: TxOptions{}, wantTip: gwei(0), wantFeeCap: gwei(40)},
+		{name: "fee history oracle", opts: TxOptions{Oracle: FeeHistoryOracle{}}, wantTip: gwei(3), wantFeeCap: gwei(44)},
+		{name: "higher min tip", opts: TxOptions{Oracle: FeeHistoryOracle{}, Fees: FeeConfig{MinTip: gwei(5)}}, wantTip: gwei(5), wantFeeCap: gwei(44)},
+		{name: "lower min tip", opts: TxOptions{Oracle: FeeHistoryOracle{}, Fees: FeeConfig{MinTip: gwei(1)}}, wantTip: gwei(3), wantFeeCap: gwei(44)},
+		{name: "pinned tip", opts: TxOptions{Oracle: FeeHistoryOracle{}, Fees: FeeConfig{TipCap: gwei(1)}}, wantTip: gwei(1), wantFeeCap: gwei(44)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tip, feeCap, err := tt.opts.caps(client, header)
+			if err != nil {
+				t.Fatalf("caps: %v", err)
+			}
+			if tip.Cmp(tt.wantTip) != 0 || feeCap.Cmp(tt.wantFeeCap) != 0 {
+				t.Fatalf("caps = %s, %s, want %s, %s", tip, feeCap, tt.wantTip, tt.wantFeeCap)
+			}
+		})
+	}
+}
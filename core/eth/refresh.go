@@ -0,0 +1,116 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/holiman/uint256"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// RefreshTx rebuilds a transaction with fees derived from the latest header, keeping its nonce,
+// recipient, value, gas, data and blobs, and signs it again. It is meant for a bundle that missed
+// its target block, whose fees may be stale for a later one. Dynamic fee and blob transactions
+// are supported.
+//
+// Parameters:
+// - client: The client connected to the L1 node.
+// - authAcct: The account that signed the original transaction.
+// - original: The transaction to refresh.
+// - opts: The fee settings; the nonce in opts is ignored.
+//
+// Returns:
+// - The refreshed transaction, or an error if it cannot be rebuilt or signed.
+func RefreshTx(client *ethclient.Client, authAcct bb.AuthAcct, original *types.Transaction, opts TxOptions) (*types.Transaction, error) {
+	header, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	tipCap, feeCap, err := opts.caps(client, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *types.Transaction
+	switch original.Type() {
+	case types.DynamicFeeTxType:
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:    original.ChainId(),
+			Nonce:      original.Nonce(),
+			To:         original.To(),
+			Value:      original.Value(),
+			Gas:        original.Gas(),
+			GasFeeCap:  feeCap,
+			GasTipCap:  tipCap,
+			Data:       original.Data(),
+			AccessList: original.AccessList(),
+		})
+	case types.BlobTxType:
+		if header.ExcessBlobGas == nil || header.BlobGasUsed == nil {
+			return nil, fmt.Errorf("block %s has no blob gas fields", header.Number)
+		}
+		// Size the blob fee cap like ExecuteBlobTransaction, on the next block's blob fee
+		blobFeeCap := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*header.ExcessBlobGas, *header.BlobGasUsed))
+		blobFeeCap.Add(blobFeeCap, big.NewInt(1))
+		blobFeeCap.Mul(blobFeeCap, big.NewInt(110)).Div(blobFeeCap, big.NewInt(100))
+
+		tx = types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(original.ChainId()),
+			Nonce:      original.Nonce(),
+			To:         *original.To(),
+			Value:      uint256.MustFromBig(original.Value()),
+			Gas:        original.Gas(),
+			GasFeeCap:  uint256.MustFromBig(feeCap),
+			GasTipCap:  uint256.MustFromBig(tipCap),
+			Data:       original.Data(),
+			AccessList: original.AccessList(),
+			BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+			BlobHashes: original.BlobHashes(),
+			Sidecar:    original.BlobTxSidecar(),
+		})
+	default:
+		return nil, fmt.Errorf("cannot refresh transaction of type %d", original.Type())
+	}
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(original.ChainId()), authAcct.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign refreshed transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// RefreshAndResend refreshes the fees of a transaction with RefreshTx and submits it as a bundle
+// for newBlock with the default bundle client, see BundleClient.RefreshAndResend.
+func RefreshAndResend(RPCURL string, client *ethclient.Client, authAcct bb.AuthAcct, original *types.Transaction, newBlock uint64, opts TxOptions) (*types.Transaction, string, error) {
+	return defaultBundleClient.RefreshAndResend(RPCURL, client, authAcct, original, newBlock, opts)
+}
+
+// RefreshAndResend rebuilds a transaction whose bundle missed its target block with fees for the
+// latest header, then submits it as a bundle for newBlock instead of reusing the stale one.
+//
+// Parameters:
+// - RPCURL: The URL of the bundle endpoint.
+// - client: The client connected to the L1 node.
+// - authAcct: The account that signed the original transaction.
+// - original: The transaction of the missed bundle.
+// - newBlock: The block to target.
+// - opts: The fee settings; the nonce in opts is ignored.
+//
+// Returns:
+// - The refreshed transaction, the bundle response, and an error if the refresh or the submission fails.
+func (c *BundleClient) RefreshAndResend(RPCURL string, client *ethclient.Client, authAcct bb.AuthAcct, original *types.Transaction, newBlock uint64, opts TxOptions) (*types.Transaction, string, error) {
+	refreshed, err := RefreshTx(client, authAcct, original, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	response, err := c.SendBundle(RPCURL, refreshed, newBlock)
+	if err != nil {
+		return refreshed, "", err
+	}
+	logger.Info("Resent bundle with refreshed fees", "block", newBlock, "oldTxHash", original.Hash(), "txHash", refreshed.Hash(), "gasFeeCap", refreshed.GasFeeCap(), "gasTipCap", refreshed.GasTipCap())
+	return refreshed, response, nil
+}
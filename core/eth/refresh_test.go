@@ -0,0 +1,162 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// staleTransfer returns a dynamic fee transaction signed by authAcct with fees far below the fake
+// node's.
+func staleTransfer(t *testing.T, authAcct bb.AuthAcct) *types.Transaction {
+	t.Helper()
+	to := common.Address{0xaa}
+	tx, err := types.SignNewTx(authAcct.PrivateKey, types.LatestSignerForChainID(big.NewInt(17000)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(17000), Nonce: 5, To: &to, Value: big.NewInt(1_000), Gas: 30_000, GasFeeCap: big.NewInt(2), GasTipCap: big.NewInt(1), Data: []byte{0xde, 0xad},
+	})
+	if err != nil {
+		t.Fatalf("SignNewTx: %v", err)
+	}
+	return tx
+}
+
+// staleBlobTx returns a blob transaction with one blob built by authAcct against node.
+func staleBlobTx(t *testing.T, authAcct bb.AuthAcct, node *fakeNode) *types.Transaction {
+	t.Helper()
+	InitKZG()
+	raw, err := BuildSignedTx(node.dial(t), authAcct, 1, big.NewInt(1_000), TxOptions{Fees: FeeConfig{FeeCap: big.NewInt(2), TipCap: big.NewInt(1)}})
+	if err != nil {
+		t.Fatalf("BuildSignedTx: %v", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	return tx
+}
+
+func TestRefreshTx(t *testing.T) {
+	authAcct := testAccount(t)
+	tests := []struct {
+		name    string
+		tx      func(node *fakeNode) *types.Transaction
+		setup   func(node *fakeNode)
+		opts    TxOptions
+		wantErr bool
+	}{
+		{name: "transfer", tx: func(*fakeNode) *types.Transaction { return staleTransfer(t, authAcct) }},
+		{name: "pinned fees", tx: func(*fakeNode) *types.Transaction { return staleTransfer(t, authAcct) }, opts: TxOptions{Fees: FeeConfig{FeeCap: big.NewInt(7e9), TipCap: big.NewInt(1e9)}}},
+		{name: "blob transaction", tx: func(node *fakeNode) *types.Transaction { return staleBlobTx(t, authAcct, node) }},
+		{
+			name:    "blob transaction without blob gas fields",
+			tx:      func(node *fakeNode) *types.Transaction { return staleBlobTx(t, authAcct, node) },
+			setup:   func(node *fakeNode) { node.head.ExcessBlobGas, node.head.BlobGasUsed = nil, nil },
+			wantErr: true,
+		},
+		{
+			name: "legacy transaction",
+			tx: func(*fakeNode) *types.Transaction {
+				return types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1)})
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newFakeNode()
+			original := tt.tx(node)
+			if tt.setup != nil {
+				tt.setup(node)
+			}
+			client := node.dial(t)
+
+			refreshed, err := RefreshTx(client, authAcct, original, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RefreshTx error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			wantTip, wantFeeCap, err := tt.opts.caps(client, node.head)
+			if err != nil {
+				t.Fatalf("caps: %v", err)
+			}
+			if refreshed.GasTipCap().Cmp(wantTip) != 0 || refreshed.GasFeeCap().Cmp(wantFeeCap) != 0 {
+				t.Fatalf("fees = %s/%s, want %s/%s", refreshed.GasTipCap(), refreshed.GasFeeCap(), wantTip, wantFeeCap)
+			}
+			if refreshed.Type() != original.Type() || refreshed.Nonce() != original.Nonce() || *refreshed.To() != *original.To() ||
+				refreshed.Value().Cmp(original.Value()) != 0 || refreshed.Gas() != original.Gas() || !bytes.Equal(refreshed.Data(), original.Data()) {
+				t.Fatal("refreshed transaction does not keep the type, nonce, recipient, value, gas and data of the original")
+			}
+			if original.Type() == types.BlobTxType {
+				// No blob gas was used, so the blob fee is the minimum of 1 wei plus the margins
+				if refreshed.BlobGasFeeCap().Cmp(big.NewInt(2)) != 0 {
+					t.Fatalf("blob fee cap = %s, want 2", refreshed.BlobGasFeeCap())
+				}
+				if len(refreshed.BlobHashes()) != 1 || refreshed.BlobHashes()[0] != original.BlobHashes()[0] || refreshed.BlobTxSidecar() == nil {
+					t.Fatal("refreshed blob transaction does not keep the blobs of the original")
+				}
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(17000)), refreshed)
+			if err != nil || sender != authAcct.Address {
+				t.Fatalf("sender = %s, %v, want %s", sender, err, authAcct.Address)
+			}
+		})
+	}
+}
+
+func TestRefreshAndResend(t *testing.T) {
+	authAcct := testAccount(t)
+	var payload FlashbotsPayload
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("malformed bundle: %v", err)
+		}
+		io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`)
+	}))
+	defer relay.Close()
+
+	node := newFakeNode()
+	original := staleTransfer(t, authAcct)
+	refreshed, response, err := RefreshAndResend(relay.URL, node.dial(t), authAcct, original, 105, TxOptions{})
+	if err != nil {
+		t.Fatalf("RefreshAndResend: %v", err)
+	}
+	if refreshed.Hash() == original.Hash() || refreshed.Nonce() != original.Nonce() {
+		t.Fatal("RefreshAndResend did not refresh the transaction")
+	}
+	if response == "" {
+		t.Fatal("RefreshAndResend returned no response")
+	}
+
+	// The bundle targets the new block with the refreshed transaction
+	if len(payload.Params) != 1 || payload.Params[0]["blockNumber"] != "0x69" {
+		t.Fatalf("bundle params = %v, want block 0x69", payload.Params)
+	}
+	txs, _ := payload.Params[0]["txs"].([]interface{})
+	if len(txs) != 1 || crypto.Keccak256Hash(hexutil.MustDecode(txs[0].(string))) != refreshed.Hash() {
+		t.Fatalf("bundle txs = %v, want the refreshed transaction", txs)
+	}
+
+	// A failed refresh sends nothing
+	payload = FlashbotsPayload{}
+	legacy := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1)})
+	if _, _, err := RefreshAndResend(relay.URL, node.dial(t), authAcct, legacy, 105, TxOptions{}); err == nil {
+		t.Fatal("RefreshAndResend refreshed a legacy transaction")
+	}
+	if payload.Params != nil {
+		t.Fatal("RefreshAndResend sent a bundle after a failed refresh")
+	}
+}
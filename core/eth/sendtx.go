@@ -8,48 +8,97 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
 	"golang.org/x/exp/rand"
 )
 
+// ErrInvalidValue is returned when a transfer value is nil or negative.
+var ErrInvalidValue = errors.New("transfer value must be non-nil and non-negative")
+
+// ErrZeroRecipient is returned when a configured transaction recipient is the zero address.
+var ErrZeroRecipient = errors.New("transaction recipient must not be the zero address")
+
+// ErrInvalidChainID is returned when a configured chain ID is not positive.
+var ErrInvalidChainID = errors.New("chain ID must be positive")
+
+// ErrNoBlobs is returned when a blob transaction would carry no blobs.
+var ErrNoBlobs = errors.New("blob transaction must carry at least one blob")
+
+// ErrTooManyBlobs is returned when a blob transaction would carry more blobs than allowed.
+var ErrTooManyBlobs = errors.New("too many blobs for one transaction")
+
+// MaxBlobsPerTransaction is the most blobs a single transaction can carry under Cancun, which is
+// bounded by the maximum blob gas per block.
+const MaxBlobsPerTransaction = params.MaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob
+
+// PragueMaxBlobsPerTransaction is the most blobs a single transaction can carry under Prague,
+// whose EIP-7691 raises the maximum blob gas per block to 9 blobs.
+const PragueMaxBlobsPerTransaction = 9
+
+// ValidateBlobCount checks that numBlobs is between 1 and MaxBlobsPerTransaction.
+func ValidateBlobCount(numBlobs int) error {
+	return ValidateBlobCountMax(numBlobs, MaxBlobsPerTransaction)
+}
+
+// ValidateBlobCountMax checks that numBlobs is between 1 and maxBlobs, returning ErrNoBlobs or
+// ErrTooManyBlobs otherwise.
+func ValidateBlobCountMax(numBlobs, maxBlobs int) error {
+	if numBlobs < 1 {
+		return fmt.Errorf("%w, got %d", ErrNoBlobs, numBlobs)
+	}
+	if numBlobs > maxBlobs {
+		return fmt.Errorf("%w: %d exceeds the maximum of %d", ErrTooManyBlobs, numBlobs, maxBlobs)
+	}
+	return nil
+}
+
+// SelfETHTransfer builds and signs an EIP-1559 transfer of value wei from the account to itself,
+// targeting the block offset blocks after the current head. The value must not be nil or
+// negative; a zero value is allowed and produces a nonce-burning transaction that can be used
+// to cancel a pending transaction with the same nonce. The fees follow opts.
+func SelfETHTransfer(client *ethclient.Client, authAcct bb.AuthAcct, value *big.Int, offset uint64, opts TxOptions) (*types.Transaction, uint64, error) {
+	// Validate the transfer value
+	if value == nil || value.Sign() < 0 {
+		return nil, 0, ErrInvalidValue
+	}
 
-func SelfETHTransfer(client *ethclient.Client, authAcct bb.AuthAcct, value *big.Int, offset uint64) (*types.Transaction, uint64, error) {
 	// Get the account's nonce
-	nonce, err := client.PendingNonceAt(context.Background(), authAcct.Address)
+	nonce, err := txNonce(client, authAcct, opts)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get the current base fee per gas from the latest block header
+	// Get the latest block header, which the fees are based on
 	header, err := client.HeaderByNumber(context.Background(), nil)
 	if err != nil {
 		return nil, 0, err
 	}
-	baseFee := header.BaseFee
-
-	
 	blockNumber := header.Number.Uint64()
 
-	// Set the max priority fee per gas to be 2 times the base fee
-	maxPriorityFee := new(big.Int).Mul(baseFee, big.NewInt(2))
-
-	// Set the max fee per gas to be 2 times the max priority fee
-	maxFeePerGas := new(big.Int).Mul(maxPriorityFee, big.NewInt(2))
+	// The fees are pinned by the config or derived from the oracle's base fee through the
+	// multipliers. By default the priority fee only sizes the fee cap: the transaction tips
+	// nothing, providers are paid through the preconfirmation bid.
+	tipCap, maxFeePerGas, err := opts.caps(client, header)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// Get the chain ID (this does not work with the Titan RPC)
-	chainID, err := client.NetworkID(context.Background())
+	// Get the chain ID (this does not work with the Titan RPC, set opts.ChainID for it)
+	chainID, err := SigningChainID(context.Background(), client, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -61,14 +110,14 @@ func SelfETHTransfer(client *ethclient.Client, authAcct bb.AuthAcct, value *big.
 		Value:     value,
 		Gas:       500_000,
 		GasFeeCap: maxFeePerGas,
-		GasTipCap: big.NewInt((0)),
+		GasTipCap: tipCap,
 	})
 
 	// Sign the transaction with the authenticated account's private key
 	signer := types.LatestSignerForChainID(chainID)
 	signedTx, err := types.SignTx(tx, signer, authAcct.PrivateKey)
 	if err != nil {
-		log.Error("Failed to sign transaction", "error", err)
+		logger.Error("Failed to sign transaction", "error", err)
 		return nil, 0, err
 	}
 
@@ -76,8 +125,18 @@ func SelfETHTransfer(client *ethclient.Client, authAcct bb.AuthAcct, value *big.
 
 }
 
+// ExecuteBlobTransaction builds and signs a blob transaction carrying numBlobs random blobs,
+// targeting the block offset blocks after the current head. The blob count must be between 1 and
+// opts.MaxBlobs, or MaxBlobsPerTransaction if that is unset. The transaction is sent to opts.BlobRecipient, or else to the account
+// itself. The fees follow opts.
+func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numBlobs int, offset uint64, opts TxOptions) (*types.Transaction, uint64, error) {
+	if err := ValidateBlobCountMax(numBlobs, opts.maxBlobs()); err != nil {
+		return nil, 0, err
+	}
+	if opts.BlobRecipient != nil && *opts.BlobRecipient == (common.Address{}) {
+		return nil, 0, ErrZeroRecipient
+	}
 
-func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numBlobs int, offset uint64) (*types.Transaction, uint64, error) {
 	var (
 		gasLimit    = uint64(500_000)
 		blockNumber uint64
@@ -91,8 +150,12 @@ func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numB
 		return nil, 0, errors.New("failed to cast public key to ECDSA")
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	recipient := fromAddress
+	if opts.BlobRecipient != nil {
+		recipient = *opts.BlobRecipient
+	}
 
-	nonce, err := client.PendingNonceAt(context.Background(), authAcct.Address)
+	nonce, err := txNonce(client, authAcct, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -101,10 +164,10 @@ func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numB
 	if err != nil {
 		return nil, 0, err
 	}
-	
+
 	blockNumber = header.Number.Uint64()
 
-	chainID, err := client.NetworkID(context.Background())
+	chainID, err := SigningChainID(context.Background(), client, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -123,47 +186,76 @@ func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numB
 	incrementFactor := big.NewInt(110) // 10% increase
 	blobFeeCap.Mul(blobFeeCap, incrementFactor).Div(blobFeeCap, big.NewInt(100))
 
-	baseFee := header.BaseFee
-
-	// Set the max priority fee per gas to be 2 times the base fee
-	maxPriorityFee := new(big.Int).Mul(baseFee, big.NewInt(2))
-
-	// Set the max fee per gas to be 2 times the max priority fee
-	maxFeePerGas := new(big.Int).Mul(maxPriorityFee, big.NewInt(2))
-
+	// The fees are pinned by the config or derived from the oracle's base fee through the multipliers
+	tipCap, maxFeePerGas, err := opts.caps(client, header)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// Create a new BlobTx transaction
 	tx := types.NewTx(&types.BlobTx{
 		ChainID:    uint256.MustFromBig(chainID),
 		Nonce:      nonce,
-		GasTipCap: uint256.NewInt(0),
+		GasTipCap:  uint256.MustFromBig(tipCap),
 		GasFeeCap:  uint256.MustFromBig(maxFeePerGas),
 		Gas:        gasLimit,
-		To:         fromAddress,
+		To:         recipient,
 		BlobFeeCap: uint256.MustFromBig(blobFeeCap),
 		BlobHashes: blobHashes,
 		Sidecar:    sideCar,
 	})
 
-	println(" check BlobTx", tx)
-
 	// Sign the transaction with the authenticated account's private key
 	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
 	if err != nil {
-		log.Error("Failed to create keyed transactor", "error", err)
+		logger.Error("Failed to create keyed transactor", "error", err)
 		return nil, 0, err
 	}
 
 	signedTx, err := auth.Signer(auth.From, tx)
 	if err != nil {
-		log.Error("Failed to sign transaction", "error", err)
+		logger.Error("Failed to sign transaction", "error", err)
 		return nil, 0, err
 	}
 	return signedTx, blockNumber + offset, nil
 }
 
+// txNonce returns the nonce set in opts, or else the account's pending nonce.
+func txNonce(client *ethclient.Client, authAcct bb.AuthAcct, opts TxOptions) (uint64, error) {
+	if opts.Nonce != nil {
+		return *opts.Nonce, nil
+	}
+	return client.PendingNonceAt(context.Background(), authAcct.Address)
+}
+
+// NetworkIDReader is implemented by clients that can report the network ID of their node.
+type NetworkIDReader interface {
+	NetworkID(ctx context.Context) (*big.Int, error)
+}
+
+// SigningChainID returns the chain ID transactions built with opts are signed for: opts.ChainID
+// if it is set, or else the network ID reported by the node.
+//
+// Parameters:
+// - ctx: The context for the call.
+// - client: The client connected to the L1 node.
+// - opts: The transaction options.
+//
+// Returns:
+// - The chain ID, or an error if opts.ChainID is not positive or the node cannot be queried.
+func SigningChainID(ctx context.Context, client NetworkIDReader, opts TxOptions) (*big.Int, error) {
+	if opts.ChainID != nil {
+		if opts.ChainID.Sign() <= 0 {
+			return nil, fmt.Errorf("%w, got %s", ErrInvalidChainID, opts.ChainID)
+		}
+		return opts.ChainID, nil
+	}
+	return client.NetworkID(ctx)
+}
 
 func makeSidecar(blobs []kzg4844.Blob) *types.BlobTxSidecar {
+	InitKZG()
+
 	var (
 		commitments []kzg4844.Commitment
 		proofs      []kzg4844.Proof
@@ -212,4 +304,4 @@ func randFieldElement() [32]byte {
 	r.SetBytes(bytes)
 
 	return gokzg4844.SerializeScalar(r)
-}
\ No newline at end of file
+}
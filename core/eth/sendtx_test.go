@@ -0,0 +1,221 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeNetworkIDReader reports a fixed network ID.
+type fakeNetworkIDReader struct {
+	networkID *big.Int
+}
+
+func (f fakeNetworkIDReader) NetworkID(context.Context) (*big.Int, error) {
+	return f.networkID, nil
+}
+
+func TestSigningChainID(t *testing.T) {
+	node := fakeNetworkIDReader{networkID: big.NewInt(17000)}
+	tests := []struct {
+		name    string
+		opts    TxOptions
+		want    int64
+		wantErr error
+	}{
+		{name: "node network ID", want: 17000},
+		{name: "override", opts: TxOptions{ChainID: big.NewInt(1)}, want: 1},
+		{name: "zero override", opts: TxOptions{ChainID: big.NewInt(0)}, wantErr: ErrInvalidChainID},
+		{name: "negative override", opts: TxOptions{ChainID: big.NewInt(-1)}, wantErr: ErrInvalidChainID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SigningChainID(context.Background(), node, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SigningChainID error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SigningChainID: %v", err)
+			}
+			if got.Int64() != tt.want {
+				t.Fatalf("SigningChainID = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestChainIDOverrideSigning builds both transaction types with a chain ID override that differs
+// from the node's network ID, and checks they are signed for the override.
+func TestChainIDOverrideSigning(t *testing.T) {
+	InitKZG()
+	node := newFakeNode()
+	client := node.dial(t)
+	authAcct := testAccount(t)
+	override := big.NewInt(1)
+	opts := TxOptions{ChainID: override}
+
+	tests := []struct {
+		name  string
+		build func() (*types.Transaction, uint64, error)
+	}{
+		{name: "eth transfer", build: func() (*types.Transaction, uint64, error) {
+			return SelfETHTransfer(client, authAcct, big.NewInt(1_000_000), 1, opts)
+		}},
+		{name: "blob transaction", build: func() (*types.Transaction, uint64, error) {
+			return ExecuteBlobTransaction(client, authAcct, 1, 1, opts)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _, err := tt.build()
+			if err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			if tx.ChainId().Cmp(override) != 0 {
+				t.Fatalf("chain ID = %s, want %s", tx.ChainId(), override)
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(override), tx)
+			if err != nil || sender != authAcct.Address {
+				t.Fatalf("sender = %s, %v, want %s", sender, err, authAcct.Address)
+			}
+			if _, err := types.Sender(types.LatestSignerForChainID(big.NewInt(node.networkID)), tx); err == nil {
+				t.Fatal("transaction also verifies for the node's network ID")
+			}
+		})
+	}
+}
+
+func TestSelfETHTransfer(t *testing.T) {
+	node := newFakeNode()
+	node.nonce = 3
+	client := node.dial(t)
+	authAcct := testAccount(t)
+
+	tests := []struct {
+		name    string
+		value   *big.Int
+		wantErr error
+	}{
+		{name: "value", value: big.NewInt(1_000_000)},
+		{name: "zero value", value: big.NewInt(0)},
+		{name: "nil value", wantErr: ErrInvalidValue},
+		{name: "negative value", value: big.NewInt(-1), wantErr: ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, target, err := SelfETHTransfer(client, authAcct, tt.value, 2, TxOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SelfETHTransfer error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if target != 102 {
+				t.Fatalf("target block = %d, want 102", target)
+			}
+			if tx.Value().Cmp(tt.value) != 0 || *tx.To() != authAcct.Address || tx.Nonce() != 3 || tx.ChainId().Int64() != 17000 {
+				t.Fatalf("transaction = value %s to %s with nonce %d on chain %s", tx.Value(), tx.To(), tx.Nonce(), tx.ChainId())
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			if err != nil || sender != authAcct.Address {
+				t.Fatalf("sender = %s, %v, want %s", sender, err, authAcct.Address)
+			}
+		})
+	}
+}
+
+func TestExecuteBlobTransactionRecipient(t *testing.T) {
+	InitKZG()
+	node := newFakeNode()
+	client := node.dial(t)
+	authAcct := testAccount(t)
+	other, zero := common.Address{0xaa}, common.Address{}
+
+	tests := []struct {
+		name      string
+		recipient *common.Address
+		want      common.Address
+		wantErr   error
+	}{
+		{name: "account itself", want: authAcct.Address},
+		{name: "configured recipient", recipient: &other, want: other},
+		{name: "zero recipient", recipient: &zero, wantErr: ErrZeroRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _, err := ExecuteBlobTransaction(client, authAcct, 1, 1, TxOptions{BlobRecipient: tt.recipient})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExecuteBlobTransaction error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if *tx.To() != tt.want {
+				t.Fatalf("recipient = %s, want %s", tx.To(), tt.want)
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			if err != nil || sender != authAcct.Address {
+				t.Fatalf("sender = %s, %v, want %s", sender, err, authAcct.Address)
+			}
+		})
+	}
+}
+
+func TestValidateBlobCountMax(t *testing.T) {
+	tests := []struct {
+		numBlobs int
+		maxBlobs int
+		wantErr  error
+	}{
+		{numBlobs: 1, maxBlobs: MaxBlobsPerTransaction},
+		{numBlobs: MaxBlobsPerTransaction, maxBlobs: MaxBlobsPerTransaction},
+		{numBlobs: MaxBlobsPerTransaction + 1, maxBlobs: MaxBlobsPerTransaction, wantErr: ErrTooManyBlobs},
+		{numBlobs: PragueMaxBlobsPerTransaction, maxBlobs: PragueMaxBlobsPerTransaction},
+		{numBlobs: PragueMaxBlobsPerTransaction + 1, maxBlobs: PragueMaxBlobsPerTransaction, wantErr: ErrTooManyBlobs},
+		{numBlobs: 0, maxBlobs: PragueMaxBlobsPerTransaction, wantErr: ErrNoBlobs},
+		{numBlobs: -1, maxBlobs: MaxBlobsPerTransaction, wantErr: ErrNoBlobs},
+	}
+	for _, tt := range tests {
+		if err := ValidateBlobCountMax(tt.numBlobs, tt.maxBlobs); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateBlobCountMax(%d, %d) = %v, want %v", tt.numBlobs, tt.maxBlobs, err, tt.wantErr)
+		}
+	}
+
+	// ValidateBlobCount applies the Cancun limit
+	if err := ValidateBlobCount(MaxBlobsPerTransaction + 1); !errors.Is(err, ErrTooManyBlobs) {
+		t.Errorf("ValidateBlobCount(%d) = %v, want %v", MaxBlobsPerTransaction+1, err, ErrTooManyBlobs)
+	}
+}
+
+func TestExecuteBlobTransactionMaxBlobs(t *testing.T) {
+	client := newFakeNode().dial(t)
+	authAcct := testAccount(t)
+
+	// The counts are rejected before any blob is built
+	tests := []struct {
+		name     string
+		numBlobs int
+		maxBlobs int
+		wantErr  error
+	}{
+		{name: "above the default", numBlobs: MaxBlobsPerTransaction + 1, wantErr: ErrTooManyBlobs},
+		{name: "above a lower maximum", numBlobs: 3, maxBlobs: 2, wantErr: ErrTooManyBlobs},
+		{name: "above the Prague maximum", numBlobs: PragueMaxBlobsPerTransaction + 1, maxBlobs: PragueMaxBlobsPerTransaction, wantErr: ErrTooManyBlobs},
+		{name: "none", maxBlobs: PragueMaxBlobsPerTransaction, wantErr: ErrNoBlobs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ExecuteBlobTransaction(client, authAcct, tt.numBlobs, 1, TxOptions{MaxBlobs: tt.maxBlobs})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExecuteBlobTransaction error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
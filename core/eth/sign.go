@@ -0,0 +1,56 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	bb "github.com/primev/preconf_blob_bidder/core/mevcommit"
+)
+
+// BuildSignedTx builds and signs the transaction the bot would bid on, without sending it, and
+// returns its raw encoding. With numBlobs set it is a blob transaction as built by
+// ExecuteBlobTransaction, otherwise a transfer of value to the account itself as built by
+// SelfETHTransfer. The client is only used for what opts does not supply: the nonce, the base
+// fee the fee caps derive from and the chain ID.
+//
+// Parameters:
+// - client: The client connected to the L1 node.
+// - authAcct: The account signing the transaction.
+// - numBlobs: The blob count of a blob transaction, or zero for a transfer.
+// - value: The transfer value in wei, ignored for blob transactions.
+// - opts: The nonce and fees to use.
+//
+// Returns:
+// - The 0x-prefixed raw transaction, including the blob sidecar, or an error if it cannot be built.
+func BuildSignedTx(client *ethclient.Client, authAcct bb.AuthAcct, numBlobs int, value *big.Int, opts TxOptions) (string, error) {
+	var signedTx *types.Transaction
+	var err error
+	if numBlobs > 0 {
+		signedTx, _, err = ExecuteBlobTransaction(client, authAcct, numBlobs, 0, opts)
+	} else {
+		signedTx, _, err = SelfETHTransfer(client, authAcct, value, 0, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+	return EncodeSignedTx(signedTx)
+}
+
+// EncodeSignedTx returns the raw encoding of a signed transaction as it is sent to a node with
+// eth_sendRawTransaction.
+//
+// Parameters:
+// - tx: The signed transaction.
+//
+// Returns:
+// - The 0x-prefixed raw transaction, or an error if it cannot be encoded.
+func EncodeSignedTx(tx *types.Transaction) (string, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return hexutil.Encode(raw), nil
+}
@@ -0,0 +1,77 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBuildSignedTx(t *testing.T) {
+	InitKZG()
+	authAcct := testAccount(t)
+	nonce := uint64(42)
+
+	tests := []struct {
+		name      string
+		numBlobs  int
+		opts      TxOptions
+		wantType  uint8
+		wantNonce uint64
+		wantFee   *big.Int // The fee cap, if pinned.
+	}{
+		{name: "transfer", wantType: types.DynamicFeeTxType, wantNonce: 3},
+		{name: "pinned nonce and fees", opts: TxOptions{Nonce: &nonce, Fees: FeeConfig{FeeCap: big.NewInt(7e9), TipCap: big.NewInt(1e9)}}, wantType: types.DynamicFeeTxType, wantNonce: 42, wantFee: big.NewInt(7e9)},
+		{name: "blob transaction", numBlobs: 2, wantType: types.BlobTxType, wantNonce: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newFakeNode()
+			node.nonce = 3
+			client := node.dial(t)
+
+			raw, err := BuildSignedTx(client, authAcct, tt.numBlobs, big.NewInt(1_000), tt.opts)
+			if err != nil {
+				t.Fatalf("BuildSignedTx: %v", err)
+			}
+			data, err := hexutil.Decode(raw)
+			if err != nil {
+				t.Fatalf("raw transaction %q is not 0x-prefixed hex: %v", raw, err)
+			}
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary: %v", err)
+			}
+
+			if tx.Type() != tt.wantType || tx.Nonce() != tt.wantNonce {
+				t.Fatalf("transaction of type %d with nonce %d, want type %d with nonce %d", tx.Type(), tx.Nonce(), tt.wantType, tt.wantNonce)
+			}
+			if tt.wantFee != nil && tx.GasFeeCap().Cmp(tt.wantFee) != 0 {
+				t.Fatalf("fee cap = %s, want %s", tx.GasFeeCap(), tt.wantFee)
+			}
+			if tt.numBlobs > 0 && (len(tx.BlobHashes()) != tt.numBlobs || tx.BlobTxSidecar() == nil) {
+				t.Fatalf("%d blob hashes, sidecar %v, want %d blobs with their sidecar", len(tx.BlobHashes()), tx.BlobTxSidecar() != nil, tt.numBlobs)
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(17000)), tx)
+			if err != nil || sender != authAcct.Address {
+				t.Fatalf("sender = %s, %v, want %s", sender, err, authAcct.Address)
+			}
+			if sent := node.sentTxs(); len(sent) != 0 {
+				t.Fatalf("BuildSignedTx sent %d transactions", len(sent))
+			}
+		})
+	}
+}
+
+func TestEncodeSignedTx(t *testing.T) {
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1)})
+	raw, err := EncodeSignedTx(tx)
+	if err != nil {
+		t.Fatalf("EncodeSignedTx: %v", err)
+	}
+	want, _ := tx.MarshalBinary()
+	if raw != hexutil.Encode(want) {
+		t.Fatalf("EncodeSignedTx = %s, want %s", raw, hexutil.Encode(want))
+	}
+}
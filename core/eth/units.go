@@ -0,0 +1,111 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// etherDecimals is the number of decimal places between ether and wei.
+const etherDecimals = 18
+
+// gweiDecimals is the number of decimal places between gwei and wei.
+const gweiDecimals = 9
+
+// ParseEther converts a decimal ETH amount such as "0.15" into wei. The conversion is exact:
+// amounts with more than 18 decimal places are rejected rather than rounded, and there is
+// no upper bound on the amount.
+func ParseEther(value string) (*big.Int, error) {
+	return parseDecimal(value, etherDecimals)
+}
+
+// ParseGwei converts a decimal gwei amount such as "1.5" into wei. Like ParseEther, the
+// conversion is exact and amounts with more than 9 decimal places are rejected.
+func ParseGwei(value string) (*big.Int, error) {
+	return parseDecimal(value, gweiDecimals)
+}
+
+// ParseAmount converts a decimal amount in unit, one of "wei", "gwei" or "eth" (case-insensitive),
+// into wei. Like ParseEther, the conversion is exact: amounts with more decimal places than the
+// unit allows, including any fraction of a wei, are rejected.
+func ParseAmount(value, unit string) (*big.Int, error) {
+	decimals, err := unitDecimals(unit)
+	if err != nil {
+		return nil, err
+	}
+	return parseDecimal(value, decimals)
+}
+
+// ValidateUnit checks that unit is one ParseAmount accepts.
+func ValidateUnit(unit string) error {
+	_, err := unitDecimals(unit)
+	return err
+}
+
+// unitDecimals returns the number of decimal places between unit and wei.
+func unitDecimals(unit string) (int, error) {
+	switch strings.ToLower(unit) {
+	case "wei":
+		return 0, nil
+	case "gwei":
+		return gweiDecimals, nil
+	case "eth", "ether":
+		return etherDecimals, nil
+	default:
+		return 0, fmt.Errorf("unknown unit %q, must be wei, gwei or eth", unit)
+	}
+}
+
+// parseDecimal converts a non-negative decimal string into an integer scaled by 10^decimals.
+func parseDecimal(value string, decimals int) (*big.Int, error) {
+	s := strings.TrimSpace(value)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("invalid amount %q: more than %d decimal places", value, decimals)
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	// Right-pad the fraction so the digits form the scaled integer
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// FormatEther formats a wei amount as an exact decimal ETH string, without trailing zeros.
+func FormatEther(wei *big.Int) string {
+	return formatDecimal(wei, etherDecimals)
+}
+
+// FormatGwei formats a wei amount as an exact decimal gwei string, without trailing zeros.
+func FormatGwei(wei *big.Int) string {
+	return formatDecimal(wei, gweiDecimals)
+}
+
+// formatDecimal formats an integer scaled by 10^decimals as an exact decimal string.
+func formatDecimal(amount *big.Int, decimals int) string {
+	sign := ""
+	digits := amount.String()
+	if amount.Sign() < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	// Left-pad so there is at least one digit before the decimal point
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
@@ -0,0 +1,167 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		unit    string
+		want    string
+		wantErr bool
+	}{
+		{value: "0.15", unit: "eth", want: "150000000000000000"},
+		{value: "1", unit: "ETH", want: "1000000000000000000"},
+		{value: "0.000000000000000001", unit: "ether", want: "1"},
+		{value: " 2. ", unit: "eth", want: "2000000000000000000"},
+		{value: ".5", unit: "eth", want: "500000000000000000"},
+		{value: "123456789012345678901234567890", unit: "eth", want: "123456789012345678901234567890000000000000000000"},
+		{value: "1.5", unit: "gwei", want: "1500000000"},
+		{value: "42", unit: "wei", want: "42"},
+		{value: "0.0000000000000000001", unit: "eth", wantErr: true},
+		{value: "0.0000000001", unit: "gwei", wantErr: true},
+		{value: "1.5", unit: "wei", wantErr: true},
+		{value: "-1", unit: "eth", wantErr: true},
+		{value: "1e18", unit: "eth", wantErr: true},
+		{value: "0x10", unit: "eth", wantErr: true},
+		{value: "1.2.3", unit: "eth", wantErr: true},
+		{value: ".", unit: "eth", wantErr: true},
+		{value: "", unit: "eth", wantErr: true},
+		{value: "1", unit: "finney", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.unit, func(t *testing.T) {
+			got, err := ParseAmount(tt.value, tt.unit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmount error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Fatalf("ParseAmount = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnit(t *testing.T) {
+	tests := []struct {
+		unit    string
+		wantErr bool
+	}{
+		{unit: "wei"},
+		{unit: "gwei"},
+		{unit: "Gwei"},
+		{unit: "eth"},
+		{unit: "ETH"},
+		{unit: "ether"},
+		{unit: "finney", wantErr: true},
+		{unit: "", wantErr: true},
+		{unit: " eth", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			if err := ValidateUnit(tt.unit); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateUnit(%q) = %v, want error %v", tt.unit, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGwei(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "1", want: "1000000000"},
+		{value: "1.5", want: "1500000000"},
+		{value: "0.000000001", want: "1"},
+		{value: "0", want: "0"},
+		{value: "0.0000000001", wantErr: true},
+		{value: "-2", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseGwei(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGwei error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Fatalf("ParseGwei = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEther(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "0.04", want: "40000000000000000"},
+		{value: "0.11", want: "110000000000000000"},
+		{value: "0.001", want: "1000000000000000"},
+		{value: "0", want: "0"},
+		{value: "0.000000000000000001", want: "1"},
+		{value: " 2. ", want: "2000000000000000000"},
+		{value: ".5", want: "500000000000000000"},
+		{value: "123456789012345678901234567890", want: "123456789012345678901234567890000000000000000000"},
+		{value: "0.0000000000000000001", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "1e18", wantErr: true},
+		{value: "0x10", wantErr: true},
+		{value: "1.2.3", wantErr: true},
+		{value: ".", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseEther(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEther error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Fatalf("ParseEther = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		wei  string
+		eth  string
+		gwei string
+	}{
+		{wei: "0", eth: "0", gwei: "0"},
+		{wei: "1", eth: "0.000000000000000001", gwei: "0.000000001"},
+		{wei: "40000000000000000", eth: "0.04", gwei: "40000000"},
+		{wei: "1500000000", eth: "0.0000000015", gwei: "1.5"},
+		{wei: "1000000000000000000", eth: "1", gwei: "1000000000"},
+		{wei: "123456789012345678901234567890", eth: "123456789012.34567890123456789", gwei: "123456789012345678901.23456789"},
+		{wei: "-1500000000000000000", eth: "-1.5", gwei: "-1500000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wei, func(t *testing.T) {
+			wei, ok := new(big.Int).SetString(tt.wei, 10)
+			if !ok {
+				t.Fatalf("invalid amount %q", tt.wei)
+			}
+			if got := FormatEther(wei); got != tt.eth {
+				t.Errorf("FormatEther = %s, want %s", got, tt.eth)
+			}
+			if got := FormatGwei(wei); got != tt.gwei {
+				t.Errorf("FormatGwei = %s, want %s", got, tt.gwei)
+			}
+			// Formatting is exact, so non-negative amounts parse back unchanged
+			if wei.Sign() >= 0 {
+				if parsed, err := ParseEther(tt.eth); err != nil || parsed.Cmp(wei) != 0 {
+					t.Errorf("ParseEther(%s) = %v, %v, want %s", tt.eth, parsed, err, wei)
+				}
+			}
+		})
+	}
+}
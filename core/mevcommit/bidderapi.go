@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,12 +14,110 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/log"
 	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
 )
 
-func (b *Bidder) SendBid(input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error) {
+// Files the bid requests, the commitments received for them and the commitments observed on
+// chain are saved to.
+const (
+	DefaultBidRequestsFile         = "data/bid.json"
+	DefaultBidResponsesFile        = "data/response.json"
+	DefaultObservedCommitmentsFile = "data/commitments.json"
+)
+
+// ErrNoCommitment is returned by SendBidRequest, SendBid and SendBidAndCollect when the bidder
+// node accepted the bid but its response stream ended without any commitment. The result, or the
+// stream, is returned along with it.
+var ErrNoCommitment = errors.New("no commitment received for bid")
+
+// SavedBidRequest is a bid request as saved by the bidder, with its submission time.
+type SavedBidRequest struct {
+	Timestamp  int64   `json:"timestamp"`         // The time the bid was submitted, in Unix time.
+	BidRequest *pb.Bid `json:"bidRequest"`        // The submitted bid request.
+	BidHash    string  `json:"bidHash,omitempty"` // The hash the contract derives for the bid, see BidHash.
+}
+
+// BidResult describes the outcome of a bid sent to the mev-commit bidder node.
+type BidResult struct {
+	Commitments []*pb.Commitment // The commitments received from providers, in the order they arrived.
+	Submitted   time.Time        // The time at which the bid was submitted.
+	Duration    time.Duration    // The time from submission until the response stream ended or failed.
+	BidHash     common.Hash      // The hash the contract derives for the bid, zero if it could not be computed.
+
+	// FirstCommitmentLatency is the time from submission until the first commitment was
+	// received. It is zero if no commitment was received.
+	FirstCommitmentLatency time.Duration
+}
+
+// SendBid builds a bid for the given transactions and submits it to the mev-commit bidder node.
+// Like SendBidAndCollect, it drains and saves the commitments it receives, then returns the
+// drained response stream, for callers that read its header or trailer. With ReturnAfter set, the
+// rest of the stream is drained in the background and must not be read by the caller.
+//
+// Parameters:
+// - ctx: The context bounding the call, see SendBidRequest.
+// - input: Either a slice of transaction hashes ([]string) or a slice of signed transactions ([]*types.Transaction).
+// - amount: The bid amount in wei, as a decimal string.
+// - blockNumber: The L1 block number the bid targets.
+// - decayStart: The decay start timestamp in milliseconds.
+// - decayEnd: The decay end timestamp in milliseconds.
+//
+// Returns:
+// - The response stream, and an error if the bid fails, ErrNoCommitment if no provider committed to it. The stream is nil if the bid could not be built or sent.
+func (b *Bidder) SendBid(ctx context.Context, input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error) {
+	bidRequest, err := NewBidRequest(input, amount, blockNumber, decayStart, decayEnd)
+	if err != nil {
+		return nil, err
+	}
+	response, _, err := b.sendBidRequest(ctx, bidRequest)
+	return response, err
+}
+
+// SendBidAndCollect builds a bid for the given transactions, submits it to the mev-commit bidder
+// node and returns the commitments it receives, as SendBidRequest does.
+//
+// Parameters:
+// - ctx: The context bounding the call, see SendBidRequest.
+// - input: Either a slice of transaction hashes ([]string) or a slice of signed transactions ([]*types.Transaction).
+// - amount: The bid amount in wei, as a decimal string.
+// - blockNumber: The L1 block number the bid targets.
+// - decayStart: The decay start timestamp in milliseconds.
+// - decayEnd: The decay end timestamp in milliseconds.
+//
+// Returns:
+// - The result of the bid, or an error if the bid could not be built or sent.
+func (b *Bidder) SendBidAndCollect(ctx context.Context, input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (*BidResult, error) {
+	bidRequest, err := NewBidRequest(input, amount, blockNumber, decayStart, decayEnd)
+	if err != nil {
+		return nil, err
+	}
+	return b.SendBidRequest(ctx, bidRequest)
+}
+
+// NewBidRequest creates the bid request sent to the mev-commit bidder node.
+//
+// The bidder API's Bid message has no field naming a provider: the bidder node gossips every bid
+// to all connected providers, so a bid cannot be directed to a specific commiter.
+//
+// The bid decays linearly from decayStart to decayEnd; the Bid message has no field for another
+// decay shape. See ValidateDecayWindow.
+//
+// Parameters:
+// - input: Either a slice of transaction hashes ([]string) or a slice of signed transactions ([]*types.Transaction).
+// - amount: The bid amount in wei, as a decimal string.
+// - blockNumber: The L1 block number the bid targets.
+// - decayStart: The decay start timestamp in milliseconds.
+// - decayEnd: The decay end timestamp in milliseconds.
+//
+// Returns:
+// - A pointer to the bid request, or an error if the input type is unsupported or the decay window is invalid.
+func NewBidRequest(input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (*pb.Bid, error) {
+	if err := ValidateDecayWindow(decayStart, decayEnd); err != nil {
+		return nil, err
+	}
+
 	// Prepare variables to hold transaction hashes or raw transactions
 	var txHashes []string
 	var rawTransactions []string
@@ -37,13 +136,13 @@ func (b *Bidder) SendBid(input interface{}, amount string, blockNumber, decaySta
 		for i, tx := range v {
 			rlpEncodedTx, err := tx.MarshalBinary()
 			if err != nil {
-				log.Error("Failed to marshal transaction to raw format", "error", err)
+				logger.Error("Failed to marshal transaction to raw format", "error", err)
 				return nil, fmt.Errorf("failed to marshal transaction: %w", err)
 			}
 			rawTransactions[i] = hex.EncodeToString(rlpEncodedTx)
 		}
 	default:
-		log.Warn("Unsupported input type, must be []string or []*types.Transaction")
+		logger.Warn("Unsupported input type, must be []string or []*types.Transaction")
 		return nil, fmt.Errorf("unsupported input type: %T", input)
 	}
 
@@ -66,20 +165,68 @@ func (b *Bidder) SendBid(input interface{}, amount string, blockNumber, decaySta
 		bidRequest.RawTransactions = rawTxStrings
 	}
 
-	ctx := context.Background()
+	return bidRequest, nil
+}
+
+// SendBidRequest submits a prepared bid request to the mev-commit bidder node, then
+// drains and saves the commitments it receives. If the bidder was configured with ReturnAfter,
+// it returns once that many commitments have arrived and drains the rest of the stream in the
+// background; Flush waits for it.
+//
+// The call, including the response stream, ends once ctx is done or after the bidder's call
+// timeout, whichever comes first. A stream drained in the background is no longer bound to ctx,
+// only to the call timeout.
+//
+// Parameters:
+// - ctx: The context bounding the call.
+// - bidRequest: The bid request to submit.
+//
+// Returns:
+// - The result of the bid, and an error if the bid fails, ErrNoCommitment if no provider committed to it. On failure the result still holds the commitments received so far.
+func (b *Bidder) SendBidRequest(ctx context.Context, bidRequest *pb.Bid) (*BidResult, error) {
+	_, result, err := b.sendBidRequest(ctx, bidRequest)
+	return result, err
+}
+
+// sendBidRequest implements SendBidRequest, additionally returning the response stream, which
+// is nil if the bid could not be sent.
+func (b *Bidder) sendBidRequest(ctx context.Context, bidRequest *pb.Bid) (pb.Bidder_SendBidClient, *BidResult, error) {
+	// The call follows ctx until its stream is handed to a background drain, which outlives ctx
+	callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), b.callTimeout)
+	stop := context.AfterFunc(ctx, cancel)
+	draining := false
+	defer func() {
+		// A stream drained in the background cancels the call itself once it ends
+		if !draining {
+			stop()
+			cancel()
+		}
+	}()
+
+	result := &BidResult{Submitted: time.Now()}
+
+	// Compute the hash the contract will derive for the bid, to match commitments to it later
+	if bidHash, err := BidHash(bidRequest); err != nil {
+		b.logger.Warn("Failed to compute bid hash", "error", err)
+	} else {
+		result.BidHash = bidHash
+		b.logger.Info("Expected bid hash", "bidHash", bidHash)
+	}
 
 	// Send the bid request to the mev-commit client
-	response, err := b.client.SendBid(ctx, bidRequest)
+	response, err := b.client.SendBid(callCtx, bidRequest)
 	if err != nil {
-		log.Error("Failed to send bid", "error", err)
-		return nil, fmt.Errorf("failed to send bid: %w", err)
+		b.logger.Error("Failed to send bid", "error", err)
+		result.Duration = time.Since(result.Submitted)
+		return nil, result, fmt.Errorf("failed to send bid: %w", err)
 	}
 
-	var responses []interface{}
-	submitTimestamp := time.Now().Unix()
-
-	// Save the bid request along with the submission timestamp
-	go saveBidRequest("data/bid.json", bidRequest, submitTimestamp)
+	// Save the bid request along with the submission timestamp and bid hash
+	saved := SavedBidRequest{Timestamp: result.Submitted.Unix(), BidRequest: bidRequest}
+	if result.BidHash != (common.Hash{}) {
+		saved.BidHash = result.BidHash.Hex()
+	}
+	b.save(func() { b.storage.SaveBidRequest(saved) })
 
 	// Continuously receive bid responses
 	for {
@@ -89,91 +236,146 @@ func (b *Bidder) SendBid(input interface{}, amount string, blockNumber, decaySta
 			break
 		}
 		if err != nil {
-			log.Error("Failed to receive bid response", "error", err)
-			return nil, fmt.Errorf("failed to send bid: %w", err)
+			b.logger.Error("Failed to receive bid response", "error", err)
+			result.Duration = time.Since(result.Submitted)
+			commitments := result.Commitments
+			b.save(func() { b.storage.SaveBidResponses(commitments) })
+			return response, result, fmt.Errorf("failed to send bid: %w", err)
 		}
 
-		log.Info("Bid accepted", "commitment details", msg)
-		responses = append(responses, msg)
+		if len(result.Commitments) == 0 {
+			result.FirstCommitmentLatency = time.Since(result.Submitted)
+			recordFirstCommitment(result.FirstCommitmentLatency)
+			b.logger.Info("First commitment received", "latency", result.FirstCommitmentLatency)
+		}
+
+		b.logger.Info("Bid accepted", "commitment details", msg)
+		result.Commitments = append(result.Commitments, msg)
+
+		// Return early with enough commitments, the remaining ones are still saved
+		if b.returnAfter > 0 && len(result.Commitments) == b.returnAfter {
+			result.Duration = time.Since(result.Submitted)
+			stop()
+			draining = true
+			received := append([]*pb.Commitment(nil), result.Commitments...)
+			b.save(func() {
+				defer cancel()
+				b.drainBidResponses(response, received)
+			})
+			return response, result, nil
+		}
 	}
+	result.Duration = time.Since(result.Submitted)
 
 	// Timer before saving bid responses
 	startTimeBeforeSaveResponses := time.Now()
-	log.Info("End Time", "time", startTimeBeforeSaveResponses)
+	b.logger.Info("End Time", "time", startTimeBeforeSaveResponses)
+
+	// Save all bid responses
+	commitments := result.Commitments
+	b.save(func() { b.storage.SaveBidResponses(commitments) })
+
+	if len(result.Commitments) == 0 {
+		recordNoCommitment()
+		b.logger.Warn("No commitment received for bid, the stream ended without any", "blockNumber", bidRequest.BlockNumber, "amount", bidRequest.Amount, "duration", result.Duration)
+		return response, result, ErrNoCommitment
+	}
+	return response, result, nil
+}
+
+// drainBidResponses receives the rest of a bid response stream, then saves the commitments
+// received before it together with the ones it drained.
+func (b *Bidder) drainBidResponses(response pb.Bidder_SendBidClient, commitments []*pb.Commitment) {
+	for {
+		msg, err := response.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			b.logger.Error("Failed to receive bid response", "error", err)
+			break
+		}
+		b.logger.Info("Bid accepted", "commitment details", msg)
+		commitments = append(commitments, msg)
+	}
+	b.storage.SaveBidResponses(commitments)
+}
+
+// save runs a storage write in the background and tracks it so Flush can wait for it.
+func (b *Bidder) save(write func()) {
+	b.saves.Add(1)
+	go func() {
+		defer b.saves.Done()
+		write()
+	}()
+}
 
-	// Save all bid responses to a file
-	go saveBidResponses("data/response.json", responses)
-	return response, nil
+// Flush waits for all bid requests and responses handed to the storage so far to be written.
+// It should be called before the process exits so the last bids are not lost.
+func (b *Bidder) Flush() {
+	b.saves.Wait()
 }
 
-// saveBidRequest saves the bid request and timestamp to a JSON file.
+// saveBidRequest saves the bid request, its timestamp and its bid hash to a JSON file.
 // The data is appended to an array of existing bid requests.
 //
 // Parameters:
 // - filename: The name of the JSON file to save the bid request to.
-// - bidRequest: The bid request to save.
-// - timestamp: The timestamp of when the bid was submitted (in Unix time).
-func saveBidRequest(filename string, bidRequest *pb.Bid, timestamp int64) {
+// - saved: The bid request to save, with its submission time and bid hash.
+func saveBidRequest(filename string, saved SavedBidRequest) {
 	// Ensure the directory exists
 	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Error("Failed to create directory", "directory", dir, "error", err)
+		logger.Error("Failed to create directory", "directory", dir, "error", err)
 		return
 	}
 
-	// Prepare the data to be saved
-	data := map[string]interface{}{
-		"timestamp":  timestamp,
-		"bidRequest": bidRequest,
-	}
-
 	// Open the file, creating it if it doesn't exist
 	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
-		log.Error("Failed to open file", "filename", filename, "error", err)
+		logger.Error("Failed to open file", "filename", filename, "error", err)
 		return
 	}
 	defer file.Close()
 
 	// Read existing data from the file
-	var existingData []map[string]interface{}
+	var existingData []interface{}
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&existingData); err != nil && err.Error() != "EOF" {
-		log.Error("Failed to decode existing JSON data", "error", err)
+		logger.Error("Failed to decode existing JSON data", "error", err)
 		return
 	}
 
 	// Append the new bid request to the existing data
-	existingData = append(existingData, data)
+	existingData = append(existingData, saved)
 
 	// Write the updated data back to the file
 	file.Seek(0, 0)  // Move to the beginning of the file
 	file.Truncate(0) // Clear the file content
 	encoder := json.NewEncoder(file)
 	if err := encoder.Encode(existingData); err != nil {
-		log.Error("Failed to encode data to JSON", "error", err)
+		logger.Error("Failed to encode data to JSON", "error", err)
 	}
 }
 
-
 // saveBidResponses saves the bid responses to a JSON file.
 // The responses are appended to an array of existing responses.
 //
 // Parameters:
 // - filename: The name of the JSON file to save the bid responses to.
 // - responses: A slice of bid responses to save.
-func saveBidResponses(filename string, responses []interface{}) {
+func saveBidResponses(filename string, responses []*pb.Commitment) {
 	// Ensure the directory exists
 	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Error("Failed to create directory", "directory", dir, "error", err)
+		logger.Error("Failed to create directory", "directory", dir, "error", err)
 		return
 	}
 
 	// Open the file, creating it if it doesn't exist
 	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
-		log.Error("Failed to open file", "filename", filename, "error", err)
+		logger.Error("Failed to open file", "filename", filename, "error", err)
 		return
 	}
 	defer file.Close()
@@ -182,18 +384,60 @@ func saveBidResponses(filename string, responses []interface{}) {
 	var existingData []interface{}
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&existingData); err != nil && err.Error() != "EOF" {
-		log.Error("Failed to decode existing JSON data", "error", err)
+		logger.Error("Failed to decode existing JSON data", "error", err)
 		return
 	}
 
 	// Append the new bid responses to the existing data
-	existingData = append(existingData, responses...)
+	for _, response := range responses {
+		existingData = append(existingData, response)
+	}
 
 	// Write the updated responses back to the file
 	file.Seek(0, 0)  // Move to the beginning of the file
 	file.Truncate(0) // Clear the file content
 	encoder := json.NewEncoder(file)
 	if err := encoder.Encode(existingData); err != nil {
-		log.Error("Failed to encode data to JSON", "error", err)
+		logger.Error("Failed to encode data to JSON", "error", err)
+	}
+}
+
+// LoadBidRequests reads the bid requests saved by the bidder.
+//
+// Parameters:
+// - filename: The name of the JSON file the bid requests were saved to.
+//
+// Returns:
+// - The saved bid requests in submission order, or an error if the file cannot be read or decoded.
+func LoadBidRequests(filename string) ([]SavedBidRequest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bid requests: %w", err)
+	}
+
+	var requests []SavedBidRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("failed to decode bid requests: %w", err)
+	}
+	return requests, nil
+}
+
+// LoadBidResponses reads the commitments saved by the bidder.
+//
+// Parameters:
+// - filename: The name of the JSON file the bid responses were saved to.
+//
+// Returns:
+// - The saved commitments in the order they were received, or an error if the file cannot be read or decoded.
+func LoadBidResponses(filename string) ([]*pb.Commitment, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bid responses: %w", err)
+	}
+
+	var commitments []*pb.Commitment
+	if err := json.Unmarshal(data, &commitments); err != nil {
+		return nil, fmt.Errorf("failed to decode bid responses: %w", err)
 	}
+	return commitments, nil
 }
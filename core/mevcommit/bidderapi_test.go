@@ -0,0 +1,414 @@
+package mevcommit
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewBidRequest(t *testing.T) {
+	to := common.Address{1}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 7, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)})
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	hash := tx.Hash().Hex()
+
+	tests := []struct {
+		name       string
+		input      interface{}
+		start, end int64
+		want       *pb.Bid
+		wantErr    bool
+	}{
+		{
+			name:  "hashes",
+			input: []string{hash, "ab" + hash[4:]},
+			start: 1000, end: 37000,
+			want: &pb.Bid{TxHashes: []string{hash[2:], "ab" + hash[4:]}, Amount: "42", BlockNumber: 100, DecayStartTimestamp: 1000, DecayEndTimestamp: 37000},
+		},
+		{
+			name:  "transactions",
+			input: []*types.Transaction{tx},
+			start: 1000, end: 37000,
+			want: &pb.Bid{RawTransactions: []string{hex.EncodeToString(raw)}, Amount: "42", BlockNumber: 100, DecayStartTimestamp: 1000, DecayEndTimestamp: 37000},
+		},
+		{name: "unsupported input", input: []common.Hash{tx.Hash()}, start: 1000, end: 37000, wantErr: true},
+		{name: "empty decay window", input: []string{hash}, start: 1000, end: 1000, wantErr: true},
+		{name: "reversed decay window", input: []string{hash}, start: 37000, end: 1000, wantErr: true},
+		{name: "negative decay start", input: []string{hash}, start: -1, end: 1000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBidRequest(tt.input, "42", 100, tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBidRequest error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("NewBidRequest = %+v, want %+v", got, tt.want)
+			}
+			if err == nil {
+				if err := ValidateBid(got); err != nil {
+					t.Fatalf("built bid is not valid: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSendBidAndCollect(t *testing.T) {
+	commitments := func(n int) func(*pb.Bid) []*pb.Commitment {
+		return func(bid *pb.Bid) []*pb.Commitment {
+			out := make([]*pb.Commitment, n)
+			for i := range out {
+				out[i] = &pb.Commitment{TxHashes: bid.TxHashes, BidAmount: bid.Amount, BlockNumber: bid.BlockNumber, ProviderAddress: common.Address{byte(i + 1)}.Hex()}
+			}
+			return out
+		}
+	}
+	tests := []struct {
+		name            string
+		server          *fakeBidderServer
+		returnAfter     int
+		wantCommitments int // In the result.
+		wantSaved       int // Saved once the bidder is flushed.
+		wantErr         error
+		wantCode        codes.Code
+	}{
+		{name: "one commitment", server: &fakeBidderServer{}, wantCommitments: 1, wantSaved: 1},
+		{name: "several commitments", server: &fakeBidderServer{commit: commitments(3)}, wantCommitments: 3, wantSaved: 3},
+		{name: "no commitment", server: &fakeBidderServer{commit: commitments(0)}, wantErr: ErrNoCommitment},
+		{name: "return early", server: &fakeBidderServer{commit: commitments(3)}, returnAfter: 1, wantCommitments: 1, wantSaved: 3},
+		{
+			name:     "rejected",
+			server:   &fakeBidderServer{fail: func(*pb.Bid) error { return status.Error(codes.InvalidArgument, "bid amount too low") }},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := dialFakeBidder(t, tt.server)
+			b.returnAfter = tt.returnAfter
+			hash := common.Hash{0xab}.Hex()
+
+			result, err := b.SendBidAndCollect(context.Background(), []string{hash}, "42", 100, 1000, 37000)
+			switch {
+			case tt.wantCode != codes.OK:
+				if status.Code(errors.Unwrap(err)) != tt.wantCode {
+					t.Fatalf("SendBidAndCollect error = %v, want code %s", err, tt.wantCode)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("SendBidAndCollect error = %v, want %v", err, tt.wantErr)
+			}
+			if result == nil {
+				t.Fatal("SendBidAndCollect returned no result")
+			}
+			if len(result.Commitments) != tt.wantCommitments {
+				t.Fatalf("%d commitments, want %d", len(result.Commitments), tt.wantCommitments)
+			}
+			if tt.wantCommitments > 0 && result.FirstCommitmentLatency <= 0 {
+				t.Fatal("first commitment latency not recorded")
+			}
+			if result.BidHash == (common.Hash{}) {
+				t.Fatal("bid hash not computed")
+			}
+
+			bids := tt.server.received()
+			if len(bids) != 1 || bids[0].TxHashes[0] != hash[2:] || bids[0].Amount != "42" || bids[0].BlockNumber != 100 {
+				t.Fatalf("server received %+v", bids)
+			}
+			b.Flush()
+			storage := b.storage.(*InMemoryStorage)
+			if saved := storage.BidRequests(); len(saved) != 1 || saved[0].BidHash != result.BidHash.Hex() {
+				t.Fatalf("saved bid requests = %+v", saved)
+			}
+			if saved := storage.BidResponses(); len(saved) != tt.wantSaved {
+				t.Fatalf("%d commitments saved, want %d", len(saved), tt.wantSaved)
+			}
+		})
+	}
+}
+
+func TestSendBidReturnsDrainedStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   *fakeBidderServer
+		wantErr  error
+		wantCode codes.Code
+	}{
+		{name: "commitments", server: &fakeBidderServer{}},
+		{name: "no commitment", server: &fakeBidderServer{commit: func(*pb.Bid) []*pb.Commitment { return nil }}, wantErr: ErrNoCommitment},
+		{
+			name:     "rejected",
+			server:   &fakeBidderServer{fail: func(*pb.Bid) error { return status.Error(codes.InvalidArgument, "bid amount too low") }},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := dialFakeBidder(t, tt.server)
+			stream, err := b.SendBid(context.Background(), []string{common.Hash{0xab}.Hex()}, "42", 100, 1000, 37000)
+			if stream == nil {
+				t.Fatalf("SendBid returned no stream, error %v", err)
+			}
+			if tt.wantCode != codes.OK {
+				if status.Code(errors.Unwrap(err)) != tt.wantCode {
+					t.Fatalf("SendBid error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendBid error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The commitments were drained into the storage, not left for the caller
+			if _, err := stream.Recv(); err != io.EOF {
+				t.Fatalf("Recv on the returned stream = %v, want io.EOF", err)
+			}
+			b.Flush()
+			if saved := b.storage.(*InMemoryStorage).BidRequests(); len(saved) != 1 {
+				t.Fatalf("%d bid requests saved, want 1", len(saved))
+			}
+		})
+	}
+}
+
+// gatedStorage holds every write until release is closed.
+type gatedStorage struct {
+	*InMemoryStorage
+	release chan struct{}
+}
+
+func (s gatedStorage) SaveBidRequest(saved SavedBidRequest) {
+	<-s.release
+	s.InMemoryStorage.SaveBidRequest(saved)
+}
+
+func (s gatedStorage) SaveBidResponses(responses []*pb.Commitment) {
+	<-s.release
+	s.InMemoryStorage.SaveBidResponses(responses)
+}
+
+func TestBidderFlush(t *testing.T) {
+	for _, bids := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("%d bids", bids), func(t *testing.T) {
+			b := dialFakeBidder(t, &fakeBidderServer{})
+			storage := gatedStorage{InMemoryStorage: NewInMemoryStorage(), release: make(chan struct{})}
+			b.storage = storage
+
+			for i := 0; i < bids; i++ {
+				if _, err := b.SendBid(context.Background(), []string{common.Hash{byte(i)}.Hex()}, "42", 100, 1000, 37000); err != nil {
+					t.Fatalf("SendBid: %v", err)
+				}
+			}
+
+			flushed := make(chan struct{})
+			go func() {
+				b.Flush()
+				close(flushed)
+			}()
+			if bids > 0 {
+				select {
+				case <-flushed:
+					t.Fatal("Flush returned before the saves were written")
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+			close(storage.release)
+			select {
+			case <-flushed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Flush did not return once the saves were written")
+			}
+
+			if got := len(storage.BidRequests()); got != bids {
+				t.Fatalf("%d bid requests saved, want %d", got, bids)
+			}
+			if got := len(storage.BidResponses()); got != bids {
+				t.Fatalf("%d commitments saved, want %d", got, bids)
+			}
+		})
+	}
+}
+
+// TestBidHasNoProviderField guards the documented limitation that bids cannot target a provider:
+// if the bidder API gains such a field, NewBidRequest and the README must be revisited.
+func TestBidHasNoProviderField(t *testing.T) {
+	fields := (&pb.Bid{}).ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		name := strings.ToLower(string(fields.Get(i).Name()))
+		for _, word := range []string{"provider", "commiter", "committer"} {
+			if strings.Contains(name, word) {
+				t.Fatalf("Bid has a %s field, bids may now target a provider", fields.Get(i).Name())
+			}
+		}
+	}
+}
+
+// gatedBidderServer sends the first commitments of a bid, then holds the rest until release is
+// closed.
+type gatedBidderServer struct {
+	pb.UnimplementedBidderServer
+	first, rest int
+	release     chan struct{}
+}
+
+func (s *gatedBidderServer) SendBid(bid *pb.Bid, stream grpc.ServerStreamingServer[pb.Commitment]) error {
+	for i := 0; i < s.first+s.rest; i++ {
+		if i == s.first {
+			<-s.release
+		}
+		if err := stream.Send(&pb.Commitment{TxHashes: bid.TxHashes, ProviderAddress: common.Address{byte(i + 1)}.Hex()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSendBidReturnAfter(t *testing.T) {
+	tests := []struct {
+		name            string
+		returnAfter     int
+		first, rest     int
+		wantCommitments int
+		wantEarly       bool // Whether SendBidAndCollect returns before the held commitments are sent.
+	}{
+		{name: "after the first", returnAfter: 1, first: 1, rest: 2, wantCommitments: 1, wantEarly: true},
+		{name: "after several", returnAfter: 2, first: 2, rest: 1, wantCommitments: 2, wantEarly: true},
+		{name: "more than sent", returnAfter: 5, first: 1, rest: 2, wantCommitments: 3},
+		{name: "disabled", first: 1, rest: 2, wantCommitments: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &gatedBidderServer{first: tt.first, rest: tt.rest, release: make(chan struct{})}
+			b := dialFakeBidder(t, server)
+			b.returnAfter = tt.returnAfter
+
+			type sent struct {
+				result *BidResult
+				err    error
+			}
+			done := make(chan sent, 1)
+			go func() {
+				result, err := b.SendBidAndCollect(context.Background(), []string{"ab"}, "42", 100, 1000, 37000)
+				done <- sent{result, err}
+			}()
+
+			var got sent
+			select {
+			case got = <-done:
+				if !tt.wantEarly {
+					t.Fatal("SendBidAndCollect returned before the stream ended")
+				}
+				close(server.release)
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantEarly {
+					t.Fatal("SendBidAndCollect did not return after the first commitments")
+				}
+				close(server.release)
+				got = <-done
+			}
+			if got.err != nil {
+				t.Fatalf("SendBidAndCollect: %v", got.err)
+			}
+			if len(got.result.Commitments) != tt.wantCommitments {
+				t.Fatalf("%d commitments, want %d", len(got.result.Commitments), tt.wantCommitments)
+			}
+
+			// The commitments drained in the background are saved too
+			b.Flush()
+			if saved := b.storage.(*InMemoryStorage).BidResponses(); len(saved) != tt.first+tt.rest {
+				t.Fatalf("%d commitments saved, want %d", len(saved), tt.first+tt.rest)
+			}
+		})
+	}
+
+	if _, err := NewBidderClient(BidderConfig{ServerAddress: "localhost:1", ReturnAfter: -1}); err == nil {
+		t.Fatal("NewBidderClient accepted a negative ReturnAfter")
+	}
+}
+
+// hungBidderServer accepts bids but never answers them, until the call is cancelled.
+type hungBidderServer struct {
+	pb.UnimplementedBidderServer
+}
+
+func (hungBidderServer) SendBid(bid *pb.Bid, stream grpc.ServerStreamingServer[pb.Commitment]) error {
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func TestSendBidRequestContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctxTimeout  time.Duration
+		callTimeout time.Duration
+	}{
+		{name: "context deadline", ctxTimeout: 50 * time.Millisecond, callTimeout: time.Minute},
+		{name: "call timeout", ctxTimeout: time.Minute, callTimeout: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := dialFakeBidder(t, hungBidderServer{})
+			b.callTimeout = tt.callTimeout
+			ctx, cancel := context.WithTimeout(context.Background(), tt.ctxTimeout)
+			defer cancel()
+			bid, err := NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+			if err != nil {
+				t.Fatalf("NewBidRequest: %v", err)
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := b.SendBidRequest(ctx, bid)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if code := status.Code(errors.Unwrap(err)); code != codes.DeadlineExceeded && code != codes.Canceled {
+					t.Fatalf("SendBidRequest error = %v, want the call cut short", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("SendBidRequest did not return once the deadline passed")
+			}
+		})
+	}
+}
+
+func TestSendBidRequestDrainOutlivesContext(t *testing.T) {
+	server := &gatedBidderServer{first: 1, rest: 2, release: make(chan struct{})}
+	b := dialFakeBidder(t, server)
+	b.returnAfter = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bid, err := NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+	if err != nil {
+		t.Fatalf("NewBidRequest: %v", err)
+	}
+	if _, err := b.SendBidRequest(ctx, bid); err != nil {
+		t.Fatalf("SendBidRequest: %v", err)
+	}
+
+	// The caller is done with the bid, the remaining commitments are still drained and saved
+	cancel()
+	close(server.release)
+	b.Flush()
+	if saved := b.storage.(*InMemoryStorage).BidResponses(); len(saved) != 3 {
+		t.Fatalf("%d commitments saved, want 3", len(saved))
+	}
+}
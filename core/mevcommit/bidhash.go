@@ -0,0 +1,167 @@
+package mevcommit
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+// EIP-712 type and domain used by the PreConfCommitmentStore contract to hash bids.
+var (
+	bidTypeHash = crypto.Keccak256Hash([]byte(
+		"PreConfBid(string txnHash,uint64 bid,uint64 blockNumber,uint64 decayStartTimeStamp,uint64 decayEndTimeStamp)",
+	))
+	bidDomainSeparator = crypto.Keccak256Hash(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version)")),
+		crypto.Keccak256([]byte("PreConfBid")),
+		crypto.Keccak256([]byte("1")),
+	)
+)
+
+// ComputeBidHash computes the hash the PreConfCommitmentStore contract derives for a bid with its
+// getBidHash: the EIP-712 typed data hash of a PreConfBid in the "PreConfBid" version "1" domain.
+// It is the BidHash of the CommitmentStored events the bid produces, so it can be used to match
+// commitments seen on chain to the bids that caused them.
+//
+// Parameters:
+// - bid: The bid request, with either transaction hashes or raw transactions.
+//
+// Returns:
+// - The bid hash, or an error if the bid has no transactions or a value the contract cannot take.
+func ComputeBidHash(bid *pb.Bid) ([32]byte, error) {
+	if bid == nil {
+		return [32]byte{}, fmt.Errorf("no bid to hash")
+	}
+	txnHash, err := bidTxnHash(bid)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	amount, err := strconv.ParseUint(bid.Amount, 10, 64)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("bid amount %q does not fit the contract's uint64: %w", bid.Amount, err)
+	}
+	if bid.BlockNumber < 0 || bid.DecayStartTimestamp < 0 || bid.DecayEndTimestamp < 0 {
+		return [32]byte{}, fmt.Errorf("bid block number and decay timestamps must not be negative")
+	}
+
+	return hashBid(txnHash, amount, uint64(bid.BlockNumber), uint64(bid.DecayStartTimestamp), uint64(bid.DecayEndTimestamp)), nil
+}
+
+// BidHash is ComputeBidHash returning a common.Hash.
+//
+// Parameters:
+// - bidRequest: The bid request, with either transaction hashes or raw transactions.
+//
+// Returns:
+// - The bid hash, or an error if the bid cannot be hashed.
+func BidHash(bidRequest *pb.Bid) (common.Hash, error) {
+	hash, err := ComputeBidHash(bidRequest)
+	return common.Hash(hash), err
+}
+
+// hashBid hashes the values of a PreConfBid as the contract's getBidHash does, with txnHash the
+// comma-separated transaction hashes of the bid, without 0x prefixes.
+func hashBid(txnHash string, bid, blockNumber, decayStart, decayEnd uint64) common.Hash {
+	structHash := crypto.Keccak256Hash(
+		bidTypeHash.Bytes(),
+		crypto.Keccak256([]byte(txnHash)),
+		common.BigToHash(new(big.Int).SetUint64(bid)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(blockNumber)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(decayStart)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(decayEnd)).Bytes(),
+	)
+	return crypto.Keccak256Hash([]byte("\x19\x01"), bidDomainSeparator.Bytes(), structHash.Bytes())
+}
+
+// VerifyBidHash checks BidHash against the contract's own getBidHash for the same bid, for
+// example after a contract upgrade that may have changed the hashing scheme.
+//
+// Parameters:
+// - client: The client connected to the mev-commit chain.
+// - bidRequest: The bid request to hash.
+//
+// Returns:
+// - The bid hash if both agree, or an error if they differ or the contract call fails.
+func VerifyBidHash(client *ethclient.Client, bidRequest *pb.Bid) (common.Hash, error) {
+	localHash, err := BidHash(bidRequest)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	txnHash, err := bidTxnHash(bidRequest)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	amount, err := strconv.ParseUint(bidRequest.Amount, 10, 64)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("bid amount %q does not fit the contract's uint64: %w", bidRequest.Amount, err)
+	}
+
+	// Load the PreConfCommitmentStore contract ABI
+	preconfABI, err := LoadABI("abi/PreConfCommitmentStore.abi")
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to load ABI file: %v", err)
+	}
+
+	// Bind the contract to the client
+	preconfContract := bind.NewBoundContract(common.HexToAddress(PreconfManagerAddress), preconfABI, client, client, client)
+
+	// Call the getBidHash function with the same values BidHash hashed
+	opts, cancel := callOpts()
+	defer cancel()
+	var bidHashResult []interface{}
+	err = preconfContract.Call(opts, &bidHashResult, "getBidHash", txnHash, amount, uint64(bidRequest.BlockNumber),
+		uint64(bidRequest.DecayStartTimestamp), uint64(bidRequest.DecayEndTimestamp))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to call getBidHash function: %v", err)
+	}
+
+	// Extract the bid hash as [32]byte
+	contractHash, ok := bidHashResult[0].([32]byte)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("failed to convert bid hash to [32]byte")
+	}
+
+	if common.Hash(contractHash) != localHash {
+		return common.Hash{}, fmt.Errorf("bid hash mismatch: computed %s, contract %s", localHash, common.Hash(contractHash))
+	}
+	return localHash, nil
+}
+
+// bidTxnHash returns the txnHash string the contract hashes for a bid: its transaction hashes
+// without 0x prefixes, joined by commas. For bids carrying raw transactions, the hashes are
+// those of the decoded transactions.
+func bidTxnHash(bidRequest *pb.Bid) (string, error) {
+	if len(bidRequest.TxHashes) > 0 {
+		hashes := make([]string, len(bidRequest.TxHashes))
+		for i, hash := range bidRequest.TxHashes {
+			hashes[i] = strings.TrimPrefix(hash, "0x")
+		}
+		return strings.Join(hashes, ","), nil
+	}
+
+	hashes := make([]string, 0, len(bidRequest.RawTransactions))
+	for _, raw := range bidRequest.RawTransactions {
+		data, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode raw transaction: %w", err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(data); err != nil {
+			return "", fmt.Errorf("failed to unmarshal raw transaction: %w", err)
+		}
+		hashes = append(hashes, strings.TrimPrefix(tx.Hash().Hex(), "0x"))
+	}
+	if len(hashes) == 0 {
+		return "", fmt.Errorf("bid has no transactions")
+	}
+	return strings.Join(hashes, ","), nil
+}
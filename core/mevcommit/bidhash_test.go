@@ -0,0 +1,205 @@
+package mevcommit
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+// typedBidHash hashes a bid with geth's EIP-712 implementation, independently of hashBid.
+func typedBidHash(t *testing.T, txnHash string, bid, blockNumber, decayStart, decayEnd uint64) common.Hash {
+	t.Helper()
+	data := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "version", Type: "string"}},
+			"PreConfBid": {
+				{Name: "txnHash", Type: "string"},
+				{Name: "bid", Type: "uint64"},
+				{Name: "blockNumber", Type: "uint64"},
+				{Name: "decayStartTimeStamp", Type: "uint64"},
+				{Name: "decayEndTimeStamp", Type: "uint64"},
+			},
+		},
+		PrimaryType: "PreConfBid",
+		Domain:      apitypes.TypedDataDomain{Name: "PreConfBid", Version: "1"},
+		Message: apitypes.TypedDataMessage{
+			"txnHash":             txnHash,
+			"bid":                 math.NewHexOrDecimal256(int64(bid)),
+			"blockNumber":         math.NewHexOrDecimal256(int64(blockNumber)),
+			"decayStartTimeStamp": math.NewHexOrDecimal256(int64(decayStart)),
+			"decayEndTimeStamp":   math.NewHexOrDecimal256(int64(decayEnd)),
+		},
+	}
+	hash, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		t.Fatalf("TypedDataAndHash: %v", err)
+	}
+	return common.BytesToHash(hash)
+}
+
+func TestBidHash(t *testing.T) {
+	to := common.Address{1}
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)}),
+		types.NewTx(&types.LegacyTx{Nonce: 2, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)}),
+	}
+	raw := make([]string, len(txs))
+	for i, tx := range txs {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+		raw[i] = hex.EncodeToString(data)
+	}
+	hash0, hash1 := txs[0].Hash().Hex(), txs[1].Hash().Hex()
+	bothHashes := hash0[2:] + "," + hash1[2:]
+
+	tests := []struct {
+		name        string
+		bid         *pb.Bid
+		wantTxnHash string // The txnHash the contract hashes.
+		wantErr     bool
+	}{
+		{name: "one hash", bid: &pb.Bid{TxHashes: []string{hash0[2:]}}, wantTxnHash: hash0[2:]},
+		{name: "prefixed hashes", bid: &pb.Bid{TxHashes: []string{hash0, hash1}}, wantTxnHash: bothHashes},
+		{name: "raw transactions", bid: &pb.Bid{RawTransactions: raw}, wantTxnHash: bothHashes},
+		{name: "prefixed raw transaction", bid: &pb.Bid{RawTransactions: []string{"0x" + raw[0]}}, wantTxnHash: hash0[2:]},
+		{name: "no transactions", bid: &pb.Bid{}, wantErr: true},
+		{name: "invalid raw transaction", bid: &pb.Bid{RawTransactions: []string{"zz"}}, wantErr: true},
+		{name: "undecodable raw transaction", bid: &pb.Bid{RawTransactions: []string{"c0"}}, wantErr: true},
+		{name: "amount above uint64", bid: &pb.Bid{TxHashes: []string{hash0}, Amount: "18446744073709551616"}, wantErr: true},
+		{name: "negative block", bid: &pb.Bid{TxHashes: []string{hash0}, BlockNumber: -1}, wantErr: true},
+		{name: "negative decay", bid: &pb.Bid{TxHashes: []string{hash0}, DecayStartTimestamp: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bid := tt.bid
+			if bid.Amount == "" {
+				bid.Amount = "1000000000"
+			}
+			if bid.BlockNumber == 0 {
+				bid.BlockNumber = 100
+			}
+			if bid.DecayStartTimestamp == 0 {
+				bid.DecayStartTimestamp, bid.DecayEndTimestamp = 1000, 37000
+			}
+
+			got, err := BidHash(bid)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BidHash error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := typedBidHash(t, tt.wantTxnHash, 1000000000, 100, 1000, 37000); got != want {
+				t.Fatalf("BidHash = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// TestComputeBidHash checks ComputeBidHash against fixed hashes. They were worked out outside this
+// package from the contract's PreConfBid type and domain, so a change to the hashing fails here
+// even if the EIP-712 reference used by TestBidHash changes with it.
+func TestComputeBidHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		bid     *pb.Bid
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "short hash",
+			bid:  &pb.Bid{TxHashes: []string{"ab"}, Amount: "1", BlockNumber: 1, DecayStartTimestamp: 0, DecayEndTimestamp: 1},
+			want: "0x15c979e88e988dcf710a8a495addf792eaa2c6b68ad8938aad966339514ac320",
+		},
+		{
+			name: "large values",
+			bid:  &pb.Bid{TxHashes: []string{"0x" + strings.Repeat("0", 64)}, Amount: "4611686018427387904", BlockNumber: 20_000_000, DecayStartTimestamp: 1_700_000_000_000, DecayEndTimestamp: 1_700_000_036_000},
+			want: "0x745168ba150b7e18767c6eee0439c4399304d05f3448031f04b71fe2b8aa622f",
+		},
+		{
+			name: "two transactions",
+			bid: &pb.Bid{
+				TxHashes:            []string{"0c6e9a5f1c2b9f0e6f9f0d1a7c3b5e2d4f6a8b0c1d2e3f4a5b6c7d8e9f0a1b2c", "a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b2"},
+				Amount:              "40000000000000000",
+				BlockNumber:         2_500_000,
+				DecayStartTimestamp: 1_730_000_000_000,
+				DecayEndTimestamp:   1_730_000_036_000,
+			},
+			want: "0xcf815aeee2f299a7fdf74694386b9359f2ae3160a038f51e9ad3990a02e4e05b",
+		},
+		{name: "nil bid", wantErr: true},
+		{name: "no transactions", bid: &pb.Bid{Amount: "1", BlockNumber: 1}, wantErr: true},
+		{name: "empty amount", bid: &pb.Bid{TxHashes: []string{"ab"}, BlockNumber: 1}, wantErr: true},
+		{name: "negative amount", bid: &pb.Bid{TxHashes: []string{"ab"}, Amount: "-1", BlockNumber: 1}, wantErr: true},
+		{name: "hex amount", bid: &pb.Bid{TxHashes: []string{"ab"}, Amount: "0x10", BlockNumber: 1}, wantErr: true},
+		{name: "amount above uint64", bid: &pb.Bid{TxHashes: []string{"ab"}, Amount: "18446744073709551616", BlockNumber: 1}, wantErr: true},
+		{name: "negative block", bid: &pb.Bid{TxHashes: []string{"ab"}, Amount: "1", BlockNumber: -1}, wantErr: true},
+		{name: "negative decay end", bid: &pb.Bid{TxHashes: []string{"ab"}, Amount: "1", BlockNumber: 1, DecayEndTimestamp: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComputeBidHash(tt.bid)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComputeBidHash error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if got != ([32]byte{}) {
+					t.Fatalf("ComputeBidHash = %x along with an error", got)
+				}
+				return
+			}
+			if common.Hash(got).Hex() != tt.want {
+				t.Fatalf("ComputeBidHash = %s, want %s", common.Hash(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyBidHash(t *testing.T) {
+	bid := &pb.Bid{TxHashes: []string{"0xab", "cd"}, Amount: "42", BlockNumber: 100, DecayStartTimestamp: 1000, DecayEndTimestamp: 37000}
+	tests := []struct {
+		name    string
+		bidHash func(txnHash string, bid, blockNumber, decayStart, decayEnd uint64) common.Hash
+		bid     *pb.Bid
+		wantErr bool
+	}{
+		{name: "same scheme", bidHash: hashBid, bid: bid},
+		{
+			name: "changed scheme",
+			bidHash: func(txnHash string, bid, blockNumber, decayStart, decayEnd uint64) common.Hash {
+				return hashBid(txnHash, bid, blockNumber, decayEnd, decayStart)
+			},
+			bid:     bid,
+			wantErr: true,
+		},
+		{name: "unhashable bid", bidHash: hashBid, bid: &pb.Bid{Amount: "42"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeRegistryChain{contracts: []abi.ABI{loadTestABI(t, "PreConfCommitmentStore.abi")}, bidHash: tt.bidHash}
+			client := chain.dial(t)
+			inRepoRoot(t)
+
+			got, err := VerifyBidHash(client, tt.bid)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyBidHash error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want, _ := BidHash(tt.bid); got != want {
+				t.Fatalf("VerifyBidHash = %s, want %s", got, want)
+			}
+		})
+	}
+}
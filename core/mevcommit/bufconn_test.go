@@ -0,0 +1,117 @@
+package mevcommit
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeBidderServer is an in-process bidder node. It answers every bid with the commitments
+// returned by commit, or fails it with the error returned by fail.
+type fakeBidderServer struct {
+	pb.UnimplementedBidderServer
+
+	commit func(bid *pb.Bid) []*pb.Commitment // The commitments sent for a bid. If nil, one commitment is sent.
+	fail   func(bid *pb.Bid) error            // The error a bid fails with. If nil, bids succeed.
+
+	mu   sync.Mutex
+	bids []*pb.Bid // The bids received, in order.
+}
+
+// SendBid records the bid and streams its commitments.
+func (s *fakeBidderServer) SendBid(bid *pb.Bid, stream grpc.ServerStreamingServer[pb.Commitment]) error {
+	s.mu.Lock()
+	s.bids = append(s.bids, bid)
+	s.mu.Unlock()
+
+	if s.fail != nil {
+		if err := s.fail(bid); err != nil {
+			return err
+		}
+	}
+	commitments := []*pb.Commitment{{TxHashes: bid.TxHashes, BidAmount: bid.Amount, BlockNumber: bid.BlockNumber, ProviderAddress: "0x01"}}
+	if s.commit != nil {
+		commitments = s.commit(bid)
+	}
+	for _, c := range commitments {
+		if err := stream.Send(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// received returns the bids received so far.
+func (s *fakeBidderServer) received() []*pb.Bid {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pb.Bid(nil), s.bids...)
+}
+
+// dialFakeBidder serves srv over an in-process connection and returns a Bidder connected to it,
+// which saves its bids in memory.
+func dialFakeBidder(t *testing.T, srv pb.BidderServer) *Bidder {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterBidderServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	b := NewBidderClientWithConn(conn)
+	b.storage = NewInMemoryStorage()
+	return b
+}
+
+func TestNewBidderClientWithConn(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit func(bid *pb.Bid) []*pb.Commitment
+		want   int
+	}{
+		{name: "one commitment", want: 1},
+		{name: "no commitment", commit: func(*pb.Bid) []*pb.Commitment { return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeBidderServer{commit: tt.commit}
+			b := dialFakeBidder(t, srv)
+
+			stream, err := b.client.SendBid(context.Background(), &pb.Bid{TxHashes: []string{"ab"}, Amount: "42", BlockNumber: 100})
+			if err != nil {
+				t.Fatalf("SendBid: %v", err)
+			}
+			var got int
+			for {
+				if _, err := stream.Recv(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("Recv: %v", err)
+				}
+				got++
+			}
+			if got != tt.want {
+				t.Fatalf("got %d commitments, want %d", got, tt.want)
+			}
+			if bids := srv.received(); len(bids) != 1 || bids[0].Amount != "42" {
+				t.Fatalf("server received %v", bids)
+			}
+		})
+	}
+}
@@ -4,8 +4,13 @@
 package mevcommit
 
 import (
+	"context"
 	"crypto/ecdsa"
+	"fmt"
 	"math/big"
+	"strings"
+	"sync"
+	"time"
 
 	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
 	"google.golang.org/grpc"
@@ -15,23 +20,75 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers the gzip compressor.
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 )
 
-const HOLESKY_CHAIN_ID = 1700
+const HOLESKY_CHAIN_ID = 17000
+
+const (
+	// DefaultMaxRecvMsgSize is the largest gRPC message accepted from the bidder node, in bytes.
+	DefaultMaxRecvMsgSize = 16 * 1024 * 1024
+	// DefaultCallTimeout is the deadline applied to each call to the bidder node, including
+	// draining the commitment stream of a bid.
+	DefaultCallTimeout = 2 * time.Minute
+	// DefaultDialTimeout is the deadline for establishing a connection to an Ethereum node.
+	DefaultDialTimeout = 30 * time.Second
+)
+
+// contractCallTimeout is the deadline of the contract calls made by the package.
+var contractCallTimeout = DefaultCallTimeout
+
+// SetCallTimeout sets the deadline of the contract calls made by the package, such as reading the
+// current window or a deposit. It should be called before the package is used; a non-positive
+// timeout restores DefaultCallTimeout.
+func SetCallTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultCallTimeout
+	}
+	contractCallTimeout = timeout
+}
+
+// callOpts returns the options of a contract call bounded by the call timeout, and the function
+// releasing its context.
+func callOpts() (*bind.CallOpts, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), contractCallTimeout)
+	return &bind.CallOpts{Context: ctx}, cancel
+}
+
+const (
+	// dnsTargetPrefix marks server addresses resolved by gRPC's DNS resolver, which re-resolves
+	// the name and returns every address behind it.
+	dnsTargetPrefix = "dns:///"
+	// roundRobinServiceConfig balances calls across all resolved addresses.
+	roundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+)
 
 // BidderConfig holds the configuration settings for the mev-commit bidder node.
 type BidderConfig struct {
-	ServerAddress string `json:"server_address" yaml:"server_address"` // The address of the gRPC server for the bidder node.
-	LogFmt        string `json:"log_fmt" yaml:"log_fmt"`               // The format for logging output.
-	LogLevel      string `json:"log_level" yaml:"log_level"`           // The level of logging detail.
+	ServerAddress  string        `json:"server_address" yaml:"server_address"`       // The address of the gRPC server for the bidder node, as host:port or a gRPC target such as dns:///host:port.
+	LogFmt         string        `json:"log_fmt" yaml:"log_fmt"`                     // The format for logging output.
+	LogLevel       string        `json:"log_level" yaml:"log_level"`                 // The level of logging detail.
+	MaxRecvMsgSize int           `json:"max_recv_msg_size" yaml:"max_recv_msg_size"` // The largest message accepted from the bidder node, in bytes. Zero uses DefaultMaxRecvMsgSize.
+	CallTimeout    time.Duration `json:"call_timeout" yaml:"call_timeout"`           // The deadline for each call to the bidder node. Zero uses DefaultCallTimeout.
+	Compression    string        `json:"compression" yaml:"compression"`             // The gRPC compressor for calls to the bidder node, such as gzip. Empty disables compression.
+	ReturnAfter    int           `json:"return_after" yaml:"return_after"`           // Commitments after which a bid returns, the rest are drained and saved in the background. Zero waits for the stream to end.
+	Logger         log.Logger    `json:"-" yaml:"-"`                                 // The logger used by the client. If nil, the package logger is used.
+	Storage        Storage       `json:"-" yaml:"-"`                                 // Where bids and commitments are saved. If nil, they are saved to the default files.
 }
 
 // Bidder utilizes the mev-commit bidder client to interact with the mev-commit chain.
 type Bidder struct {
-	client pb.BidderClient // gRPC client for interacting with the mev-commit bidder service.
+	conn        grpc.ClientConnInterface // gRPC connection to the mev-commit bidder node.
+	client      pb.BidderClient          // gRPC client for interacting with the mev-commit bidder service.
+	callTimeout time.Duration            // Deadline applied to each call to the bidder service.
+	logger      log.Logger               // Logger for the client's output.
+	storage     Storage                  // Where bids and commitments are saved.
+	saves       sync.WaitGroup           // Storage writes still in progress.
+	returnAfter int                      // Commitments after which a bid returns, zero to wait for the stream to end.
 }
 
 // GethConfig holds configuration settings for a Geth node to connect to the mev-commit chain.
@@ -45,6 +102,12 @@ type AuthAcct struct {
 	PublicKey  *ecdsa.PublicKey   // The public key derived from the private key.
 	Address    common.Address     // The Ethereum address derived from the public key.
 	Auth       *bind.TransactOpts // The transaction options for signing transactions.
+	ChainID    *big.Int           // The chain ID the transaction options sign for.
+}
+
+// ChainIDReader is implemented by clients that can report the chain ID of the node they are connected to.
+type ChainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
 }
 
 // NewBidderClient creates a new gRPC client connection to the bidder service and returns a Bidder instance.
@@ -55,19 +118,79 @@ type AuthAcct struct {
 // Returns:
 // - A pointer to a Bidder struct, or an error if the connection fails.
 func NewBidderClient(cfg BidderConfig) (*Bidder, error) {
+	// Apply defaults and validate the gRPC limits
+	if cfg.MaxRecvMsgSize == 0 {
+		cfg.MaxRecvMsgSize = DefaultMaxRecvMsgSize
+	}
+	if cfg.MaxRecvMsgSize < 0 {
+		return nil, fmt.Errorf("max receive message size must be positive, got %d", cfg.MaxRecvMsgSize)
+	}
+	if cfg.CallTimeout == 0 {
+		cfg.CallTimeout = DefaultCallTimeout
+	}
+	if cfg.CallTimeout < 0 {
+		return nil, fmt.Errorf("call timeout must be positive, got %s", cfg.CallTimeout)
+	}
+	if cfg.ReturnAfter < 0 {
+		return nil, fmt.Errorf("commitments to return after must not be negative, got %d", cfg.ReturnAfter)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize)),
+	}
+	// Compress calls with a registered compressor, if one is configured
+	if cfg.Compression != "" {
+		if encoding.GetCompressor(cfg.Compression) == nil {
+			return nil, fmt.Errorf("unknown gRPC compressor %q", cfg.Compression)
+		}
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(cfg.Compression)))
+	}
+	// A DNS target can resolve to several bidder replicas, spread the calls over all of them
+	if strings.HasPrefix(cfg.ServerAddress, dnsTargetPrefix) {
+		opts = append(opts, grpc.WithDefaultServiceConfig(roundRobinServiceConfig))
+	}
+
 	// Establish a gRPC connection to the bidder service
-	conn, err := grpc.NewClient(cfg.ServerAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(cfg.ServerAddress, opts...)
 	if err != nil {
-		log.Crit("Failed to connect to gRPC server", "err", err)
-		return nil, err
+		logger.Error("Failed to connect to gRPC server", "err", err)
+		return nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
 	}
 
 	// Create a new bidder client using the gRPC connection
-	client := pb.NewBidderClient(conn)
-	return &Bidder{client: client}, nil
+	bidder := NewBidderClientWithConn(conn)
+	bidder.callTimeout = cfg.CallTimeout
+	bidder.returnAfter = cfg.ReturnAfter
+	if cfg.Logger != nil {
+		bidder.logger = cfg.Logger
+	}
+	if cfg.Storage != nil {
+		bidder.storage = cfg.Storage
+	}
+	return bidder, nil
+}
+
+// NewBidderClientWithConn returns a Bidder that uses an existing gRPC connection. This allows
+// callers, such as tests, to supply their own connection (for example one backed by bufconn).
+//
+// Parameters:
+// - conn: The gRPC connection to the bidder service.
+//
+// Returns:
+// - A pointer to a Bidder struct using DefaultCallTimeout, the package logger and the default files.
+func NewBidderClientWithConn(conn grpc.ClientConnInterface) *Bidder {
+	return &Bidder{
+		conn:        conn,
+		client:      pb.NewBidderClient(conn),
+		callTimeout: DefaultCallTimeout,
+		logger:      logger,
+		storage:     defaultFileStorage,
+	}
 }
 
-// NewGethClient connects to an Ethereum-compatible chain using the provided RPC endpoint.
+// NewGethClient connects to an Ethereum-compatible chain using the provided RPC endpoint,
+// within DefaultDialTimeout.
 //
 // Parameters:
 // - endpoint: The RPC endpoint of the Ethereum node.
@@ -75,8 +198,27 @@ func NewBidderClient(cfg BidderConfig) (*Bidder, error) {
 // Returns:
 // - A pointer to an ethclient.Client for interacting with the Ethereum node, or an error if the connection fails.
 func NewGethClient(endpoint string) (*ethclient.Client, error) {
+	return NewGethClientWithTimeout(endpoint, DefaultDialTimeout)
+}
+
+// NewGethClientWithTimeout connects to an Ethereum-compatible chain using the provided RPC
+// endpoint, giving up if the connection is not established within timeout.
+//
+// Parameters:
+// - endpoint: The RPC endpoint of the node.
+// - timeout: The deadline for establishing the connection. It does not apply to later calls.
+//
+// Returns:
+// - A pointer to an ethclient.Client, or an error if the connection fails.
+func NewGethClientWithTimeout(endpoint string, timeout time.Duration) (*ethclient.Client, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("dial timeout must be positive, got %s", timeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
 	// Dial the Ethereum RPC endpoint
-	client, err := rpc.Dial(endpoint)
+	client, err := rpc.DialContext(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -102,15 +244,16 @@ func AuthenticateAddress(privateKeyHex string) (AuthAcct, error) {
 	// Convert the hex-encoded private key to an ECDSA private key
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
-		log.Crit("Failed to load private key", "err", err)
-		return AuthAcct{}, err
+		logger.Error("Failed to load private key", "err", err)
+		return AuthAcct{}, fmt.Errorf("failed to load private key: %w", err)
 	}
 
 	// Extract the public key from the private key
 	publicKey := privateKey.Public()
 	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
-		log.Crit("Failed to assert public key type")
+		logger.Error("Failed to assert public key type")
+		return AuthAcct{}, fmt.Errorf("failed to assert public key type")
 	}
 
 	// Generate the Ethereum address from the public key
@@ -122,7 +265,8 @@ func AuthenticateAddress(privateKeyHex string) (AuthAcct, error) {
 	// Create the transaction options with the private key and chain ID
 	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
 	if err != nil {
-		log.Crit("Failed to create authorized transactor", "err", err)
+		logger.Error("Failed to create authorized transactor", "err", err)
+		return AuthAcct{}, fmt.Errorf("failed to create authorized transactor: %w", err)
 	}
 
 	// Return the AuthAcct struct containing the private key, public key, address, and transaction options
@@ -131,5 +275,51 @@ func AuthenticateAddress(privateKeyHex string) (AuthAcct, error) {
 		PublicKey:  publicKeyECDSA,
 		Address:    address,
 		Auth:       auth,
+		ChainID:    chainID,
 	}, nil
 }
+
+// VerifyChainID checks that the node the client is connected to is on the chain transactions
+// are signed for. Signing for the wrong chain makes transactions fail or, worse, replayable on the
+// other chain.
+//
+// Parameters:
+// - ctx: The context for the call.
+// - client: The client connected to the node.
+// - signingChainID: The chain ID transactions are signed for.
+//
+// Returns:
+// - An error if the chain IDs differ or the node's chain ID cannot be read.
+func VerifyChainID(ctx context.Context, client ChainIDReader, signingChainID *big.Int) error {
+	if signingChainID == nil {
+		return fmt.Errorf("no signing chain ID")
+	}
+
+	nodeChainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID from node: %w", err)
+	}
+
+	if nodeChainID.Cmp(signingChainID) != 0 {
+		return fmt.Errorf("chain ID mismatch: node is on chain %s but transactions are signed for chain %s", nodeChainID, signingChainID)
+	}
+	return nil
+}
+
+// ForChain returns a copy of the account whose transactor signs for chainID, e.g. to send
+// transactions on the mev-commit chain with an account authenticated for L1.
+//
+// Parameters:
+// - chainID: The chain the transactor signs for.
+//
+// Returns:
+// - The account for chainID, or an error if the transactor cannot be created.
+func (a AuthAcct) ForChain(chainID *big.Int) (AuthAcct, error) {
+	auth, err := bind.NewKeyedTransactorWithChainID(a.PrivateKey, chainID)
+	if err != nil {
+		return AuthAcct{}, fmt.Errorf("failed to create authorized transactor: %w", err)
+	}
+	a.Auth = auth
+	a.ChainID = chainID
+	return a, nil
+}
@@ -0,0 +1,174 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// testPrivateKey is a well-known development key, never used on a live chain.
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// fakeChainIDReader reports a fixed chain ID, or fails with err.
+type fakeChainIDReader struct {
+	chainID *big.Int
+	err     error
+}
+
+func (f fakeChainIDReader) ChainID(context.Context) (*big.Int, error) {
+	return f.chainID, f.err
+}
+
+func TestAuthenticateAddressSignsForHolesky(t *testing.T) {
+	acct, err := AuthenticateAddress(testPrivateKey)
+	if err != nil {
+		t.Fatalf("AuthenticateAddress: %v", err)
+	}
+	if acct.ChainID.Cmp(big.NewInt(17000)) != 0 {
+		t.Fatalf("ChainID = %s, want 17000", acct.ChainID)
+	}
+
+	// Deposits and withdrawals are signed with the transactor, so check the chain it signs for
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 1, Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1)})
+	signed, err := acct.Auth.Signer(acct.Address, tx)
+	if err != nil {
+		t.Fatalf("Signer: %v", err)
+	}
+	if signed.ChainId().Cmp(big.NewInt(HOLESKY_CHAIN_ID)) != 0 {
+		t.Fatalf("transactor signs for chain %s, want %d", signed.ChainId(), HOLESKY_CHAIN_ID)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(17000)), signed)
+	if err != nil || sender != acct.Address {
+		t.Fatalf("sender = %s, %v, want %s", sender, err, acct.Address)
+	}
+}
+
+func TestForChainSignsForChain(t *testing.T) {
+	acct, err := AuthenticateAddress(testPrivateKey)
+	if err != nil {
+		t.Fatalf("AuthenticateAddress: %v", err)
+	}
+	other, err := acct.ForChain(big.NewInt(17864))
+	if err != nil {
+		t.Fatalf("ForChain: %v", err)
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 1, Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1)})
+	signed, err := other.Auth.Signer(other.Address, tx)
+	if err != nil {
+		t.Fatalf("Signer: %v", err)
+	}
+	if signed.ChainId().Cmp(big.NewInt(17864)) != 0 || other.ChainID.Cmp(big.NewInt(17864)) != 0 {
+		t.Fatalf("ForChain signs for %s (ChainID %s), want 17864", signed.ChainId(), other.ChainID)
+	}
+	if acct.ChainID.Cmp(big.NewInt(HOLESKY_CHAIN_ID)) != 0 {
+		t.Fatalf("ForChain changed the original account's chain to %s", acct.ChainID)
+	}
+	if want := crypto.PubkeyToAddress(acct.PrivateKey.PublicKey); other.Address != want {
+		t.Fatalf("address = %s, want %s", other.Address, want)
+	}
+}
+
+func TestVerifyChainID(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    fakeChainIDReader
+		signing *big.Int
+		wantErr string
+	}{
+		{name: "match", node: fakeChainIDReader{chainID: big.NewInt(1)}, signing: big.NewInt(1)},
+		{name: "mismatch", node: fakeChainIDReader{chainID: big.NewInt(1)}, signing: big.NewInt(17000), wantErr: "chain ID mismatch"},
+		{name: "node error", node: fakeChainIDReader{err: errors.New("boom")}, signing: big.NewInt(1), wantErr: "failed to get chain ID"},
+		{name: "no signing chain", node: fakeChainIDReader{chainID: big.NewInt(1)}, wantErr: "no signing chain ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyChainID(context.Background(), tt.node, tt.signing)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyChainID: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyChainID error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// compressionRecorder records the compression of the requests a server receives.
+type compressionRecorder struct {
+	mu          sync.Mutex
+	compression []string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if h, ok := s.(*stats.InHeader); ok {
+		r.mu.Lock()
+		r.compression = append(r.compression, h.Compression)
+		r.mu.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestBidderCompression(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression string
+		wantErr     bool
+	}{
+		{name: "none"},
+		{name: "gzip", compression: "gzip"},
+		{name: "unregistered", compression: "zstd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Listen: %v", err)
+			}
+			recorder := &compressionRecorder{}
+			server := grpc.NewServer(grpc.StatsHandler(recorder))
+			pb.RegisterBidderServer(server, &fakeBidderServer{})
+			go server.Serve(lis)
+			defer server.Stop()
+
+			b, err := NewBidderClient(BidderConfig{ServerAddress: lis.Addr().String(), Compression: tt.compression, Storage: NewInMemoryStorage()})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBidderClient error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := b.SendBid(context.Background(), []string{"ab"}, "42", 100, 1000, 37000); err != nil {
+				t.Fatalf("SendBid: %v", err)
+			}
+			b.Flush()
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if len(recorder.compression) != 1 || recorder.compression[0] != tt.compression {
+				t.Fatalf("server received compression %q, want %q", recorder.compression, tt.compression)
+			}
+		})
+	}
+}
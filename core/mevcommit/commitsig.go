@@ -0,0 +1,106 @@
+package mevcommit
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP-712 type and domain used by the PreConfCommitmentStore contract to hash commitments.
+var (
+	commitmentTypeHash = crypto.Keccak256Hash([]byte(
+		"PreConfCommitment(string txnHash,uint64 bid,uint64 blockNumber,uint64 decayStartTimeStamp,uint64 decayEndTimeStamp,bytes32 bidHash,string signature,string sharedSecretKey)",
+	))
+	commitmentDomainSeparator = crypto.Keccak256Hash(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version)")),
+		crypto.Keccak256([]byte("PreConfCommitment")),
+		crypto.Keccak256([]byte("1")),
+	)
+)
+
+// ErrCommitmentHashMismatch is returned by VerifyCommitmentSignature when the commitment hash of
+// an event differs from the one computed for its fields, so the signature cannot be trusted to
+// cover them. This also happens if the contract changed its hashing scheme.
+var ErrCommitmentHashMismatch = errors.New("commitment hash does not match the commitment")
+
+// ComputeCommitmentHash mirrors the contract's getPreConfHash: the EIP-712 typed data hash of a
+// PreConfCommitment in the "PreConfCommitment" version "1" domain. The bid hash, bid signature and
+// shared secret are hashed as lowercase hex strings without 0x prefixes, as the provider does.
+//
+// Parameters:
+// - txnHash: The comma-separated transaction hashes of the bid, without 0x prefixes.
+// - bid: The bid amount in wei.
+// - blockNumber: The L1 block number the bid targets.
+// - decayStart: The decay start timestamp in milliseconds.
+// - decayEnd: The decay end timestamp in milliseconds.
+// - bidHash: The hash of the bid, see ComputeBidHash.
+// - bidSignature: The bidder's signature of the bid hash.
+// - sharedSecretKey: The secret shared between bidder and provider.
+//
+// Returns:
+// - The commitment hash.
+func ComputeCommitmentHash(txnHash string, bid, blockNumber, decayStart, decayEnd uint64, bidHash [32]byte, bidSignature, sharedSecretKey []byte) common.Hash {
+	structHash := crypto.Keccak256Hash(
+		commitmentTypeHash.Bytes(),
+		crypto.Keccak256([]byte(txnHash)),
+		common.BigToHash(new(big.Int).SetUint64(bid)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(blockNumber)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(decayStart)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(decayEnd)).Bytes(),
+		crypto.Keccak256([]byte(hex.EncodeToString(bidHash[:]))),
+		crypto.Keccak256([]byte(hex.EncodeToString(bidSignature))),
+		crypto.Keccak256([]byte(hex.EncodeToString(sharedSecretKey))),
+	)
+	return crypto.Keccak256Hash([]byte("\x19\x01"), commitmentDomainSeparator.Bytes(), structHash.Bytes())
+}
+
+// VerifyCommitmentSignature checks that a stored commitment was signed by the expected provider.
+// The commitment hash is recomputed from the event's fields, and the signer is recovered from
+// the commitment signature over it, which providers produce with a plain secp256k1 signature of
+// the hash and a recovery id of 27 or 28.
+//
+// Parameters:
+// - event: The CommitmentStored event.
+// - expectedCommiter: The provider the commitment should come from, usually event.Commiter.
+//
+// Returns:
+// - Whether the signer is expectedCommiter, or an error if the signature is malformed or the hash does not match the event (ErrCommitmentHashMismatch).
+func VerifyCommitmentSignature(event *CommitmentStoredEvent, expectedCommiter common.Address) (bool, error) {
+	if event == nil {
+		return false, errors.New("commitment event is missing")
+	}
+
+	hash := ComputeCommitmentHash(event.TxnHash, event.Bid, event.BlockNumber, event.DecayStartTimeStamp,
+		event.DecayEndTimeStamp, event.BidHash, event.BidSignature, event.SharedSecretKey)
+	if hash != common.Hash(event.CommitmentHash) {
+		return false, fmt.Errorf("%w: computed %s, event %s", ErrCommitmentHashMismatch, hash, common.Hash(event.CommitmentHash))
+	}
+
+	signer, err := recoverSigner(hash, event.CommitmentSignature)
+	if err != nil {
+		return false, fmt.Errorf("invalid commitment signature: %w", err)
+	}
+	return signer == expectedCommiter, nil
+}
+
+// recoverSigner returns the address that produced a 65-byte signature of hash, accepting recovery
+// ids of 0 and 1 as well as 27 and 28.
+func recoverSigner(hash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(signature))
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
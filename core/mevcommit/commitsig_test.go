@@ -0,0 +1,193 @@
+package mevcommit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+// signedCommitment returns a commitment event committed to and signed by key, with a recovery id
+// of 27 or 28 as providers produce it.
+func signedCommitment(t *testing.T, key string) *CommitmentStoredEvent {
+	t.Helper()
+	privateKey, err := crypto.HexToECDSA(key)
+	if err != nil {
+		t.Fatalf("HexToECDSA: %v", err)
+	}
+	event := &CommitmentStoredEvent{
+		Commiter:            crypto.PubkeyToAddress(privateKey.PublicKey),
+		Bid:                 42,
+		BlockNumber:         100,
+		BidHash:             common.Hash{0xab},
+		DecayStartTimeStamp: 1000,
+		DecayEndTimeStamp:   37000,
+		TxnHash:             "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		BidSignature:        []byte{0x01, 0x02},
+		SharedSecretKey:     []byte{0x03, 0x04},
+	}
+	hash := ComputeCommitmentHash(event.TxnHash, event.Bid, event.BlockNumber, event.DecayStartTimeStamp,
+		event.DecayEndTimeStamp, event.BidHash, event.BidSignature, event.SharedSecretKey)
+	event.CommitmentHash = hash
+	signature, err := crypto.Sign(hash.Bytes(), privateKey)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	event.CommitmentSignature = signature
+	return event
+}
+
+func TestVerifyCommitmentSignature(t *testing.T) {
+	const providerKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	const otherKey = "59c6995e998f97a5a0044966f8a18aec7c0e8d0b8d0f2e0f44bea40d8a3e1a5c"
+	tests := []struct {
+		name     string
+		modify   func(e *CommitmentStoredEvent)
+		expected common.Address // The expected committer, event.Commiter if zero.
+		want     bool
+		wantErr  error
+		anyErr   bool // Whether the signature cannot be checked, for another reason.
+	}{
+		{name: "signed by the committer", want: true},
+		{name: "recovery id of 0 or 1", modify: func(e *CommitmentStoredEvent) { e.CommitmentSignature[crypto.RecoveryIDOffset] -= 27 }, want: true},
+		{name: "other committer", expected: common.Address{1}},
+		{name: "signed by another provider", modify: func(e *CommitmentStoredEvent) {
+			e.CommitmentSignature = signedCommitment(t, otherKey).CommitmentSignature
+		}},
+		{name: "bid changed", modify: func(e *CommitmentStoredEvent) { e.Bid++ }, wantErr: ErrCommitmentHashMismatch},
+		{name: "shared secret changed", modify: func(e *CommitmentStoredEvent) { e.SharedSecretKey = []byte{0x05} }, wantErr: ErrCommitmentHashMismatch},
+		{name: "short signature", modify: func(e *CommitmentStoredEvent) { e.CommitmentSignature = e.CommitmentSignature[:64] }, anyErr: true},
+		{name: "invalid recovery id", modify: func(e *CommitmentStoredEvent) { e.CommitmentSignature[crypto.RecoveryIDOffset] = 5 }, anyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := signedCommitment(t, providerKey)
+			if tt.modify != nil {
+				tt.modify(event)
+			}
+			expected := event.Commiter
+			if tt.expected != (common.Address{}) {
+				expected = tt.expected
+			}
+
+			got, err := VerifyCommitmentSignature(event, expected)
+			switch {
+			case tt.wantErr != nil || tt.anyErr:
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("VerifyCommitmentSignature error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("VerifyCommitmentSignature: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("VerifyCommitmentSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := VerifyCommitmentSignature(nil, common.Address{}); err == nil {
+		t.Fatal("VerifyCommitmentSignature accepted a missing event")
+	}
+}
+
+func TestComputeCommitmentHash(t *testing.T) {
+	base := signedCommitment(t, "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	hash := func(e *CommitmentStoredEvent) common.Hash {
+		return ComputeCommitmentHash(e.TxnHash, e.Bid, e.BlockNumber, e.DecayStartTimeStamp, e.DecayEndTimeStamp, e.BidHash, e.BidSignature, e.SharedSecretKey)
+	}
+	tests := []struct {
+		name   string
+		modify func(e *CommitmentStoredEvent)
+	}{
+		{name: "transaction hash", modify: func(e *CommitmentStoredEvent) { e.TxnHash = "ff" }},
+		{name: "bid", modify: func(e *CommitmentStoredEvent) { e.Bid = 43 }},
+		{name: "block number", modify: func(e *CommitmentStoredEvent) { e.BlockNumber = 101 }},
+		{name: "decay start", modify: func(e *CommitmentStoredEvent) { e.DecayStartTimeStamp = 1001 }},
+		{name: "decay end", modify: func(e *CommitmentStoredEvent) { e.DecayEndTimeStamp = 37001 }},
+		{name: "bid hash", modify: func(e *CommitmentStoredEvent) { e.BidHash = common.Hash{0xcd} }},
+		{name: "bid signature", modify: func(e *CommitmentStoredEvent) { e.BidSignature = []byte{0x01} }},
+		{name: "shared secret", modify: func(e *CommitmentStoredEvent) { e.SharedSecretKey = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every field is covered by the hash
+			event := *base
+			tt.modify(&event)
+			if hash(&event) == hash(base) {
+				t.Fatalf("hash unchanged when the %s changes", tt.name)
+			}
+		})
+	}
+}
+
+// fixedCommitment returns a commitment with its hash and signature written out, so the
+// verification does not depend on the signing helpers of this file. It is signed by the well-known
+// development key ac0974...ff80 of 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266, over a bid signed
+// by the key 59c699...1a5c, and its hashes were checked against a separate Keccak implementation.
+func fixedCommitment() *CommitmentStoredEvent {
+	return &CommitmentStoredEvent{
+		Commiter:            common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"),
+		Bid:                 42,
+		BlockNumber:         100,
+		BidHash:             common.HexToHash("0x1188756582c0c1541fac5716a6c8e7b6b166c7334b4d58d54e524074c48c6f3d"),
+		DecayStartTimeStamp: 1000,
+		DecayEndTimeStamp:   37000,
+		TxnHash:             "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		BidSignature:        common.FromHex("0x00395896db2af39d1b6b8f7b9ff09f273ab285c6fbaecea4d8d3afd97acf6d454b4c2420910e4676ae2152031e623871e715a9953ff2ec8ed91523282da097d81c"),
+		SharedSecretKey:     common.FromHex("0x1a2b3c"),
+		CommitmentHash:      common.HexToHash("0xd9d3bafef244ad95ca32d5188cc0821444a828a4ba0bf8fd725a0312ef634d99"),
+		CommitmentSignature: common.FromHex("0xc7bde48df691f26cd69e199229864377f4de9cc1ab9e66f335c66dfee13dcbe27954f81473bcfcf4b06910a6a853239844007daebc7de13796e90a18ed6bf9f41b"),
+	}
+}
+
+func TestVerifyCommitmentSignatureVectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(e *CommitmentStoredEvent)
+		want    bool
+		wantErr error
+	}{
+		{name: "as signed", want: true},
+		{name: "block number tampered", modify: func(e *CommitmentStoredEvent) { e.BlockNumber = 101 }, wantErr: ErrCommitmentHashMismatch},
+		{name: "bid hash tampered", modify: func(e *CommitmentStoredEvent) { e.BidHash[31] ^= 1 }, wantErr: ErrCommitmentHashMismatch},
+		{
+			// The hash is recomputed for the tampered field, so it is the signature that fails
+			name: "amount tampered with a matching hash",
+			modify: func(e *CommitmentStoredEvent) {
+				e.Bid = 4200
+				e.CommitmentHash = ComputeCommitmentHash(e.TxnHash, e.Bid, e.BlockNumber, e.DecayStartTimeStamp,
+					e.DecayEndTimeStamp, e.BidHash, e.BidSignature, e.SharedSecretKey)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := fixedCommitment()
+			if tt.modify != nil {
+				tt.modify(event)
+			}
+
+			got, err := VerifyCommitmentSignature(event, event.Commiter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyCommitmentSignature error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("VerifyCommitmentSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The bid hash of the vector is the one ComputeBidHash gives for its bid
+	event := fixedCommitment()
+	bidHash, err := ComputeBidHash(&pb.Bid{TxHashes: []string{event.TxnHash}, Amount: "42", BlockNumber: 100, DecayStartTimestamp: 1000, DecayEndTimestamp: 37000})
+	if err != nil || bidHash != event.BidHash {
+		t.Fatalf("ComputeBidHash = %x, %v, want %x", bidHash, err, event.BidHash)
+	}
+	signer, err := recoverSigner(common.Hash(event.CommitmentHash), event.CommitmentSignature)
+	if err != nil || signer != event.Commiter {
+		t.Fatalf("recovered signer %s, %v, want %s", signer, err, event.Commiter)
+	}
+}
@@ -0,0 +1,160 @@
+package mevcommit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultCompetitionBlocks and DefaultCompetitionSaturation are used when a CompetitionConfig
+// leaves them unset.
+const (
+	DefaultCompetitionBlocks     = 32
+	DefaultCompetitionSaturation = 4.0
+)
+
+// CompetitionConfig holds the settings of a CompetitionAwareBidStrategy.
+type CompetitionConfig struct {
+	MinAmount  *big.Int       // The amount bid without competition, in wei.
+	MaxAmount  *big.Int       // The amount bid at or above the saturation, in wei.
+	Self       common.Address // The bot's own bidder address, not counted as competition.
+	Blocks     uint64         // How many recent L1 blocks the estimate covers. Zero uses DefaultCompetitionBlocks.
+	Saturation float64        // Competing bidders per block at which the maximum is bid. Zero uses DefaultCompetitionSaturation.
+}
+
+// CompetitionAwareBidStrategy scales its bid between the bounds with the competition observed in
+// recent blocks, estimated from the CommitmentStored events of other bidders: it bids the minimum
+// when recent blocks had no other committed bidders and the maximum once they averaged
+// Saturation bidders per block.
+type CompetitionAwareBidStrategy struct {
+	mu         sync.Mutex
+	minAmount  *big.Int
+	maxAmount  *big.Int
+	self       common.Address
+	blocks     uint64
+	saturation float64
+	latest     uint64                                 // The highest L1 block a commitment was seen for.
+	bidders    map[uint64]map[[32]byte]common.Address // The competing bidder of each commitment, per L1 block.
+}
+
+// NewCompetitionAwareBidStrategy creates a CompetitionAwareBidStrategy. It bids the minimum until
+// commitments are fed to it with ObserveCommitment or Consume.
+//
+// Parameters:
+// - cfg: The CompetitionConfig struct containing the bounds and the estimate settings.
+//
+// Returns:
+// - A pointer to a CompetitionAwareBidStrategy, or an error if the configuration is invalid.
+func NewCompetitionAwareBidStrategy(cfg CompetitionConfig) (*CompetitionAwareBidStrategy, error) {
+	if err := validateBidBounds(cfg.MinAmount, cfg.MaxAmount); err != nil {
+		return nil, err
+	}
+	blocks := cfg.Blocks
+	if blocks == 0 {
+		blocks = DefaultCompetitionBlocks
+	}
+	saturation := cfg.Saturation
+	if saturation == 0 {
+		saturation = DefaultCompetitionSaturation
+	}
+	if !(saturation > 0) {
+		return nil, fmt.Errorf("competition saturation must be positive, got %v", saturation)
+	}
+	return &CompetitionAwareBidStrategy{
+		minAmount:  cfg.MinAmount,
+		maxAmount:  cfg.MaxAmount,
+		self:       cfg.Self,
+		blocks:     blocks,
+		saturation: saturation,
+		bidders:    make(map[uint64]map[[32]byte]common.Address),
+	}, nil
+}
+
+// ObserveCommitment updates the competition estimate with a commitment delivered by the
+// CommitmentListener. Commitments of the bot itself are ignored and reverted ones are forgotten.
+func (s *CompetitionAwareBidStrategy) ObserveCommitment(e CommitmentEvent) {
+	if e.Event.Bidder == s.self {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	block := e.Event.BlockNumber
+	if e.Status == CommitmentRemoved {
+		if commitments, ok := s.bidders[block]; ok {
+			delete(commitments, e.Event.CommitmentIndex)
+		}
+		return
+	}
+	if block+s.blocks <= s.latest {
+		return
+	}
+
+	// A commitment delivered as pending and then final is keyed by its index, so it counts once
+	if s.bidders[block] == nil {
+		s.bidders[block] = make(map[[32]byte]common.Address)
+	}
+	s.bidders[block][e.Event.CommitmentIndex] = e.Event.Bidder
+
+	if block > s.latest {
+		s.latest = block
+		for old := range s.bidders {
+			if old+s.blocks <= s.latest {
+				delete(s.bidders, old)
+			}
+		}
+	}
+}
+
+// Consume feeds the commitments received on events to the strategy until the context is
+// cancelled or the channel is closed.
+func (s *CompetitionAwareBidStrategy) Consume(ctx context.Context, events <-chan CommitmentEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			s.ObserveCommitment(e)
+		}
+	}
+}
+
+// Competition returns the average number of distinct competing bidders per block over the last
+// Blocks blocks. Blocks without commitments count as blocks without competition.
+func (s *CompetitionAwareBidStrategy) Competition() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, commitments := range s.bidders {
+		distinct := make(map[common.Address]struct{}, len(commitments))
+		for _, bidder := range commitments {
+			distinct[bidder] = struct{}{}
+		}
+		total += len(distinct)
+	}
+	return float64(total) / float64(s.blocks)
+}
+
+// BidAmount returns the minimum plus the share of the span between the bounds that the
+// competition is of the saturation, capped at the maximum.
+func (s *CompetitionAwareBidStrategy) BidAmount(targetBlock uint64) (*big.Int, error) {
+	share := s.Competition() / s.saturation
+	if share > 1 {
+		share = 1
+	}
+
+	span := new(big.Float).SetInt(new(big.Int).Sub(s.maxAmount, s.minAmount))
+	offset, _ := span.Mul(span, big.NewFloat(share)).Int(nil)
+	return offset.Add(offset, s.minAmount), nil
+}
+
+// Observe is a no-op, the competition strategy adapts to the commitments of other bidders rather
+// than to its own outcomes.
+func (s *CompetitionAwareBidStrategy) Observe(targetBlock uint64, accepted bool) {}
@@ -0,0 +1,150 @@
+package mevcommit
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// commitment returns a CommitmentEvent of bidder for block with the given index and status.
+func commitment(bidder common.Address, block uint64, index byte, status CommitmentStatus) CommitmentEvent {
+	return CommitmentEvent{
+		Event:  CommitmentStoredEvent{Bidder: bidder, BlockNumber: block, CommitmentIndex: [32]byte{index}},
+		Status: status,
+	}
+}
+
+func TestCompetitionAwareBidStrategy(t *testing.T) {
+	self, a, b, c := common.Address{0x5e}, common.Address{0xa}, common.Address{0xb}, common.Address{0xc}
+	tests := []struct {
+		name            string
+		events          []CommitmentEvent
+		wantCompetition float64
+		wantAmount      int64
+	}{
+		{name: "no commitments", wantAmount: 100},
+		{name: "own commitments", events: []CommitmentEvent{commitment(self, 10, 1, CommitmentFinal)}, wantAmount: 100},
+		{name: "one bidder", events: []CommitmentEvent{commitment(a, 10, 1, CommitmentFinal)}, wantCompetition: 0.25, wantAmount: 150},
+		{
+			name:            "same bidder counts once per block",
+			events:          []CommitmentEvent{commitment(a, 10, 1, CommitmentFinal), commitment(a, 10, 2, CommitmentFinal)},
+			wantCompetition: 0.25,
+			wantAmount:      150,
+		},
+		{
+			name:            "pending then final counts once",
+			events:          []CommitmentEvent{commitment(a, 10, 1, CommitmentPending), commitment(a, 10, 1, CommitmentFinal)},
+			wantCompetition: 0.25,
+			wantAmount:      150,
+		},
+		{
+			name:   "reverted commitment forgotten",
+			events: []CommitmentEvent{commitment(a, 10, 1, CommitmentFinal), commitment(a, 10, 1, CommitmentRemoved)},
+			// The block stays known without commitments
+			wantAmount: 100,
+		},
+		{
+			name: "saturated",
+			events: []CommitmentEvent{
+				commitment(a, 10, 1, CommitmentFinal), commitment(b, 10, 2, CommitmentFinal),
+				commitment(a, 11, 3, CommitmentFinal), commitment(b, 11, 4, CommitmentFinal),
+				commitment(a, 12, 5, CommitmentFinal), commitment(b, 12, 6, CommitmentFinal),
+				commitment(a, 13, 7, CommitmentFinal), commitment(b, 13, 8, CommitmentFinal),
+			},
+			wantCompetition: 2,
+			wantAmount:      500,
+		},
+		{
+			name: "capped at the maximum",
+			events: []CommitmentEvent{
+				commitment(a, 10, 1, CommitmentFinal), commitment(b, 10, 2, CommitmentFinal), commitment(c, 10, 3, CommitmentFinal),
+				commitment(a, 11, 4, CommitmentFinal), commitment(b, 11, 5, CommitmentFinal), commitment(c, 11, 6, CommitmentFinal),
+				commitment(a, 12, 7, CommitmentFinal), commitment(b, 12, 8, CommitmentFinal), commitment(c, 12, 9, CommitmentFinal),
+			},
+			wantCompetition: 2.25,
+			wantAmount:      500,
+		},
+		{
+			name:            "old blocks expire",
+			events:          []CommitmentEvent{commitment(a, 10, 1, CommitmentFinal), commitment(b, 14, 2, CommitmentFinal)},
+			wantCompetition: 0.25,
+			wantAmount:      150,
+		},
+		{
+			name:            "late commitment for an expired block",
+			events:          []CommitmentEvent{commitment(b, 14, 1, CommitmentFinal), commitment(a, 10, 2, CommitmentFinal)},
+			wantCompetition: 0.25,
+			wantAmount:      150,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewCompetitionAwareBidStrategy(CompetitionConfig{MinAmount: big.NewInt(100), MaxAmount: big.NewInt(500), Self: self, Blocks: 4, Saturation: 2})
+			if err != nil {
+				t.Fatalf("NewCompetitionAwareBidStrategy: %v", err)
+			}
+			for _, e := range tt.events {
+				s.ObserveCommitment(e)
+			}
+			if got := s.Competition(); got != tt.wantCompetition {
+				t.Fatalf("Competition = %v, want %v", got, tt.wantCompetition)
+			}
+			amount, err := s.BidAmount(20)
+			if err != nil {
+				t.Fatalf("BidAmount: %v", err)
+			}
+			if amount.Cmp(big.NewInt(tt.wantAmount)) != 0 {
+				t.Fatalf("BidAmount = %s, want %d", amount, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestNewCompetitionAwareBidStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CompetitionConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: CompetitionConfig{MinAmount: big.NewInt(1), MaxAmount: big.NewInt(2)}},
+		{name: "equal bounds", cfg: CompetitionConfig{MinAmount: big.NewInt(2), MaxAmount: big.NewInt(2), Saturation: 0.5}},
+		{name: "reversed bounds", cfg: CompetitionConfig{MinAmount: big.NewInt(2), MaxAmount: big.NewInt(1)}, wantErr: true},
+		{name: "negative saturation", cfg: CompetitionConfig{MinAmount: big.NewInt(1), MaxAmount: big.NewInt(2), Saturation: -1}, wantErr: true},
+		{name: "NaN saturation", cfg: CompetitionConfig{MinAmount: big.NewInt(1), MaxAmount: big.NewInt(2), Saturation: math.NaN()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewCompetitionAwareBidStrategy(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCompetitionAwareBidStrategy error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && tt.cfg.Blocks == 0 && s.blocks != DefaultCompetitionBlocks {
+				t.Fatalf("blocks = %d, want %d", s.blocks, DefaultCompetitionBlocks)
+			}
+		})
+	}
+}
+
+func TestCompetitionAwareBidStrategyConsume(t *testing.T) {
+	s, err := NewCompetitionAwareBidStrategy(CompetitionConfig{MinAmount: big.NewInt(1), MaxAmount: big.NewInt(2), Blocks: 1})
+	if err != nil {
+		t.Fatalf("NewCompetitionAwareBidStrategy: %v", err)
+	}
+	events := make(chan CommitmentEvent, 2)
+	events <- commitment(common.Address{0xa}, 10, 1, CommitmentFinal)
+	events <- commitment(common.Address{0xb}, 10, 2, CommitmentFinal)
+	close(events)
+
+	// Consume returns once the channel is closed, having observed every event
+	s.Consume(context.Background(), events)
+	if got := s.Competition(); got != 2 {
+		t.Fatalf("Competition = %v, want 2", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Consume(ctx, make(chan CommitmentEvent))
+}
@@ -4,13 +4,12 @@ package mevcommit
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
 	"os"
 	"strings"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -21,11 +20,26 @@ import (
 // Contract addresses used within the mev-commit protocol.
 const (
 	// latest contracts as of v0.6.1
-	bidderRegistryAddress         = "0x401B3287364f95694c43ACA3252831cAc02e5C41"
-	blockTrackerAddress           = "0x7538F3AaA07dA1990486De21A0B438F55e9639e4"
-	PreconfManagerAddress 		  = "0x9433bCD9e89F923ce587f7FA7E39e120E93eb84D"
+	bidderRegistryAddress = "0x401B3287364f95694c43ACA3252831cAc02e5C41"
+	blockTrackerAddress   = "0x7538F3AaA07dA1990486De21A0B438F55e9639e4"
+	PreconfManagerAddress = "0x9433bCD9e89F923ce587f7FA7E39e120E93eb84D"
 )
 
+// ErrWindowClosed is returned by DepositIntoWindow for a window that has already ended.
+var ErrWindowClosed = errors.New("bidding window is closed")
+
+// ProtocolVersion is the mev-commit release the contract addresses belong to.
+const ProtocolVersion = "v0.6.1"
+
+// ContractAddresses returns the mev-commit contract addresses used by this package, keyed by contract name.
+func ContractAddresses() map[string]string {
+	return map[string]string{
+		"BidderRegistry": bidderRegistryAddress,
+		"BlockTracker":   blockTrackerAddress,
+		"PreconfManager": PreconfManagerAddress,
+	}
+}
+
 // CommitmentStoredEvent represents the data structure for the CommitmentStored event.
 type CommitmentStoredEvent struct {
 	CommitmentIndex     [32]byte
@@ -54,13 +68,13 @@ type CommitmentStoredEvent struct {
 func LoadABI(filePath string) (abi.ABI, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		log.Println("Failed to load ABI file:", err)
+		logger.Error("Failed to load ABI file", "err", err)
 		return abi.ABI{}, err
 	}
 
 	parsedABI, err := abi.JSON(strings.NewReader(string(data)))
 	if err != nil {
-		log.Println("Failed to parse ABI file:", err)
+		logger.Error("Failed to parse ABI file", "err", err)
 		return abi.ABI{}, err
 	}
 
@@ -78,7 +92,7 @@ func WindowHeight(client *ethclient.Client) (*big.Int, error) {
 	// Load the BlockTracker contract ABI
 	blockTrackerABI, err := LoadABI("abi/BlockTracker.abi")
 	if err != nil {
-		log.Println("Failed to load ABI file:", err)
+		logger.Error("Failed to load ABI file", "err", err)
 		return nil, err
 	}
 
@@ -86,17 +100,19 @@ func WindowHeight(client *ethclient.Client) (*big.Int, error) {
 	blockTrackerContract := bind.NewBoundContract(common.HexToAddress(blockTrackerAddress), blockTrackerABI, client, client, client)
 
 	// Call the getCurrentWindow function to retrieve the current window height
+	opts, cancel := callOpts()
+	defer cancel()
 	var currentWindowResult []interface{}
-	err = blockTrackerContract.Call(nil, &currentWindowResult, "getCurrentWindow")
+	err = blockTrackerContract.Call(opts, &currentWindowResult, "getCurrentWindow")
 	if err != nil {
-		log.Println("Failed to get current window:", err)
+		logger.Error("Failed to get current window", "err", err)
 		return nil, err
 	}
 
 	// Extract the current window as *big.Int
 	currentWindow, ok := currentWindowResult[0].(*big.Int)
 	if !ok {
-		log.Println("Failed to convert current window to *big.Int")
+		logger.Error("Failed to convert current window to *big.Int")
 		return nil, fmt.Errorf("conversion to *big.Int failed")
 	}
 
@@ -121,8 +137,10 @@ func GetMinDeposit(client *ethclient.Client) (*big.Int, error) {
 	bidderRegistryContract := bind.NewBoundContract(common.HexToAddress(bidderRegistryAddress), bidderRegistryABI, client, client, client)
 
 	// Call the minDeposit function to get the minimum deposit amount
+	opts, cancel := callOpts()
+	defer cancel()
 	var minDepositResult []interface{}
-	err = bidderRegistryContract.Call(nil, &minDepositResult, "minDeposit")
+	err = bidderRegistryContract.Call(opts, &minDepositResult, "minDeposit")
 	if err != nil {
 		return nil, fmt.Errorf("failed to call minDeposit function: %v", err)
 	}
@@ -137,6 +155,8 @@ func GetMinDeposit(client *ethclient.Client) (*big.Int, error) {
 }
 
 // DepositIntoWindow deposits the minimum bid amount into the specified bidding window.
+// Windows before the current one are closed, so they are rejected with ErrWindowClosed before
+// any gas is spent; ForceDepositIntoWindow skips that check.
 //
 // Parameters:
 // - client: The Ethereum client instance.
@@ -144,8 +164,30 @@ func GetMinDeposit(client *ethclient.Client) (*big.Int, error) {
 // - authAcct: The authenticated account struct containing transaction authorization.
 //
 // Returns:
-// - The transaction object if successful, or an error if the transaction fails.
+// - The transaction object if successful, or an error if the window is closed or the transaction fails.
 func DepositIntoWindow(client *ethclient.Client, depositWindow *big.Int, authAcct *AuthAcct) (*types.Transaction, error) {
+	currentWindow, err := WindowHeight(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current window: %w", err)
+	}
+	if depositWindow.Cmp(currentWindow) < 0 {
+		return nil, fmt.Errorf("%w: window %s is before the current window %s", ErrWindowClosed, depositWindow, currentWindow)
+	}
+	return ForceDepositIntoWindow(client, depositWindow, authAcct)
+}
+
+// ForceDepositIntoWindow deposits the minimum bid amount into the specified bidding window like
+// DepositIntoWindow, without checking that the window is still open. It is meant for contract
+// versions whose window semantics differ from the ones DepositIntoWindow checks for.
+//
+// Parameters:
+// - client: The Ethereum client instance.
+// - depositWindow: The window into which the deposit should be made.
+// - authAcct: The authenticated account struct containing transaction authorization.
+//
+// Returns:
+// - The transaction object if successful, or an error if the transaction fails.
+func ForceDepositIntoWindow(client *ethclient.Client, depositWindow *big.Int, authAcct *AuthAcct) (*types.Transaction, error) {
 	// Load the BidderRegistry contract ABI
 	bidderRegistryABI, err := LoadABI("abi/BidderRegistry.abi")
 	if err != nil {
@@ -178,7 +220,7 @@ func DepositIntoWindow(client *ethclient.Client, depositWindow *big.Int, authAcc
 
 	// Check the transaction status
 	if receipt.Status == 1 {
-		fmt.Println("Transaction successful")
+		logger.Info("Transaction successful", "tx", tx.Hash())
 		return tx, nil
 	} else {
 		return nil, fmt.Errorf("transaction failed")
@@ -205,8 +247,10 @@ func GetDepositAmount(client *ethclient.Client, address common.Address, window b
 	bidderRegistryContract := bind.NewBoundContract(common.HexToAddress(bidderRegistryAddress), bidderRegistryABI, client, client, client)
 
 	// Call the getDeposit function to retrieve the deposit amount
+	opts, cancel := callOpts()
+	defer cancel()
 	var depositResult []interface{}
-	err = bidderRegistryContract.Call(nil, &depositResult, "getDeposit", address, window)
+	err = bidderRegistryContract.Call(opts, &depositResult, "getDeposit", address, &window)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call getDeposit function: %v", err)
 	}
@@ -253,7 +297,7 @@ func WithdrawFromWindow(client *ethclient.Client, authAcct *AuthAcct, window *bi
 
 	// Check the withdrawal transaction status
 	if withdrawalReceipt.Status == 1 {
-		fmt.Println("Withdrawal successful")
+		logger.Info("Withdrawal successful", "tx", withdrawalTx.Hash())
 		return withdrawalTx, nil
 	} else {
 		return nil, fmt.Errorf("withdrawal failed")
@@ -263,77 +307,64 @@ func WithdrawFromWindow(client *ethclient.Client, authAcct *AuthAcct, window *bi
 // ListenForCommitmentStoredEvent listens for the CommitmentStored event on the Ethereum blockchain.
 // This function will print event details when the CommitmentStored event is detected.
 //
+// Each commitment is printed once; logs for an already printed commitment index are skipped,
+// and commitments reverted by a chain reorg are logged as such and printed again if re-included.
+// Use ListenForCommitmentStoredEventWithConfig to configure the de-duplication.
+//
 // Parameters:
 // - client: The Ethereum client instance.
 //
-// Note: The event listener is not currently functioning correctly (as per the TODO comment).
+// It returns once the subscription fails; the failure is logged.
 func ListenForCommitmentStoredEvent(client *ethclient.Client) {
-	// Load the PreConfCommitmentStore contract ABI
-	contractAbi, err := LoadABI("abi/PreConfCommitmentStore.abi")
-	if err != nil {
-		log.Fatalf("Failed to load contract ABI: %v", err)
-	}
+	ListenForCommitmentStoredEventWithConfig(client, ListenerConfig{})
+}
 
-	// Subscribe to new block headers
-	headers := make(chan *types.Header)
-	sub, err := client.SubscribeNewHead(context.Background(), headers)
+// ListenForCommitmentStoredEventWithConfig prints the CommitmentStored events like
+// ListenForCommitmentStoredEvent, with a listener configured by cfg. With cfg.NoReemit set, a
+// commitment reverted by a reorg is not printed again if re-included.
+//
+// Parameters:
+// - client: The Ethereum client instance.
+// - cfg: The ListenerConfig struct containing the de-duplicator and its reorg behavior.
+//
+// It returns once the subscription fails; the failure is logged.
+func ListenForCommitmentStoredEventWithConfig(client *ethclient.Client, cfg ListenerConfig) {
+	listener, err := NewCommitmentListener(client, cfg)
 	if err != nil {
-		log.Fatalf("Failed to subscribe to new head: %v", err)
+		logger.Error("Failed to create commitment listener", "err", err)
+		return
 	}
 
-	// Listen for new block headers and filter logs for the CommitmentStored event
-	for {
-		select {
-		case err := <-sub.Err():
-			log.Fatalf("Error with header subscription: %v", err)
-		case header := <-headers:
-			query := ethereum.FilterQuery{
-				Addresses: []common.Address{common.HexToAddress(PreconfManagerAddress)},
-				FromBlock: header.Number,
-				ToBlock:   header.Number,
-			}
-
-			logs := make(chan types.Log)
-			subLogs, err := client.SubscribeFilterLogs(context.Background(), query, logs)
-			if err != nil {
-				log.Printf("Failed to subscribe to logs: %v", err)
-				continue
-			}
-
-			// Process incoming logs
-			for {
-				select {
-				case err := <-subLogs.Err():
-					log.Printf("Error with log subscription: %v", err)
-					break
-				case vLog := <-logs:
-					var event CommitmentStoredEvent
-
-					// Unpack the log data into the CommitmentStoredEvent struct
-					err := contractAbi.UnpackIntoInterface(&event, "CommitmentStored", vLog.Data)
-					if err != nil {
-						log.Printf("Failed to unpack log data: %v", err)
-						continue
-					}
-
-					// Print event details
-					fmt.Printf("CommitmentStored Event: \n")
-					fmt.Printf("CommitmentIndex: %x\n", event.CommitmentIndex)
-					fmt.Printf("Bidder: %s\n", event.Bidder.Hex())
-					fmt.Printf("Commiter: %s\n", event.Commiter.Hex())
-					fmt.Printf("Bid: %d\n", event.Bid)
-					fmt.Printf("BlockNumber: %d\n", event.BlockNumber)
-					fmt.Printf("BidHash: %x\n", event.BidHash)
-					fmt.Printf("DecayStartTimeStamp: %d\n", event.DecayStartTimeStamp)
-					fmt.Printf("DecayEndTimeStamp: %d\n", event.DecayEndTimeStamp)
-					fmt.Printf("TxnHash: %s\n", event.TxnHash)
-					fmt.Printf("CommitmentHash: %x\n", event.CommitmentHash)
-					fmt.Printf("BidSignature: %x\n", event.BidSignature)
-					fmt.Printf("CommitmentSignature: %x\n", event.CommitmentSignature)
-					fmt.Printf("DispatchTimestamp: %d\n", event.DispatchTimestamp)
-					fmt.Printf("SharedSecretKey: %x\n", event.SharedSecretKey)
-				}
-			}
+	events := make(chan CommitmentEvent)
+	go func() {
+		defer close(events)
+		if err := listener.Run(context.Background(), events); err != nil {
+			logger.Error("Error with log subscription", "err", err)
 		}
+	}()
+
+	for e := range events {
+		event := e.Event
+		if e.Status == CommitmentRemoved {
+			logger.Warn("CommitmentStored event reverted by reorg", "commitmentIndex", common.Hash(event.CommitmentIndex), "tx", e.Log.TxHash)
+			continue
+		}
+
+		// Print event details
+		fmt.Printf("CommitmentStored Event: \n")
+		fmt.Printf("CommitmentIndex: %x\n", event.CommitmentIndex)
+		fmt.Printf("Bidder: %s\n", event.Bidder.Hex())
+		fmt.Printf("Commiter: %s\n", event.Commiter.Hex())
+		fmt.Printf("Bid: %d\n", event.Bid)
+		fmt.Printf("BlockNumber: %d\n", event.BlockNumber)
+		fmt.Printf("BidHash: %x\n", event.BidHash)
+		fmt.Printf("DecayStartTimeStamp: %d\n", event.DecayStartTimeStamp)
+		fmt.Printf("DecayEndTimeStamp: %d\n", event.DecayEndTimeStamp)
+		fmt.Printf("TxnHash: %s\n", event.TxnHash)
+		fmt.Printf("CommitmentHash: %x\n", event.CommitmentHash)
+		fmt.Printf("BidSignature: %x\n", event.BidSignature)
+		fmt.Printf("CommitmentSignature: %x\n", event.CommitmentSignature)
+		fmt.Printf("DispatchTimestamp: %d\n", event.DispatchTimestamp)
+		fmt.Printf("SharedSecretKey: %x\n", event.SharedSecretKey)
 	}
 }
@@ -0,0 +1,60 @@
+package mevcommit
+
+import (
+	"fmt"
+	"time"
+)
+
+// DecayWindowFromPercent converts a decay window given as percentages of the time left until the
+// target block into the absolute decay timestamps SendBid expects. With 0 and 100 the bid decays
+// from now until the target block's estimated timestamp; with 50 and 100 it keeps its full value
+// for the first half of that time.
+//
+// Parameters:
+// - now: The time the bid is made.
+// - targetTime: The estimated timestamp of the target block, after now.
+// - decayStartPct: When the decay starts, as a percentage (0-100) of the time until targetTime.
+// - decayEndPct: When the decay ends, as a percentage (0-100) of the time until targetTime, above decayStartPct.
+//
+// Returns:
+// - The decay start and end timestamps in milliseconds, or an error if the percentages or times are invalid.
+func DecayWindowFromPercent(now, targetTime time.Time, decayStartPct, decayEndPct float64) (int64, int64, error) {
+	if !(decayStartPct >= 0 && decayStartPct <= 100) {
+		return 0, 0, fmt.Errorf("decay start must be between 0 and 100 percent, got %v", decayStartPct)
+	}
+	if !(decayEndPct >= 0 && decayEndPct <= 100) {
+		return 0, 0, fmt.Errorf("decay end must be between 0 and 100 percent, got %v", decayEndPct)
+	}
+	if decayEndPct <= decayStartPct {
+		return 0, 0, fmt.Errorf("decay end %v%% must be after decay start %v%%", decayEndPct, decayStartPct)
+	}
+	if !targetTime.After(now) {
+		return 0, 0, fmt.Errorf("target time %s is not after %s", targetTime.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano))
+	}
+
+	untilTarget := targetTime.Sub(now)
+	start := now.Add(time.Duration(float64(untilTarget) * decayStartPct / 100))
+	end := now.Add(time.Duration(float64(untilTarget) * decayEndPct / 100))
+	return start.UnixMilli(), end.UnixMilli(), nil
+}
+
+// ValidateDecayWindow checks the decay window of a bid. A bid keeps its full amount until
+// decayStart, then loses value linearly until it is worth nothing at decayEnd; providers compute
+// that linear decay themselves. The bidder API's Bid message carries only the two timestamps and
+// no decay type or curve, so the shape of the decay cannot be chosen, only its window.
+//
+// Parameters:
+// - decayStart: The decay start timestamp in milliseconds.
+// - decayEnd: The decay end timestamp in milliseconds.
+//
+// Returns:
+// - An error if a timestamp is negative or the window does not end after it starts, or nil.
+func ValidateDecayWindow(decayStart, decayEnd int64) error {
+	if decayStart < 0 || decayEnd < 0 {
+		return fmt.Errorf("decay timestamps must not be negative, got %d and %d", decayStart, decayEnd)
+	}
+	if decayEnd <= decayStart {
+		return fmt.Errorf("decay ends at %d, not after it starts at %d", decayEnd, decayStart)
+	}
+	return nil
+}
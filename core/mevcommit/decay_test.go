@@ -0,0 +1,70 @@
+package mevcommit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValidateDecayWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int64
+		wantErr    bool
+	}{
+		{name: "window", start: 1000, end: 37000},
+		{name: "from zero", start: 0, end: 1},
+		{name: "empty", start: 1000, end: 1000, wantErr: true},
+		{name: "reversed", start: 2000, end: 1000, wantErr: true},
+		{name: "negative start", start: -1, end: 1000, wantErr: true},
+		{name: "negative end", start: -2000, end: -1000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDecayWindow(tt.start, tt.end); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateDecayWindow(%d, %d) = %v, want error %v", tt.start, tt.end, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecayWindowFromPercent(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	target := now.Add(10 * time.Second)
+	tests := []struct {
+		name               string
+		target             time.Time
+		startPct, endPct   float64
+		wantStart, wantEnd int64
+		wantErr            bool
+	}{
+		{name: "whole time", target: target, startPct: 0, endPct: 100, wantStart: 1_000_000, wantEnd: 1_010_000},
+		{name: "second half", target: target, startPct: 50, endPct: 100, wantStart: 1_005_000, wantEnd: 1_010_000},
+		{name: "middle", target: target, startPct: 25, endPct: 75, wantStart: 1_002_500, wantEnd: 1_007_500},
+		{name: "start above 100", target: target, startPct: 101, endPct: 100, wantErr: true},
+		{name: "end below 0", target: target, startPct: 0, endPct: -1, wantErr: true},
+		{name: "end before start", target: target, startPct: 60, endPct: 40, wantErr: true},
+		{name: "fractional percentages", target: target, startPct: 12.5, endPct: 87.5, wantStart: 1_001_250, wantEnd: 1_008_750},
+		{name: "equal percentages", target: target, startPct: 50, endPct: 50, wantErr: true},
+		{name: "NaN start", target: target, startPct: math.NaN(), endPct: 100, wantErr: true},
+		{name: "NaN end", target: target, startPct: 0, endPct: math.NaN(), wantErr: true},
+		{name: "target passed", target: now, startPct: 0, endPct: 100, wantErr: true},
+		{name: "target before now", target: now.Add(-time.Second), startPct: 0, endPct: 100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := DecayWindowFromPercent(now, tt.target, tt.startPct, tt.endPct)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecayWindowFromPercent error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (start != tt.wantStart || end != tt.wantEnd) {
+				t.Fatalf("DecayWindowFromPercent = %d, %d, want %d, %d", start, end, tt.wantStart, tt.wantEnd)
+			}
+			if err == nil {
+				if err := ValidateDecayWindow(start, end); err != nil {
+					t.Fatalf("window is not valid: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package mevcommit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultDedupCapacity is the number of commitment indexes remembered when no capacity is given.
+const DefaultDedupCapacity = 4096
+
+// DefaultBidDedupTTL is how long a BidDeduper remembers a bid transaction when no TTL is given,
+// one bidding window of DefaultBlocksPerWindow blocks.
+const DefaultBidDedupTTL = DefaultBlocksPerWindow * 12 * time.Second
+
+// lruSet is a bounded set that evicts its least recently added key when full.
+type lruSet[K comparable] struct {
+	capacity int
+	order    *list.List          // Keys, most recently added at the front.
+	entries  map[K]*list.Element // Index into order by key.
+}
+
+// newLRUSet creates an lruSet holding at most capacity keys.
+func newLRUSet[K comparable](capacity int) *lruSet[K] {
+	return &lruSet[K]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[K]*list.Element),
+	}
+}
+
+// add inserts key and reports whether it was not already present.
+func (s *lruSet[K]) add(key K) bool {
+	if elem, ok := s.entries[key]; ok {
+		s.order.MoveToFront(elem)
+		return false
+	}
+
+	s.entries[key] = s.order.PushFront(key)
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(K))
+	}
+	return true
+}
+
+// contains reports whether key is in the set.
+func (s *lruSet[K]) contains(key K) bool {
+	_, ok := s.entries[key]
+	return ok
+}
+
+// remove deletes key from the set if present.
+func (s *lruSet[K]) remove(key K) {
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+}
+
+// CommitmentDeduper tracks the commitment indexes already emitted by the event listener
+// so that each commitment is reported once, even when its log is delivered again after a
+// re-subscription. A commitment reverted by a reorg is forgotten when its removal is reported,
+// so it is emitted again if it is re-included, unless ObserveOnce is used. Memory is bounded by
+// evicting the least recently seen index.
+type CommitmentDeduper struct {
+	mu       sync.Mutex
+	seen     *lruSet[[32]byte]
+	reverted *lruSet[[32]byte] // Commitments whose removal ObserveOnce reported.
+}
+
+// NewCommitmentDeduper creates a CommitmentDeduper.
+//
+// Parameters:
+// - capacity: The maximum number of commitment indexes to remember. Non-positive values use DefaultDedupCapacity.
+//
+// Returns:
+// - A pointer to a CommitmentDeduper.
+func NewCommitmentDeduper(capacity int) *CommitmentDeduper {
+	if capacity <= 0 {
+		capacity = DefaultDedupCapacity
+	}
+	return &CommitmentDeduper{seen: newLRUSet[[32]byte](capacity), reverted: newLRUSet[[32]byte](capacity)}
+}
+
+// Observe records a commitment log and reports whether it should be emitted.
+//
+// Parameters:
+// - index: The commitment index of the log.
+// - removed: Whether the log was reverted by a chain reorg.
+//
+// Returns:
+// - For included logs, true if the commitment has not been emitted before.
+// - For removed logs, true if the commitment was emitted and its removal should be reported. It is then forgotten, so it is emitted again when re-included.
+func (d *CommitmentDeduper) Observe(index [32]byte, removed bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if removed {
+		if !d.seen.contains(index) {
+			return false
+		}
+		d.seen.remove(index)
+		return true
+	}
+	return d.seen.add(index)
+}
+
+// ObserveOnce records a commitment log like Observe, but never forgets an emitted commitment, so
+// a commitment reverted by a reorg is not emitted again if it is re-included.
+//
+// Parameters:
+// - index: The commitment index of the log.
+// - removed: Whether the log was reverted by a chain reorg.
+//
+// Returns:
+// - For included logs, true if the commitment has not been emitted before.
+// - For removed logs, true if the commitment was emitted and its removal has not been reported before.
+func (d *CommitmentDeduper) ObserveOnce(index [32]byte, removed bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if removed {
+		if !d.seen.contains(index) {
+			return false
+		}
+		return d.reverted.add(index)
+	}
+	return d.seen.add(index)
+}
+
+// bidEntry is a transaction hash remembered by a BidDeduper, with the time it was bid on.
+type bidEntry struct {
+	hash common.Hash
+	at   time.Time
+}
+
+// BidDeduper remembers the transactions recently bid on so the same transaction is not bid on
+// twice, for example by several workers. A transaction is forgotten once ttl has passed since
+// its bid, or once capacity newer transactions were bid on.
+type BidDeduper struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time              // Source of the current time.
+	order    *list.List                    // Entries, oldest bid at the front.
+	entries  map[common.Hash]*list.Element // Index into order by transaction hash.
+}
+
+// NewBidDeduper creates a BidDeduper.
+//
+// Parameters:
+// - ttl: How long a transaction is remembered after its bid. Non-positive values use DefaultBidDedupTTL.
+// - capacity: The maximum number of transactions to remember. Non-positive values use DefaultDedupCapacity.
+//
+// Returns:
+// - A pointer to a BidDeduper.
+func NewBidDeduper(ttl time.Duration, capacity int) *BidDeduper {
+	if ttl <= 0 {
+		ttl = DefaultBidDedupTTL
+	}
+	if capacity <= 0 {
+		capacity = DefaultDedupCapacity
+	}
+	return &BidDeduper{
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[common.Hash]*list.Element),
+	}
+}
+
+// Observe records a bid on a transaction and reports whether the transaction was not already
+// bid on within the TTL. A false result means the bid is a duplicate and should be skipped.
+//
+// Parameters:
+// - txHash: The hash of the transaction bid on.
+//
+// Returns:
+// - True if the bid should be submitted.
+func (d *BidDeduper) Observe(txHash common.Hash) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Forget expired bids, entries are in bid order so they are all at the front
+	now := d.now()
+	for front := d.order.Front(); front != nil; front = d.order.Front() {
+		entry := front.Value.(bidEntry)
+		if now.Sub(entry.at) < d.ttl {
+			break
+		}
+		d.order.Remove(front)
+		delete(d.entries, entry.hash)
+	}
+
+	if _, ok := d.entries[txHash]; ok {
+		return false
+	}
+
+	d.entries[txHash] = d.order.PushBack(bidEntry{hash: txHash, at: now})
+	if d.order.Len() > d.capacity {
+		oldest := d.order.Front()
+		d.order.Remove(oldest)
+		delete(d.entries, oldest.Value.(bidEntry).hash)
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package mevcommit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCommitmentDeduper(t *testing.T) {
+	a, b, c := [32]byte{1}, [32]byte{2}, [32]byte{3}
+	type step struct {
+		index   [32]byte
+		removed bool
+		want    bool
+	}
+	tests := []struct {
+		name     string
+		capacity int
+		once     bool // Whether the steps use ObserveOnce.
+		steps    []step
+	}{
+		{
+			name:  "duplicates",
+			steps: []step{{index: a, want: true}, {index: a}, {index: b, want: true}, {index: a}, {index: b}},
+		},
+		{
+			name: "reorg",
+			steps: []step{
+				{index: a, want: true},
+				{index: a, removed: true, want: true}, // Reported once, then forgotten.
+				{index: a, removed: true},
+				{index: a, want: true}, // Re-included.
+				{index: a},
+			},
+		},
+		{
+			name:  "removal of an unseen commitment",
+			steps: []step{{index: a, removed: true}, {index: a, want: true}},
+		},
+		{
+			name: "reorg without re-emitting",
+			once: true,
+			steps: []step{
+				{index: a, want: true},
+				{index: a, removed: true, want: true}, // Reported once, but still remembered.
+				{index: a, removed: true},
+				{index: a}, // Re-included.
+				{index: b, removed: true},
+				{index: b, want: true},
+			},
+		},
+		{
+			name:     "eviction",
+			capacity: 2,
+			steps: []step{
+				{index: a, want: true},
+				{index: b, want: true},
+				{index: a},             // Seen again, so b is now the oldest.
+				{index: c, want: true}, // Evicts b.
+				{index: a},
+				{index: b, want: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewCommitmentDeduper(tt.capacity)
+			observe := d.Observe
+			if tt.once {
+				observe = d.ObserveOnce
+			}
+			for i, s := range tt.steps {
+				if got := observe(s.index, s.removed); got != s.want {
+					t.Fatalf("step %d: Observe(%x, %v) = %v, want %v", i, s.index[:1], s.removed, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBidDeduper(t *testing.T) {
+	a, b, c := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	type step struct {
+		after  time.Duration // Time passed since the previous step.
+		txHash common.Hash
+		want   bool
+	}
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		capacity int
+		steps    []step
+	}{
+		{
+			name:  "duplicates",
+			ttl:   time.Minute,
+			steps: []step{{txHash: a, want: true}, {txHash: a}, {txHash: b, want: true}, {txHash: a}, {txHash: b}},
+		},
+		{
+			name: "expiry",
+			ttl:  time.Minute,
+			steps: []step{
+				{txHash: a, want: true},
+				{after: 59 * time.Second, txHash: a},
+				{after: time.Second, txHash: a, want: true}, // Expired exactly at the TTL.
+				{after: 30 * time.Second, txHash: a},
+			},
+		},
+		{
+			name: "duplicate does not extend the ttl",
+			ttl:  time.Minute,
+			steps: []step{
+				{txHash: a, want: true},
+				{after: 40 * time.Second, txHash: a},
+				{after: 20 * time.Second, txHash: a, want: true},
+			},
+		},
+		{
+			name:     "eviction",
+			ttl:      time.Minute,
+			capacity: 2,
+			steps: []step{
+				{txHash: a, want: true},
+				{txHash: b, want: true},
+				{txHash: c, want: true}, // Evicts a.
+				{txHash: b},
+				{txHash: a, want: true},
+			},
+		},
+		{
+			name:  "default ttl",
+			steps: []step{{txHash: a, want: true}, {after: DefaultBidDedupTTL - time.Second, txHash: a}, {after: time.Second, txHash: a, want: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewBidDeduper(tt.ttl, tt.capacity)
+			now := time.Unix(1700000000, 0)
+			d.now = func() time.Time { return now }
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				if got := d.Observe(s.txHash); got != s.want {
+					t.Fatalf("step %d: Observe(%x) = %v, want %v", i, s.txHash[:1], got, s.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultDepositPollInterval is how often WaitForSufficientDeposit checks the deposit.
+const DefaultDepositPollInterval = 5 * time.Second
+
+// DefaultWindowPollInterval is how often KeepNextWindowFunded checks for a new bidding window.
+const DefaultWindowPollInterval = 30 * time.Second
+
+// ErrInsufficientDeposit is returned by WaitForSufficientDeposit when the deposit is still
+// below the minimum once the timeout expires.
+var ErrInsufficientDeposit = errors.New("bidder deposit below the minimum deposit")
+
+// WaitForSufficientDeposit polls the BidderRegistry until the address has at least the minimum
+// deposit in the current bidding window, so that bids sent right after a fresh deposit do not
+// fail while the deposit transaction is still confirming.
+//
+// Parameters:
+// - ctx: The context controlling the wait.
+// - client: The client connected to the mev-commit chain.
+// - address: The bidder address whose deposit is checked.
+// - timeout: How long to wait for the deposit before giving up.
+//
+// Returns:
+// - nil once the deposit is sufficient, ErrInsufficientDeposit on timeout, or a failed call's error.
+func WaitForSufficientDeposit(ctx context.Context, client *ethclient.Client, address common.Address, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(DefaultDepositPollInterval)
+	defer ticker.Stop()
+
+	for {
+		window, deposit, minDeposit, err := currentDeposit(client, address)
+		if err != nil {
+			return err
+		}
+		if deposit.Cmp(minDeposit) >= 0 {
+			logger.Info("Bidder deposit is sufficient", "window", window, "deposit", deposit, "minDeposit", minDeposit)
+			return nil
+		}
+		logger.Info("Waiting for bidder deposit", "window", window, "deposit", deposit, "minDeposit", minDeposit)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: deposit %s, minimum %s in window %s after %s", ErrInsufficientDeposit, deposit, minDeposit, window, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// currentDeposit reads the current window, the address's deposit in it and the minimum deposit.
+func currentDeposit(client *ethclient.Client, address common.Address) (*big.Int, *big.Int, *big.Int, error) {
+	window, err := WindowHeight(client)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get current window: %w", err)
+	}
+	deposit, err := GetDepositAmount(client, address, *window)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	minDeposit, err := GetMinDeposit(client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return window, deposit, minDeposit, nil
+}
+
+// EnsureNextWindowFunded tops up the deposit of a bidding window ahead of the current one, so the
+// account stays funded when that window starts. The window is funded with DepositIntoWindow when
+// its deposit is below the minimum deposit.
+//
+// Parameters:
+// - client: The client connected to the mev-commit chain.
+// - authAcct: The account to fund, with a transactor for the mev-commit chain.
+// - lookahead: How many windows after the current one to fund, at least 1.
+//
+// Returns:
+// - Whether a deposit was made, and an error if a call or the deposit fails.
+func EnsureNextWindowFunded(client *ethclient.Client, authAcct *AuthAcct, lookahead uint64) (bool, error) {
+	if lookahead == 0 {
+		return false, errors.New("window lookahead must be at least 1")
+	}
+
+	window, err := WindowHeight(client)
+	if err != nil {
+		return false, fmt.Errorf("failed to get current window: %w", err)
+	}
+	target := new(big.Int).Add(window, new(big.Int).SetUint64(lookahead))
+
+	deposit, err := GetDepositAmount(client, authAcct.Address, *target)
+	if err != nil {
+		return false, err
+	}
+	minDeposit, err := GetMinDeposit(client)
+	if err != nil {
+		return false, err
+	}
+	if deposit.Cmp(minDeposit) >= 0 {
+		logger.Debug("Window already funded", "window", target, "deposit", deposit, "minDeposit", minDeposit)
+		return false, nil
+	}
+
+	logger.Info("Funding window ahead", "window", target, "deposit", deposit, "minDeposit", minDeposit)
+	if _, err := DepositIntoWindow(client, target, authAcct); err != nil {
+		return false, fmt.Errorf("failed to fund window %s: %w", target, err)
+	}
+	return true, nil
+}
+
+// KeepNextWindowFunded calls EnsureNextWindowFunded right away and again on each transition to a
+// new bidding window, until ctx is done. Failures are logged and retried at the next check.
+//
+// Parameters:
+// - ctx: The context controlling the loop.
+// - client: The client connected to the mev-commit chain.
+// - authAcct: The account to fund, with a transactor for the mev-commit chain.
+// - lookahead: How many windows after the current one to fund, at least 1.
+func KeepNextWindowFunded(ctx context.Context, client *ethclient.Client, authAcct *AuthAcct, lookahead uint64) {
+	ticker := time.NewTicker(DefaultWindowPollInterval)
+	defer ticker.Stop()
+
+	var funded *big.Int // The last window EnsureNextWindowFunded succeeded in.
+	for {
+		window, err := WindowHeight(client)
+		if err != nil {
+			logger.Warn("Failed to get current window", "err", err)
+		} else if funded == nil || window.Cmp(funded) != 0 {
+			if _, err := EnsureNextWindowFunded(client, authAcct, lookahead); err != nil {
+				logger.Warn("Failed to fund window ahead", "window", window, "err", err)
+			} else {
+				funded = window
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
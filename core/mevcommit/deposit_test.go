@@ -0,0 +1,177 @@
+package mevcommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeRegistryChain is a mev-commit chain node answering the contract calls of deposit checks
+// and bid hash verification.
+type fakeRegistryChain struct {
+	contracts []abi.ABI
+
+	mu         sync.Mutex
+	window     int64
+	deposits   map[common.Address]*big.Int // Deposits in the current window.
+	minDeposit *big.Int
+	bidHash    func(txnHash string, bid, blockNumber, decayStart, decayEnd uint64) common.Hash // Answers getBidHash.
+}
+
+// callArgs is the part of an eth_call's arguments the fake reads.
+type callArgs struct {
+	Input hexutil.Bytes `json:"input"`
+	Data  hexutil.Bytes `json:"data"`
+}
+
+// Call answers a contract call by the method its selector names.
+func (f *fakeRegistryChain) Call(args callArgs, _ json.RawMessage) (hexutil.Bytes, error) {
+	input := args.Input
+	if len(input) == 0 {
+		input = args.Data
+	}
+	if len(input) < 4 {
+		return nil, errors.New("no method selector")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, contract := range f.contracts {
+		method, err := contract.MethodById(input[:4])
+		if err != nil {
+			continue
+		}
+		switch method.Name {
+		case "getCurrentWindow":
+			return method.Outputs.Pack(big.NewInt(f.window))
+		case "minDeposit":
+			return method.Outputs.Pack(f.minDeposit)
+		case "getDeposit":
+			inputs, err := method.Inputs.Unpack(input[4:])
+			if err != nil {
+				return nil, err
+			}
+			deposit := f.deposits[inputs[0].(common.Address)]
+			if deposit == nil || inputs[1].(*big.Int).Int64() != f.window {
+				deposit = new(big.Int)
+			}
+			return method.Outputs.Pack(deposit)
+		case "getBidHash":
+			inputs, err := method.Inputs.Unpack(input[4:])
+			if err != nil {
+				return nil, err
+			}
+			return method.Outputs.Pack(f.bidHash(inputs[0].(string), inputs[1].(uint64), inputs[2].(uint64), inputs[3].(uint64), inputs[4].(uint64)))
+		}
+		return nil, fmt.Errorf("unexpected call to %s", method.Name)
+	}
+	return nil, fmt.Errorf("unknown method selector %x", input[:4])
+}
+
+// dial serves the fake in process and returns a client connected to it.
+func (f *fakeRegistryChain) dial(t *testing.T) *ethclient.Client {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", f); err != nil {
+		t.Fatalf("RegisterName: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestWaitForSufficientDeposit(t *testing.T) {
+	bidder := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	tests := []struct {
+		name     string
+		deposits map[common.Address]*big.Int
+		window   int64
+		wantErr  error
+	}{
+		{name: "above minimum", deposits: map[common.Address]*big.Int{bidder: big.NewInt(2e18)}, window: 7},
+		{name: "at minimum", deposits: map[common.Address]*big.Int{bidder: big.NewInt(1e18)}, window: 7},
+		{name: "below minimum", deposits: map[common.Address]*big.Int{bidder: big.NewInt(1e17)}, window: 7, wantErr: ErrInsufficientDeposit},
+		{name: "no deposit", deposits: map[common.Address]*big.Int{common.Address{1}: big.NewInt(2e18)}, window: 7, wantErr: ErrInsufficientDeposit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeRegistryChain{
+				contracts:  []abi.ABI{loadTestABI(t, "BlockTracker.abi"), loadTestABI(t, "BidderRegistry.abi")},
+				window:     tt.window,
+				deposits:   tt.deposits,
+				minDeposit: big.NewInt(1e18),
+			}
+			client := chain.dial(t)
+			inRepoRoot(t)
+
+			start := time.Now()
+			err := WaitForSufficientDeposit(context.Background(), client, bidder, 100*time.Millisecond)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WaitForSufficientDeposit error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && time.Since(start) >= 100*time.Millisecond {
+				t.Fatal("sufficient deposit waited for the timeout")
+			}
+		})
+	}
+}
+
+func TestWaitForSufficientDepositCallFails(t *testing.T) {
+	// A chain without the registry fails the calls instead of waiting for the timeout
+	chain := &fakeRegistryChain{contracts: []abi.ABI{loadTestABI(t, "BlockTracker.abi")}}
+	client := chain.dial(t)
+	inRepoRoot(t)
+
+	err := WaitForSufficientDeposit(context.Background(), client, common.Address{1}, time.Minute)
+	if err == nil || errors.Is(err, ErrInsufficientDeposit) {
+		t.Fatalf("WaitForSufficientDeposit error = %v, want a call error", err)
+	}
+}
+
+func TestDepositIntoWindowClosed(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     int64
+		wantClosed bool
+	}{
+		{name: "past window", window: 6, wantClosed: true},
+		{name: "current window", window: 7},
+		{name: "future window", window: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeRegistryChain{
+				contracts:  []abi.ABI{loadTestABI(t, "BlockTracker.abi"), loadTestABI(t, "BidderRegistry.abi")},
+				window:     7,
+				minDeposit: big.NewInt(1e18),
+			}
+			client := chain.dial(t)
+			inRepoRoot(t)
+			authAcct, err := AuthenticateAddress(testPrivateKey)
+			if err != nil {
+				t.Fatalf("AuthenticateAddress: %v", err)
+			}
+
+			// The fake cannot accept transactions, so open windows fail once the deposit is sent
+			_, err = DepositIntoWindow(client, big.NewInt(tt.window), &authAcct)
+			if err == nil {
+				t.Fatal("DepositIntoWindow succeeded against a chain without transactions")
+			}
+			if closed := errors.Is(err, ErrWindowClosed); closed != tt.wantClosed {
+				t.Fatalf("DepositIntoWindow error = %v, want window closed %v", err, tt.wantClosed)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package mevcommit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultBlocksPerWindow is the number of L1 blocks in a bidding window as of mev-commit v0.6.1.
+const DefaultBlocksPerWindow = 10
+
+// WindowForBlock returns the bidding window an L1 block belongs to, numbered as the BlockTracker
+// contract does with DefaultBlocksPerWindow blocks per window: blocks 1 to 10 are window 1.
+// Block zero precedes every window and returns zero. WindowSizeDetector.WindowForBlock uses the
+// detected window size instead.
+func WindowForBlock(blockNumber uint64) uint64 {
+	return windowForBlock(blockNumber, DefaultBlocksPerWindow)
+}
+
+// windowForBlock returns the bidding window of an L1 block with blocksPerWindow blocks per window.
+func windowForBlock(blockNumber, blocksPerWindow uint64) uint64 {
+	if blockNumber == 0 {
+		return 0
+	}
+	return (blockNumber-1)/blocksPerWindow + 1
+}
+
+// WindowCostConfig holds the bidding parameters used to estimate the cost of a window.
+type WindowCostConfig struct {
+	MinBidAmount    *big.Int // The lowest bid amount in wei.
+	MaxBidAmount    *big.Int // The highest bid amount in wei.
+	BlocksPerWindow uint64   // The number of L1 blocks in a window. Zero uses the detected size of WindowSize.
+	BidsPerBlock    uint64   // The number of bids sent per block. Zero means one.
+
+	WindowSize *WindowSizeDetector // Supplies the window size when BlocksPerWindow is zero. If nil or not measured yet, DefaultBlocksPerWindow is used.
+}
+
+// WindowCostEstimate is the breakdown of the expected spend for one bidding window.
+type WindowCostEstimate struct {
+	MinDeposit       *big.Int // The minimum deposit required by the BidderRegistry, in wei.
+	ExpectedBids     uint64   // The number of bids expected in the window.
+	AverageBidAmount *big.Int // The midpoint of the bid amount bounds, in wei.
+	BidSpend         *big.Int // ExpectedBids times AverageBidAmount, in wei.
+	Total            *big.Int // MinDeposit plus BidSpend, in wei.
+}
+
+// EstimateWindowCost estimates the funds needed to participate in a full bidding window.
+// This is a ballpark figure for planning: it assumes every bid is committed and pays
+// the midpoint of the configured bid amount bounds.
+//
+// Parameters:
+// - client: The Ethereum client instance.
+// - cfg: The WindowCostConfig struct containing the bid amount bounds and window size.
+//
+// Returns:
+// - The cost breakdown, or an error if the configuration is invalid or the minimum deposit cannot be read.
+func EstimateWindowCost(client *ethclient.Client, cfg WindowCostConfig) (*WindowCostEstimate, error) {
+	if cfg.MinBidAmount == nil || cfg.MaxBidAmount == nil {
+		return nil, fmt.Errorf("bid amount bounds are required")
+	}
+	if cfg.MinBidAmount.Sign() < 0 || cfg.MaxBidAmount.Cmp(cfg.MinBidAmount) < 0 {
+		return nil, fmt.Errorf("invalid bid amount bounds [%s, %s]", cfg.MinBidAmount, cfg.MaxBidAmount)
+	}
+
+	blocksPerWindow := cfg.BlocksPerWindow
+	if blocksPerWindow == 0 {
+		blocksPerWindow, _ = cfg.WindowSize.BlocksPerWindow()
+	}
+	bidsPerBlock := cfg.BidsPerBlock
+	if bidsPerBlock == 0 {
+		bidsPerBlock = 1
+	}
+
+	// Retrieve the minimum deposit amount
+	minDeposit, err := GetMinDeposit(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minDeposit: %v", err)
+	}
+
+	expectedBids := blocksPerWindow * bidsPerBlock
+	average := new(big.Int).Add(cfg.MinBidAmount, cfg.MaxBidAmount)
+	average.Div(average, big.NewInt(2))
+	bidSpend := new(big.Int).Mul(average, new(big.Int).SetUint64(expectedBids))
+
+	return &WindowCostEstimate{
+		MinDeposit:       minDeposit,
+		ExpectedBids:     expectedBids,
+		AverageBidAmount: average,
+		BidSpend:         bidSpend,
+		Total:            new(big.Int).Add(minDeposit, bidSpend),
+	}, nil
+}
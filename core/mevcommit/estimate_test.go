@@ -0,0 +1,23 @@
+package mevcommit
+
+import "testing"
+
+func TestWindowForBlock(t *testing.T) {
+	tests := []struct {
+		block uint64
+		want  uint64
+	}{
+		{block: 0, want: 0},
+		{block: 1, want: 1},
+		{block: 10, want: 1},
+		{block: 11, want: 2},
+		{block: 20, want: 2},
+		{block: 21, want: 3},
+		{block: 1_000_001, want: 100_001},
+	}
+	for _, tt := range tests {
+		if got := WindowForBlock(tt.block); got != tt.want {
+			t.Errorf("WindowForBlock(%d) = %d, want %d", tt.block, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// MaxLadderConcurrency is how many rungs of a bid ladder are sent at once.
+const MaxLadderConcurrency = 4
+
+// LadderRung is the outcome of one bid of a ladder.
+type LadderRung struct {
+	Amount string     // The bid amount in wei.
+	Result *BidResult // The result of the bid, nil if it was not sent.
+	Err    error      // Why the bid failed or was not sent.
+}
+
+// SendBidLadder bids on the same transactions for the same block once per amount, to see which
+// amounts providers commit to. At most MaxLadderConcurrency bids are sent at once, each with
+// SendBidRequest, so every rung is saved with its own amount.
+//
+// Parameters:
+// - ctx: The context bounding the ladder; rungs not yet sent once it is done fail with its error.
+// - input: Either a slice of transaction hashes ([]string) or a slice of signed transactions ([]*types.Transaction).
+// - blockNumber: The L1 block number the bids target.
+// - amounts: The bid amounts in wei, as decimal strings.
+// - decayStart: The decay start timestamp in milliseconds.
+// - decayEnd: The decay end timestamp in milliseconds.
+//
+// Returns:
+// - The rungs in the order of amounts, or an error if an amount is invalid or a bid request cannot be built, in which case no bid is sent.
+func (b *Bidder) SendBidLadder(ctx context.Context, input interface{}, blockNumber int64, amounts []string, decayStart, decayEnd int64) ([]LadderRung, error) {
+	if len(amounts) == 0 {
+		return nil, errors.New("bid ladder has no amounts")
+	}
+
+	// Build every request first, so a bad amount does not leave the ladder half sent
+	rungs := make([]LadderRung, len(amounts))
+	bids := make([]func() (*BidResult, error), len(amounts))
+	for i, amount := range amounts {
+		if value, ok := new(big.Int).SetString(amount, 10); !ok || value.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid bid amount '%s' at rung %d", amount, i)
+		}
+		bidRequest, err := NewBidRequest(input, amount, blockNumber, decayStart, decayEnd)
+		if err != nil {
+			return nil, err
+		}
+		rungs[i].Amount = amount
+		bids[i] = func() (*BidResult, error) { return b.SendBidRequest(ctx, bidRequest) }
+	}
+
+	sem := make(chan struct{}, MaxLadderConcurrency)
+	var wg sync.WaitGroup
+	for i := range rungs {
+		// A free slot does not take precedence over a done context
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(rungs); j++ {
+				rungs[j].Err = err
+			}
+			wg.Wait()
+			return rungs, nil
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			rungs[i].Result, rungs[i].Err = bids[i]()
+		}(i)
+	}
+	wg.Wait()
+	return rungs, nil
+}
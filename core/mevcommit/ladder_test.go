@@ -0,0 +1,159 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSendBidLadder(t *testing.T) {
+	// Providers commit to bids of at least 30 wei, and reject bids of 13 wei
+	server := func() *fakeBidderServer {
+		return &fakeBidderServer{
+			commit: func(bid *pb.Bid) []*pb.Commitment {
+				amount, _ := new(big.Int).SetString(bid.Amount, 10)
+				if amount.Cmp(big.NewInt(30)) < 0 {
+					return nil
+				}
+				return []*pb.Commitment{{TxHashes: bid.TxHashes, BidAmount: bid.Amount, BlockNumber: bid.BlockNumber, ProviderAddress: "0x01"}}
+			},
+			fail: func(bid *pb.Bid) error {
+				if bid.Amount == "13" {
+					return status.Error(codes.InvalidArgument, "bid amount too low")
+				}
+				return nil
+			},
+		}
+	}
+	tests := []struct {
+		name            string
+		amounts         []string
+		cancelled       bool
+		wantCommitments []int   // Per rung.
+		wantErrs        []error // Per rung, nil for none.
+		wantBids        int     // Bids received by the node.
+		wantErr         bool
+	}{
+		{name: "one rung", amounts: []string{"40"}, wantCommitments: []int{1}, wantErrs: []error{nil}, wantBids: 1},
+		{
+			name:            "price discovery",
+			amounts:         []string{"10", "20", "30", "40", "50", "60"},
+			wantCommitments: []int{0, 0, 1, 1, 1, 1},
+			wantErrs:        []error{ErrNoCommitment, ErrNoCommitment, nil, nil, nil, nil},
+			wantBids:        6,
+		},
+		{name: "rejected rung", amounts: []string{"13", "40"}, wantCommitments: []int{0, 1}, wantBids: 2},
+		{name: "no amounts", wantErr: true},
+		{name: "invalid amount", amounts: []string{"10", "lots"}, wantErr: true},
+		{name: "zero amount", amounts: []string{"0"}, wantErr: true},
+		{name: "negative amount", amounts: []string{"-5"}, wantErr: true},
+		{
+			name:      "context done",
+			amounts:   []string{"10", "40"},
+			cancelled: true,
+			wantErrs:  []error{context.Canceled, context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := server()
+			b := dialFakeBidder(t, srv)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			rungs, err := b.SendBidLadder(ctx, []string{common.Hash{0xab}.Hex()}, 100, tt.amounts, 1000, 37000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendBidLadder error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := len(srv.received()); got != tt.wantBids {
+				t.Fatalf("%d bids sent, want %d", got, tt.wantBids)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(rungs) != len(tt.amounts) {
+				t.Fatalf("%d rungs, want %d", len(rungs), len(tt.amounts))
+			}
+			for i, rung := range rungs {
+				if rung.Amount != tt.amounts[i] {
+					t.Fatalf("rung %d amount = %s, want %s", i, rung.Amount, tt.amounts[i])
+				}
+				if tt.wantErrs != nil && !errors.Is(rung.Err, tt.wantErrs[i]) {
+					t.Fatalf("rung %d error = %v, want %v", i, rung.Err, tt.wantErrs[i])
+				}
+				if tt.wantCommitments == nil {
+					continue
+				}
+				if rung.Result == nil {
+					if tt.wantCommitments[i] > 0 {
+						t.Fatalf("rung %d has no result", i)
+					}
+					continue
+				}
+				if got := len(rung.Result.Commitments); got != tt.wantCommitments[i] {
+					t.Fatalf("rung %d: %d commitments, want %d", i, got, tt.wantCommitments[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSendBidLadderConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var running, most int
+	release := make(chan struct{})
+	started := make(chan struct{}, 2*MaxLadderConcurrency)
+	srv := &fakeBidderServer{fail: func(*pb.Bid) error {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		started <- struct{}{}
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}}
+	b := dialFakeBidder(t, srv)
+
+	amounts := make([]string, 2*MaxLadderConcurrency)
+	for i := range amounts {
+		amounts[i] = big.NewInt(int64(i + 1)).String()
+	}
+	done := make(chan []LadderRung)
+	go func() {
+		rungs, err := b.SendBidLadder(context.Background(), []string{common.Hash{0xab}.Hex()}, 100, amounts, 1000, 37000)
+		if err != nil {
+			t.Errorf("SendBidLadder: %v", err)
+		}
+		done <- rungs
+	}()
+
+	// The first rungs hold every slot until they are released
+	for i := 0; i < MaxLadderConcurrency; i++ {
+		<-started
+	}
+	close(release)
+	rungs := <-done
+	if most != MaxLadderConcurrency {
+		t.Fatalf("%d bids ran at once, want %d", most, MaxLadderConcurrency)
+	}
+	for i, rung := range rungs {
+		if rung.Err != nil || len(rung.Result.Commitments) != 1 {
+			t.Fatalf("rung %d = %+v", i, rung)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultMaxABIMismatches is the number of consecutive logs that do not match the contract ABI
+// after which the CommitmentListener gives up.
+const DefaultMaxABIMismatches = 5
+
+// ErrABIMismatch is returned when a CommitmentStored log does not match the pinned contract ABI,
+// which usually means the PreconfManager contract was upgraded past ProtocolVersion.
+var ErrABIMismatch = errors.New("log does not match the PreconfManager ABI of mev-commit " + ProtocolVersion + ", the contract may have been upgraded; update the ABI and contract addresses")
+
+// CommitmentStatus describes how settled a commitment delivered by the CommitmentListener is.
+type CommitmentStatus int
+
+const (
+	// CommitmentPending is a commitment whose block is not yet ConfirmationDepth blocks deep.
+	CommitmentPending CommitmentStatus = iota
+	// CommitmentFinal is a commitment whose block is at least ConfirmationDepth blocks deep.
+	CommitmentFinal
+	// CommitmentRemoved is a previously delivered commitment that was reverted by a chain reorg.
+	CommitmentRemoved
+)
+
+// String returns the name of the status.
+func (s CommitmentStatus) String() string {
+	switch s {
+	case CommitmentPending:
+		return "pending"
+	case CommitmentFinal:
+		return "final"
+	case CommitmentRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// CommitmentEvent is a decoded CommitmentStored log delivered by the CommitmentListener.
+type CommitmentEvent struct {
+	Event  CommitmentStoredEvent // The decoded event.
+	Log    types.Log             // The raw log, including the block and transaction it was emitted in.
+	Status CommitmentStatus      // Whether the commitment is pending, final, or reverted by a reorg.
+}
+
+// CommitmentLogSource is the subset of the Ethereum client used by the CommitmentListener.
+type CommitmentLogSource interface {
+	ethereum.LogFilterer
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// ListenerConfig holds the configuration settings for a CommitmentListener.
+type ListenerConfig struct {
+	Dedup             *CommitmentDeduper // Tracks emitted commitments. If nil, a default one is used.
+	ConfirmationDepth uint64             // Blocks on top of a commitment's block before it is final. Zero delivers commitments as final immediately.
+	Logger            log.Logger         // The logger used by the listener. If nil, the package logger is used.
+	MaxABIMismatches  int                // Consecutive logs that may fail to match the ABI before Run fails. Zero uses DefaultMaxABIMismatches.
+	NoReemit          bool               // Whether a commitment reverted by a reorg is not delivered again if re-included. Its removal is still delivered once.
+}
+
+// CommitmentListener streams CommitmentStored events using a single log subscription on the
+// PreconfManager contract. Commitments are delivered as pending until their block is
+// ConfirmationDepth blocks deep, then as final. Logs reverted by a chain reorg are delivered
+// as removal events.
+type CommitmentListener struct {
+	client  CommitmentLogSource // The client used to subscribe to contract logs and heads.
+	abi     abi.ABI             // The PreConfCommitmentStore contract ABI.
+	address common.Address      // The PreconfManager contract address.
+	dedup   *CommitmentDeduper  // Tracks emitted commitments so each is delivered once.
+	depth   uint64              // The confirmation depth before a commitment is final.
+	logger  log.Logger          // Logger for the listener's output.
+	reemit  bool                // Whether commitments reverted by a reorg are delivered again if re-included.
+
+	pending       map[[32]byte]CommitmentEvent // Commitments delivered as pending and awaiting finality.
+	mismatches    int                          // Consecutive logs that did not match the ABI.
+	maxMismatches int                          // Consecutive mismatches after which Run fails.
+}
+
+// NewCommitmentListener creates a CommitmentListener for the PreconfManager contract.
+//
+// Parameters:
+// - client: The client used to subscribe to contract logs and new heads.
+// - cfg: The ListenerConfig struct containing the de-duplicator and confirmation depth.
+//
+// Returns:
+// - A pointer to a CommitmentListener, or an error if the contract ABI cannot be loaded.
+func NewCommitmentListener(client CommitmentLogSource, cfg ListenerConfig) (*CommitmentListener, error) {
+	contractAbi, err := LoadABI("abi/PreConfCommitmentStore.abi")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contract ABI: %w", err)
+	}
+
+	dedup := cfg.Dedup
+	if dedup == nil {
+		dedup = NewCommitmentDeduper(DefaultDedupCapacity)
+	}
+
+	listenerLogger := cfg.Logger
+	if listenerLogger == nil {
+		listenerLogger = logger
+	}
+
+	maxMismatches := cfg.MaxABIMismatches
+	if maxMismatches <= 0 {
+		maxMismatches = DefaultMaxABIMismatches
+	}
+
+	return &CommitmentListener{
+		client:        client,
+		logger:        listenerLogger,
+		abi:           contractAbi,
+		address:       common.HexToAddress(PreconfManagerAddress),
+		dedup:         dedup,
+		depth:         cfg.ConfirmationDepth,
+		reemit:        !cfg.NoReemit,
+		pending:       make(map[[32]byte]CommitmentEvent),
+		maxMismatches: maxMismatches,
+	}, nil
+}
+
+// Run subscribes to CommitmentStored logs and sends each decoded event to out until the
+// context is cancelled or the subscription fails.
+//
+// Parameters:
+// - ctx: The context controlling the subscription.
+// - out: The channel decoded events are sent to.
+//
+// Returns:
+// - The subscription error, an error wrapping ErrABIMismatch once too many consecutive logs do not match the ABI, or the context error once the context is cancelled.
+func (l *CommitmentListener) Run(ctx context.Context, out chan<- CommitmentEvent) error {
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{l.address},
+		Topics:    [][]common.Hash{{l.abi.Events["CommitmentStored"].ID}},
+	}
+
+	logs := make(chan types.Log)
+	sub, err := l.client.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Heads are only needed to decide when pending commitments become final
+	headers := make(chan *types.Header)
+	var headErr <-chan error
+	if l.depth > 0 {
+		headSub, err := l.client.SubscribeNewHead(ctx, headers)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to new heads: %w", err)
+		}
+		defer headSub.Unsubscribe()
+		headErr = headSub.Err()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("log subscription failed: %w", err)
+		case err := <-headErr:
+			return fmt.Errorf("head subscription failed: %w", err)
+		case header := <-headers:
+			if err := l.finalize(ctx, header.Number.Uint64(), out); err != nil {
+				return err
+			}
+		case vLog := <-logs:
+			event, err := l.decode(vLog)
+			if err != nil {
+				if err := l.decodeFailed(vLog, err); err != nil {
+					return err
+				}
+				continue
+			}
+			l.mismatches = 0
+
+			// Skip commitments that were already delivered
+			observe := l.dedup.Observe
+			if !l.reemit {
+				observe = l.dedup.ObserveOnce
+			}
+			if !observe(event.CommitmentIndex, vLog.Removed) {
+				continue
+			}
+
+			e := CommitmentEvent{Event: event, Log: vLog, Status: CommitmentFinal}
+			switch {
+			case vLog.Removed:
+				delete(l.pending, event.CommitmentIndex)
+				e.Status = CommitmentRemoved
+			case l.depth > 0:
+				l.pending[event.CommitmentIndex] = e
+				e.Status = CommitmentPending
+			}
+
+			if err := send(ctx, out, e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// finalize delivers every pending commitment that is at least depth blocks below head as final.
+func (l *CommitmentListener) finalize(ctx context.Context, head uint64, out chan<- CommitmentEvent) error {
+	for index, e := range l.pending {
+		if e.Log.BlockNumber+l.depth > head {
+			continue
+		}
+
+		delete(l.pending, index)
+		e.Status = CommitmentFinal
+		if err := send(ctx, out, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeFailed logs a log that could not be decoded. Logs that do not match the ABI are counted,
+// and once maxMismatches of them arrive in a row an error wrapping ErrABIMismatch is returned.
+func (l *CommitmentListener) decodeFailed(vLog types.Log, err error) error {
+	if !errors.Is(err, ErrABIMismatch) {
+		l.logger.Warn("Failed to unpack log data", "tx", vLog.TxHash, "error", err)
+		return nil
+	}
+
+	l.mismatches++
+	if l.mismatches >= l.maxMismatches {
+		l.logger.Error("CommitmentStored logs keep failing to decode, the PreconfManager ABI is out of date", "version", ProtocolVersion, "consecutive", l.mismatches, "tx", vLog.TxHash, "error", err)
+		return fmt.Errorf("%d consecutive logs failed to decode: %w", l.mismatches, err)
+	}
+	l.logger.Warn("CommitmentStored log does not match the ABI", "version", ProtocolVersion, "consecutive", l.mismatches, "tx", vLog.TxHash, "error", err)
+	return nil
+}
+
+// send delivers an event to out unless the context is cancelled first.
+func send[E any](ctx context.Context, out chan<- E, e E) error {
+	select {
+	case out <- e:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// decode unpacks a CommitmentStored log into a CommitmentStoredEvent. Logs whose topics or data
+// do not fit the ABI's event fail with an error wrapping ErrABIMismatch.
+func (l *CommitmentListener) decode(vLog types.Log) (CommitmentStoredEvent, error) {
+	var event CommitmentStoredEvent
+
+	abiEvent := l.abi.Events["CommitmentStored"]
+	if len(vLog.Topics) == 0 || vLog.Topics[0] != abiEvent.ID {
+		return event, fmt.Errorf("%w: unexpected event signature", ErrABIMismatch)
+	}
+	indexed := 0
+	for _, input := range abiEvent.Inputs {
+		if input.Indexed {
+			indexed++
+		}
+	}
+	if len(vLog.Topics) != indexed+1 {
+		return event, fmt.Errorf("%w: got %d topics, want %d", ErrABIMismatch, len(vLog.Topics), indexed+1)
+	}
+
+	// Unpack the log data into the CommitmentStoredEvent struct
+	if err := l.abi.UnpackIntoInterface(&event, "CommitmentStored", vLog.Data); err != nil {
+		return event, fmt.Errorf("%w: %v", ErrABIMismatch, err)
+	}
+
+	// The commitment index is indexed, so it is carried in the topics rather than the data
+	if len(vLog.Topics) > 1 {
+		event.CommitmentIndex = vLog.Topics[1]
+	}
+	return event, nil
+}
@@ -0,0 +1,386 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// inRepoRoot runs the rest of the test from the repository root, where the contract ABIs are
+// loaded from.
+func inRepoRoot(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// loadTestABI loads a contract ABI from the repository's abi directory.
+func loadTestABI(t *testing.T, name string) abi.ABI {
+	t.Helper()
+	contractAbi, err := LoadABI("../../abi/" + name)
+	if err != nil {
+		t.Fatalf("LoadABI: %v", err)
+	}
+	return contractAbi
+}
+
+// fakeSubscription is a subscription that only fails when told to.
+type fakeSubscription struct {
+	err chan error
+}
+
+func (s fakeSubscription) Unsubscribe()      {}
+func (s fakeSubscription) Err() <-chan error { return s.err }
+
+// fakeLogSource hands the channels of its log and head subscriptions to the test.
+type fakeLogSource struct {
+	logs  chan chan<- types.Log
+	heads chan chan<- *types.Header
+	sub   fakeSubscription
+}
+
+func newFakeLogSource() *fakeLogSource {
+	return &fakeLogSource{
+		logs:  make(chan chan<- types.Log, 1),
+		heads: make(chan chan<- *types.Header, 1),
+		sub:   fakeSubscription{err: make(chan error, 1)},
+	}
+}
+
+func (s *fakeLogSource) FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (s *fakeLogSource) SubscribeFilterLogs(_ context.Context, _ ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	s.logs <- ch
+	return s.sub, nil
+}
+
+func (s *fakeLogSource) SubscribeNewHead(_ context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	s.heads <- ch
+	return fakeSubscription{err: make(chan error)}, nil
+}
+
+// commitmentLog returns a CommitmentStored log of the commitment with the given index, emitted in
+// block. Removed logs are reverted by a reorg.
+func commitmentLog(t *testing.T, contractAbi abi.ABI, index byte, block uint64, removed bool) types.Log {
+	t.Helper()
+	event := contractAbi.Events["CommitmentStored"]
+	data, err := event.Inputs.NonIndexed().Pack(
+		common.Address{0xb1}, common.Address{0xc1}, uint64(42), uint64(200), [32]byte{0xbb},
+		uint64(1000), uint64(37000), "ab", [32]byte{0xcc}, []byte{1}, []byte{2}, uint64(5), []byte{3},
+	)
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	return types.Log{
+		Address:     common.HexToAddress(PreconfManagerAddress),
+		Topics:      []common.Hash{event.ID, {index}},
+		Data:        data,
+		BlockNumber: block,
+		Removed:     removed,
+	}
+}
+
+// listenerStep feeds a log or a head to a CommitmentListener and lists the events it should
+// deliver in response, by commitment index and status.
+type listenerStep struct {
+	log  *types.Log
+	head uint64
+	want []listenerEvent
+}
+
+type listenerEvent struct {
+	index  byte
+	status CommitmentStatus
+}
+
+// runListenerSteps runs a CommitmentListener with cfg over the steps and checks its events.
+func runListenerSteps(t *testing.T, cfg ListenerConfig, steps []listenerStep) {
+	t.Helper()
+	source := newFakeLogSource()
+	l, err := NewCommitmentListener(source, cfg)
+	if err != nil {
+		t.Fatalf("NewCommitmentListener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan CommitmentEvent)
+	runErr := make(chan error, 1)
+	go func() { runErr <- l.Run(ctx, out) }()
+	defer func() {
+		cancel()
+		if err := <-runErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("Run = %v, want context.Canceled", err)
+		}
+	}()
+
+	logs := <-source.logs
+	var heads chan<- *types.Header
+	if cfg.ConfirmationDepth > 0 {
+		heads = <-source.heads
+	}
+	timeout := time.After(5 * time.Second)
+	for i, step := range steps {
+		// An unexpected event blocks the listener, so the next input is not taken
+		var err error
+		if step.log != nil {
+			err = feed(logs, *step.log, timeout)
+		} else {
+			err = feed(heads, &types.Header{Number: new(big.Int).SetUint64(step.head)}, timeout)
+		}
+		if err != nil {
+			t.Fatalf("step %d: listener did not take its input, it sent an unexpected event", i)
+		}
+
+		var got []listenerEvent
+		for range step.want {
+			select {
+			case e := <-out:
+				got = append(got, listenerEvent{index: e.Event.CommitmentIndex[0], status: e.Status})
+			case <-timeout:
+				t.Fatalf("step %d: got events %v, want %v", i, got, step.want)
+			}
+		}
+		// Pending commitments become final in no particular order
+		sort.Slice(got, func(a, b int) bool { return got[a].index < got[b].index })
+		if len(step.want) > 0 && !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("step %d: got events %v, want %v", i, got, step.want)
+		}
+	}
+	select {
+	case e := <-out:
+		t.Fatalf("unexpected event for commitment %x (%s)", e.Event.CommitmentIndex[:1], e.Status)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// feed sends v to ch, failing once timeout fires.
+func feed[V any](ch chan<- V, v V, timeout <-chan time.Time) error {
+	select {
+	case ch <- v:
+		return nil
+	case <-timeout:
+		return errors.New("timed out")
+	}
+}
+
+func TestCommitmentListenerReorgs(t *testing.T) {
+	contractAbi := loadTestABI(t, "PreConfCommitmentStore.abi")
+	inRepoRoot(t)
+	logOf := func(index byte, removed bool) *types.Log {
+		l := commitmentLog(t, contractAbi, index, 10, removed)
+		return &l
+	}
+	tests := []struct {
+		name     string
+		noReemit bool
+		steps    []listenerStep
+	}{
+		{
+			name: "commitments",
+			steps: []listenerStep{
+				{log: logOf(1, false), want: []listenerEvent{{1, CommitmentFinal}}},
+				{log: logOf(2, false), want: []listenerEvent{{2, CommitmentFinal}}},
+			},
+		},
+		{
+			name: "delivered again",
+			steps: []listenerStep{
+				{log: logOf(1, false), want: []listenerEvent{{1, CommitmentFinal}}},
+				{log: logOf(1, false)},
+			},
+		},
+		{
+			name: "reverted and re-included",
+			steps: []listenerStep{
+				{log: logOf(1, false), want: []listenerEvent{{1, CommitmentFinal}}},
+				{log: logOf(1, true), want: []listenerEvent{{1, CommitmentRemoved}}},
+				{log: logOf(1, true)},
+				{log: logOf(1, false), want: []listenerEvent{{1, CommitmentFinal}}},
+			},
+		},
+		{
+			name:  "removal of an unseen commitment",
+			steps: []listenerStep{{log: logOf(1, true)}},
+		},
+		{
+			name:     "reverted and re-included without re-emitting",
+			noReemit: true,
+			steps: []listenerStep{
+				{log: logOf(1, false), want: []listenerEvent{{1, CommitmentFinal}}},
+				{log: logOf(1, true), want: []listenerEvent{{1, CommitmentRemoved}}},
+				{log: logOf(1, true)},
+				{log: logOf(1, false)},
+				{log: logOf(2, false), want: []listenerEvent{{2, CommitmentFinal}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runListenerSteps(t, ListenerConfig{NoReemit: tt.noReemit}, tt.steps)
+		})
+	}
+}
+
+func TestCommitmentListenerDecode(t *testing.T) {
+	contractAbi := loadTestABI(t, "PreConfCommitmentStore.abi")
+	inRepoRoot(t)
+	l, err := NewCommitmentListener(newFakeLogSource(), ListenerConfig{})
+	if err != nil {
+		t.Fatalf("NewCommitmentListener: %v", err)
+	}
+	event, err := l.decode(commitmentLog(t, contractAbi, 7, 10, false))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if event.CommitmentIndex != [32]byte{7} || event.Bidder != (common.Address{0xb1}) || event.Commiter != (common.Address{0xc1}) || event.Bid != 42 || event.BlockNumber != 200 || event.TxnHash != "ab" {
+		t.Fatalf("decoded %+v", event)
+	}
+}
+
+func TestCommitmentListenerConfirmationDepth(t *testing.T) {
+	contractAbi := loadTestABI(t, "PreConfCommitmentStore.abi")
+	inRepoRoot(t)
+	logAt := func(index byte, block uint64, removed bool) *types.Log {
+		l := commitmentLog(t, contractAbi, index, block, removed)
+		return &l
+	}
+	tests := []struct {
+		name  string
+		steps []listenerStep
+	}{
+		{
+			name: "final once deep enough",
+			steps: []listenerStep{
+				{log: logAt(1, 10, false), want: []listenerEvent{{1, CommitmentPending}}},
+				{head: 11},
+				{head: 12, want: []listenerEvent{{1, CommitmentFinal}}},
+				{head: 13},
+			},
+		},
+		{
+			name: "several at once",
+			steps: []listenerStep{
+				{log: logAt(1, 10, false), want: []listenerEvent{{1, CommitmentPending}}},
+				{log: logAt(2, 11, false), want: []listenerEvent{{2, CommitmentPending}}},
+				{log: logAt(3, 13, false), want: []listenerEvent{{3, CommitmentPending}}},
+				{head: 13, want: []listenerEvent{{1, CommitmentFinal}, {2, CommitmentFinal}}},
+				{head: 15, want: []listenerEvent{{3, CommitmentFinal}}},
+			},
+		},
+		{
+			name: "reverted while pending",
+			steps: []listenerStep{
+				{log: logAt(1, 10, false), want: []listenerEvent{{1, CommitmentPending}}},
+				{log: logAt(1, 10, true), want: []listenerEvent{{1, CommitmentRemoved}}},
+				{head: 20},
+				{log: logAt(1, 21, false), want: []listenerEvent{{1, CommitmentPending}}},
+				{head: 23, want: []listenerEvent{{1, CommitmentFinal}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runListenerSteps(t, ListenerConfig{ConfirmationDepth: 2}, tt.steps)
+		})
+	}
+}
+
+func TestCommitmentListenerDecodeMismatch(t *testing.T) {
+	contractAbi := loadTestABI(t, "PreConfCommitmentStore.abi")
+	inRepoRoot(t)
+	l, err := NewCommitmentListener(newFakeLogSource(), ListenerConfig{})
+	if err != nil {
+		t.Fatalf("NewCommitmentListener: %v", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(l *types.Log)
+	}{
+		{name: "no topics", modify: func(l *types.Log) { l.Topics = nil }},
+		{name: "other event", modify: func(l *types.Log) { l.Topics[0] = common.Hash{0xee} }},
+		{name: "extra topic", modify: func(l *types.Log) { l.Topics = append(l.Topics, common.Hash{1}) }},
+		{name: "missing topic", modify: func(l *types.Log) { l.Topics = l.Topics[:1] }},
+		{name: "truncated data", modify: func(l *types.Log) { l.Data = l.Data[:64] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vLog := commitmentLog(t, contractAbi, 7, 10, false)
+			tt.modify(&vLog)
+			if _, err := l.decode(vLog); !errors.Is(err, ErrABIMismatch) {
+				t.Fatalf("decode error = %v, want %v", err, ErrABIMismatch)
+			}
+		})
+	}
+}
+
+func TestCommitmentListenerABIMismatches(t *testing.T) {
+	contractAbi := loadTestABI(t, "PreConfCommitmentStore.abi")
+	inRepoRoot(t)
+	mismatch := commitmentLog(t, contractAbi, 1, 10, false)
+	mismatch.Topics = mismatch.Topics[:1]
+
+	tests := []struct {
+		name    string
+		max     int
+		logs    []types.Log // The logs fed in order; valid ones are delivered.
+		wantErr bool
+	}{
+		{name: "below the limit", max: 2, logs: []types.Log{mismatch}},
+		{name: "reset by a valid log", max: 2, logs: []types.Log{mismatch, commitmentLog(t, contractAbi, 2, 10, false), mismatch}},
+		{name: "limit reached", max: 2, logs: []types.Log{mismatch, mismatch}, wantErr: true},
+		{name: "default limit", logs: []types.Log{mismatch, mismatch, mismatch, mismatch, mismatch}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newFakeLogSource()
+			l, err := NewCommitmentListener(source, ListenerConfig{MaxABIMismatches: tt.max})
+			if err != nil {
+				t.Fatalf("NewCommitmentListener: %v", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			out := make(chan CommitmentEvent, len(tt.logs))
+			runErr := make(chan error, 1)
+			go func() { runErr <- l.Run(ctx, out) }()
+
+			logs := <-source.logs
+			timeout := time.After(5 * time.Second)
+			for i, vLog := range tt.logs {
+				if err := feed(logs, vLog, timeout); err != nil {
+					t.Fatalf("log %d was not taken", i)
+				}
+			}
+
+			if !tt.wantErr {
+				select {
+				case err := <-runErr:
+					t.Fatalf("Run = %v before the mismatch limit", err)
+				case <-time.After(50 * time.Millisecond):
+				}
+				cancel()
+			}
+			err = <-runErr
+			if gotMismatch := errors.Is(err, ErrABIMismatch); gotMismatch != tt.wantErr {
+				t.Fatalf("Run = %v, want ABI mismatch %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Defaults of a MempoolBidder.
+const (
+	// DefaultMempoolBidDecay is how long mempool bids decay for (2 blocks).
+	DefaultMempoolBidDecay = 36 * time.Second
+	// DefaultMempoolMaxInFlight is how many mempool bids may wait for their commitments at once.
+	DefaultMempoolMaxInFlight = 4
+)
+
+// ErrNoBidStrategy is returned when a MempoolBidder is created without a bid strategy.
+var ErrNoBidStrategy = errors.New("a bid strategy is required")
+
+// PendingTxSource streams the transactions entering a node's mempool.
+type PendingTxSource interface {
+	SubscribePendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error)
+}
+
+// HeadReader reports the number of the latest block, which mempool bids target blocks after.
+type HeadReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// gethPendingTxSource subscribes to newPendingTransactions with full transactions.
+type gethPendingTxSource struct {
+	client *gethclient.Client
+}
+
+// NewPendingTxSource creates a PendingTxSource subscribing to newPendingTransactions on a node
+// that delivers full transactions, such as geth.
+//
+// Parameters:
+// - client: The RPC client of a websocket connection to the node.
+//
+// Returns:
+// - The PendingTxSource.
+func NewPendingTxSource(client *rpc.Client) PendingTxSource {
+	return gethPendingTxSource{client: gethclient.New(client)}
+}
+
+// SubscribePendingTransactions subscribes to the full transactions entering the node's mempool.
+func (s gethPendingTxSource) SubscribePendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error) {
+	return s.client.SubscribeFullPendingTransactions(ctx, ch)
+}
+
+// MempoolFilter selects the mempool transactions to bid on.
+type MempoolFilter struct {
+	MinValue *big.Int         // Only transactions transferring at least this many wei. If nil, any value.
+	To       []common.Address // Only transactions sent to one of these addresses. If empty, any recipient.
+}
+
+// Match reports whether tx passes the filter. Contract creations have no recipient, so they never
+// match a filter with addresses.
+func (f MempoolFilter) Match(tx *types.Transaction) bool {
+	if f.MinValue != nil && tx.Value().Cmp(f.MinValue) < 0 {
+		return false
+	}
+	if len(f.To) == 0 {
+		return true
+	}
+	if tx.To() == nil {
+		return false
+	}
+	for _, to := range f.To {
+		if *tx.To() == to {
+			return true
+		}
+	}
+	return false
+}
+
+// MempoolBidderConfig holds the configuration settings for a MempoolBidder.
+type MempoolBidderConfig struct {
+	Filter      MempoolFilter // Selects the transactions to bid on.
+	Strategy    BidStrategy   // Decides the bid amounts and learns from their outcomes. Required.
+	Offset      uint64        // Blocks after the latest one the bids target. Zero uses 1.
+	Decay       time.Duration // How long bids decay for. Zero uses DefaultMempoolBidDecay.
+	MinInterval time.Duration // The shortest time between two bids; transactions arriving sooner are skipped. Zero does not limit the rate.
+	MaxInFlight int           // Bids that may wait for their commitments at once; transactions arriving while all are busy are skipped. Zero uses DefaultMempoolMaxInFlight.
+	Dedup       *BidDeduper   // Skips transactions already bid on. If nil, one with DefaultBidDedupTTL is used.
+}
+
+// MempoolBid is the outcome of a bid on a mempool transaction.
+type MempoolBid struct {
+	TxHash      common.Hash // The transaction bid on.
+	TargetBlock uint64      // The block the bid targets.
+	Amount      *big.Int    // The bid amount in wei.
+	Result      *BidResult  // The result of the bid, nil if it could not be sent.
+	Err         error       // Why the bid failed.
+}
+
+// MempoolBidder bids on transactions observed in a node's mempool, by hash. Transactions are
+// selected by a MempoolFilter, each is bid on at most once within the de-duplicator's TTL, and
+// the rate of bids is bounded by a minimum interval and a number of bids in flight.
+type MempoolBidder struct {
+	bidder   *Bidder          // The bidder the bids are sent with.
+	source   PendingTxSource  // The source of the mempool transactions.
+	heads    HeadReader       // Reports the latest block.
+	filter   MempoolFilter    // Selects the transactions to bid on.
+	strategy BidStrategy      // Decides the bid amounts.
+	offset   uint64           // Blocks after the latest one the bids target.
+	decay    time.Duration    // How long bids decay for.
+	dedup    *BidDeduper      // Skips transactions already bid on.
+	now      func() time.Time // Source of the current time.
+	slots    chan struct{}    // Holds a token per bid in flight.
+	minGap   time.Duration    // The shortest time between two bids.
+	lastBid  time.Time        // When the last bid was started.
+}
+
+// NewMempoolBidder creates a MempoolBidder.
+//
+// Parameters:
+// - bidder: The bidder the bids are sent with.
+// - source: The source of the mempool transactions.
+// - heads: Reports the latest block, which bids target Offset blocks after.
+// - cfg: The MempoolBidderConfig struct containing the filter, strategy and limits.
+//
+// Returns:
+// - A pointer to a MempoolBidder, or an error if the configuration is invalid.
+func NewMempoolBidder(bidder *Bidder, source PendingTxSource, heads HeadReader, cfg MempoolBidderConfig) (*MempoolBidder, error) {
+	if cfg.Strategy == nil {
+		return nil, ErrNoBidStrategy
+	}
+	if cfg.Filter.MinValue != nil && cfg.Filter.MinValue.Sign() < 0 {
+		return nil, fmt.Errorf("minimum value must not be negative, got %s", cfg.Filter.MinValue)
+	}
+	if cfg.MinInterval < 0 || cfg.MaxInFlight < 0 {
+		return nil, fmt.Errorf("rate limits must not be negative, got interval %s and %d in flight", cfg.MinInterval, cfg.MaxInFlight)
+	}
+
+	offset := cfg.Offset
+	if offset == 0 {
+		offset = 1
+	}
+	decay := cfg.Decay
+	if decay <= 0 {
+		decay = DefaultMempoolBidDecay
+	}
+	maxInFlight := cfg.MaxInFlight
+	if maxInFlight == 0 {
+		maxInFlight = DefaultMempoolMaxInFlight
+	}
+	dedup := cfg.Dedup
+	if dedup == nil {
+		dedup = NewBidDeduper(DefaultBidDedupTTL, 0)
+	}
+
+	return &MempoolBidder{
+		bidder:   bidder,
+		source:   source,
+		heads:    heads,
+		filter:   cfg.Filter,
+		strategy: cfg.Strategy,
+		offset:   offset,
+		decay:    decay,
+		dedup:    dedup,
+		now:      time.Now,
+		slots:    make(chan struct{}, maxInFlight),
+		minGap:   cfg.MinInterval,
+	}, nil
+}
+
+// Run subscribes to the node's pending transactions and bids on each selected one by hash until
+// the context is cancelled or the subscription fails. Bids are sent in the background; Run waits
+// for the ones in flight before returning.
+//
+// Parameters:
+// - ctx: The context controlling the subscription.
+// - out: The channel the outcome of each bid is sent to, or nil to only log them.
+//
+// Returns:
+// - The subscription error, or the context error once the context is cancelled.
+func (m *MempoolBidder) Run(ctx context.Context, out chan<- MempoolBid) error {
+	txs := make(chan *types.Transaction)
+	sub, err := m.source.SubscribePendingTransactions(ctx, txs)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to pending transactions: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("pending transaction subscription failed: %w", err)
+		case tx := <-txs:
+			if !m.admit(tx) {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-m.slots }()
+
+				bid := m.bid(ctx, tx.Hash())
+				if bid.Err != nil {
+					logger.Warn("Mempool bid failed", "tx", bid.TxHash, "block", bid.TargetBlock, "amount", bid.Amount, "error", bid.Err)
+				} else {
+					logger.Info("Mempool bid sent", "tx", bid.TxHash, "block", bid.TargetBlock, "amount", bid.Amount, "commitments", len(bid.Result.Commitments))
+				}
+				if out != nil {
+					select {
+					case out <- bid:
+					case <-ctx.Done():
+					}
+				}
+			}()
+		}
+	}
+}
+
+// admit reports whether a bid on tx may start now, and if so takes a slot for it. Transactions
+// that don't pass the filter, were already bid on, arrive within the minimum interval of the
+// last bid or while every slot is taken are skipped. Only the Run loop calls it.
+func (m *MempoolBidder) admit(tx *types.Transaction) bool {
+	if !m.filter.Match(tx) {
+		return false
+	}
+	now := m.now()
+	if m.minGap > 0 && !m.lastBid.IsZero() && now.Sub(m.lastBid) < m.minGap {
+		logger.Debug("Skipping mempool transaction, bid rate limit reached", "tx", tx.Hash())
+		return false
+	}
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		logger.Debug("Skipping mempool transaction, too many bids in flight", "tx", tx.Hash())
+		return false
+	}
+	// Record the bid only once it is admitted, so a skipped transaction can still be bid on later
+	if !m.dedup.Observe(tx.Hash()) {
+		<-m.slots
+		return false
+	}
+	m.lastBid = now
+	return true
+}
+
+// bid bids on the transaction with the given hash for the block offset blocks after the latest
+// one, and reports the outcome to the strategy.
+func (m *MempoolBidder) bid(ctx context.Context, txHash common.Hash) MempoolBid {
+	bid := MempoolBid{TxHash: txHash}
+
+	head, err := m.heads.BlockNumber(ctx)
+	if err != nil {
+		bid.Err = fmt.Errorf("failed to get latest block: %w", err)
+		return bid
+	}
+	bid.TargetBlock = head + m.offset
+
+	bid.Amount, err = m.strategy.BidAmount(bid.TargetBlock)
+	if err != nil {
+		bid.Err = fmt.Errorf("failed to choose bid amount: %w", err)
+		return bid
+	}
+
+	start := m.now()
+	bid.Result, bid.Err = m.bidder.SendBidAndCollect(ctx, []string{txHash.Hex()}, bid.Amount.String(), int64(bid.TargetBlock), start.UnixMilli(), start.Add(m.decay).UnixMilli())
+	m.strategy.Observe(bid.TargetBlock, bid.Err == nil)
+	return bid
+}
@@ -0,0 +1,224 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakePendingTxSource hands its subscriber's channel to the test.
+type fakePendingTxSource struct {
+	subscribed chan chan<- *types.Transaction
+	sub        fakeSubscription
+}
+
+func newFakePendingTxSource() *fakePendingTxSource {
+	return &fakePendingTxSource{subscribed: make(chan chan<- *types.Transaction, 1), sub: fakeSubscription{err: make(chan error, 1)}}
+}
+
+func (s *fakePendingTxSource) SubscribePendingTransactions(_ context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error) {
+	s.subscribed <- ch
+	return s.sub, nil
+}
+
+// fakeHeads reports a fixed latest block.
+type fakeHeads uint64
+
+func (h fakeHeads) BlockNumber(context.Context) (uint64, error) { return uint64(h), nil }
+
+// mempoolTx returns a transfer of value wei to to, made distinct by nonce.
+func mempoolTx(nonce uint64, to *common.Address, value int64) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{Nonce: nonce, To: to, Value: big.NewInt(value), Gas: 21000, GasPrice: big.NewInt(1)})
+}
+
+// fixedStrategy returns a strategy always bidding amount wei.
+func fixedStrategy(t *testing.T, amount int64) BidStrategy {
+	t.Helper()
+	strategy, err := NewRandomBidStrategy(big.NewInt(amount), big.NewInt(amount))
+	if err != nil {
+		t.Fatalf("NewRandomBidStrategy: %v", err)
+	}
+	return strategy
+}
+
+func TestMempoolFilterMatch(t *testing.T) {
+	target := common.Address{0xaa}
+	other := common.Address{0xbb}
+	tests := []struct {
+		name   string
+		filter MempoolFilter
+		tx     *types.Transaction
+		want   bool
+	}{
+		{name: "no filter", tx: mempoolTx(0, &other, 0), want: true},
+		{name: "value at minimum", filter: MempoolFilter{MinValue: big.NewInt(10)}, tx: mempoolTx(0, &other, 10), want: true},
+		{name: "value below minimum", filter: MempoolFilter{MinValue: big.NewInt(10)}, tx: mempoolTx(0, &other, 9)},
+		{name: "recipient", filter: MempoolFilter{To: []common.Address{other, target}}, tx: mempoolTx(0, &target, 0), want: true},
+		{name: "other recipient", filter: MempoolFilter{To: []common.Address{target}}, tx: mempoolTx(0, &other, 0)},
+		{name: "contract creation", filter: MempoolFilter{To: []common.Address{target}}, tx: mempoolTx(0, nil, 0)},
+		{name: "both", filter: MempoolFilter{MinValue: big.NewInt(10), To: []common.Address{target}}, tx: mempoolTx(0, &target, 9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.tx); got != tt.want {
+				t.Fatalf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMempoolBidder(t *testing.T) {
+	strategy := fixedStrategy(t, 1)
+	tests := []struct {
+		name    string
+		cfg     MempoolBidderConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: MempoolBidderConfig{Strategy: strategy}},
+		{name: "no strategy", cfg: MempoolBidderConfig{}, wantErr: true},
+		{name: "negative minimum value", cfg: MempoolBidderConfig{Strategy: strategy, Filter: MempoolFilter{MinValue: big.NewInt(-1)}}, wantErr: true},
+		{name: "negative interval", cfg: MempoolBidderConfig{Strategy: strategy, MinInterval: -time.Second}, wantErr: true},
+		{name: "negative in flight", cfg: MempoolBidderConfig{Strategy: strategy, MaxInFlight: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMempoolBidder(nil, newFakePendingTxSource(), fakeHeads(1), tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMempoolBidder error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (m.offset != 1 || m.decay != DefaultMempoolBidDecay || cap(m.slots) != DefaultMempoolMaxInFlight) {
+				t.Fatalf("defaults = offset %d, decay %s, %d in flight", m.offset, m.decay, cap(m.slots))
+			}
+		})
+	}
+}
+
+func TestMempoolBidderRun(t *testing.T) {
+	server := &fakeBidderServer{}
+	source := newFakePendingTxSource()
+	target := common.Address{0xaa}
+	m, err := NewMempoolBidder(dialFakeBidder(t, server), source, fakeHeads(100), MempoolBidderConfig{
+		Filter:   MempoolFilter{MinValue: big.NewInt(5), To: []common.Address{target}},
+		Strategy: fixedStrategy(t, 42),
+		Offset:   2,
+	})
+	if err != nil {
+		t.Fatalf("NewMempoolBidder: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan MempoolBid)
+	runErr := make(chan error, 1)
+	go func() { runErr <- m.Run(ctx, out) }()
+
+	txs := <-source.subscribed
+	selected := mempoolTx(1, &target, 5)
+	txs <- mempoolTx(0, &target, 4) // below the minimum value
+	txs <- selected
+	txs <- selected // a duplicate
+
+	bid := <-out
+	if bid.Err != nil {
+		t.Fatalf("bid failed: %v", bid.Err)
+	}
+	if bid.TxHash != selected.Hash() || bid.TargetBlock != 102 || bid.Amount.Int64() != 42 || len(bid.Result.Commitments) != 1 {
+		t.Fatalf("bid = %+v", bid)
+	}
+
+	cancel()
+	if err := <-runErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	bids := server.received()
+	if len(bids) != 1 {
+		t.Fatalf("received %d bids, want 1", len(bids))
+	}
+	if got := bids[0]; len(got.TxHashes) != 1 || got.TxHashes[0] != strings.TrimPrefix(selected.Hash().Hex(), "0x") || got.Amount != "42" || got.BlockNumber != 102 || len(got.RawTransactions) != 0 {
+		t.Fatalf("bid request = %+v", got)
+	}
+	if decay := bids[0].DecayEndTimestamp - bids[0].DecayStartTimestamp; decay != DefaultMempoolBidDecay.Milliseconds() {
+		t.Fatalf("decay = %dms, want %dms", decay, DefaultMempoolBidDecay.Milliseconds())
+	}
+}
+
+func TestMempoolBidderBidFollowsContext(t *testing.T) {
+	b := dialFakeBidder(t, hungBidderServer{})
+	m, err := NewMempoolBidder(b, newFakePendingTxSource(), fakeHeads(100), MempoolBidderConfig{Strategy: fixedStrategy(t, 42)})
+	if err != nil {
+		t.Fatalf("NewMempoolBidder: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan MempoolBid, 1)
+	go func() { done <- m.bid(ctx, common.Hash{0xab}) }()
+	select {
+	case bid := <-done:
+		if bid.Err == nil {
+			t.Fatal("bid on a hung bidder node succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("bid did not return once its context was done")
+	}
+}
+
+func TestMempoolBidderRunSubscriptionError(t *testing.T) {
+	source := newFakePendingTxSource()
+	m, err := NewMempoolBidder(nil, source, fakeHeads(1), MempoolBidderConfig{Strategy: fixedStrategy(t, 1)})
+	if err != nil {
+		t.Fatalf("NewMempoolBidder: %v", err)
+	}
+	source.sub.err <- errors.New("connection lost")
+	if err := m.Run(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Fatalf("Run = %v, want the subscription error", err)
+	}
+}
+
+func TestMempoolBidderAdmit(t *testing.T) {
+	m, err := NewMempoolBidder(nil, newFakePendingTxSource(), fakeHeads(1), MempoolBidderConfig{
+		Strategy:    fixedStrategy(t, 1),
+		MinInterval: time.Second,
+		MaxInFlight: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewMempoolBidder: %v", err)
+	}
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	to := common.Address{1}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		tx      *types.Transaction
+		release bool // Free a slot first, as a finished bid does.
+		want    bool
+	}{
+		{name: "first", tx: mempoolTx(0, &to, 0), want: true},
+		{name: "within the interval", advance: 500 * time.Millisecond, tx: mempoolTx(1, &to, 0)},
+		{name: "after the interval", advance: 500 * time.Millisecond, tx: mempoolTx(1, &to, 0), want: true},
+		{name: "all slots taken", advance: time.Second, tx: mempoolTx(2, &to, 0)},
+		{name: "slot freed", tx: mempoolTx(2, &to, 0), release: true, want: true},
+		{name: "duplicate", advance: time.Second, tx: mempoolTx(0, &to, 0), release: true},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.release {
+			<-m.slots
+		}
+		if got := m.admit(step.tx); got != step.want {
+			t.Fatalf("%s: admit = %v, want %v", step.name, got, step.want)
+		}
+	}
+	if len(m.slots) != 1 {
+		t.Fatalf("%d slots taken after a rejected duplicate, want 1", len(m.slots))
+	}
+}
@@ -0,0 +1,26 @@
+package mevcommit
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Metric names reported by the bidder. They are only collected when go-ethereum metrics are enabled.
+const (
+	firstCommitmentMetric = "mevcommit/bid/firstcommitment"
+	noCommitmentMetric    = "mevcommit/bid/nocommitment"
+)
+
+// recordFirstCommitment adds the time from bid submission to the first commitment, in
+// milliseconds, to the first commitment latency histogram.
+func recordFirstCommitment(latency time.Duration) {
+	metrics.GetOrRegisterHistogramLazy(firstCommitmentMetric, nil, func() metrics.Sample {
+		return metrics.NewExpDecaySample(1028, 0.015)
+	}).Update(latency.Milliseconds())
+}
+
+// recordNoCommitment counts a bid whose response stream ended without any commitment.
+func recordNoCommitment() {
+	metrics.GetOrRegisterCounter(noCommitmentMetric, nil).Inc(1)
+}
@@ -0,0 +1,110 @@
+package mevcommit
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrInvalidMnemonic is returned by AuthenticateMnemonic when the mnemonic is not a valid
+// BIP-39 mnemonic.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// AuthenticateMnemonic derives count accounts from a BIP-39 mnemonic, as BIP-32 children of the
+// given derivation path, so account i is derived at path/i. The derivation is deterministic: the
+// same mnemonic and path always give the same accounts.
+//
+// Parameters:
+// - mnemonic: The BIP-39 mnemonic, without a passphrase.
+// - path: The root derivation path, e.g. m/44'/60'/0'/0. An empty path uses m/44'/60'/0'/0.
+// - count: The number of accounts to derive.
+//
+// Returns:
+// - The derived accounts, or an error if the mnemonic or path is invalid.
+func AuthenticateMnemonic(mnemonic, path string, count int) ([]AuthAcct, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	root := accounts.DefaultRootDerivationPath
+	if path != "" {
+		var err error
+		root, err = accounts.ParseDerivationPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %q: %w", path, err)
+		}
+	}
+
+	seed := bip39.NewSeed(mnemonic, "")
+	key, chainCode := masterKey(seed)
+	for _, index := range root {
+		var err error
+		key, chainCode, err = childKey(key, chainCode, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", root, err)
+		}
+	}
+
+	accts := make([]AuthAcct, 0, count)
+	for i := 0; i < count; i++ {
+		childKeyBytes, _, err := childKey(key, chainCode, uint32(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive account %d: %w", i, err)
+		}
+		acct, err := AuthenticateAddress(hex.EncodeToString(childKeyBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to authenticate account %d: %w", i, err)
+		}
+		accts = append(accts, acct)
+	}
+	return accts, nil
+}
+
+// masterKey derives the BIP-32 master private key and chain code from a seed.
+func masterKey(seed []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+// childKey derives the BIP-32 child private key and chain code at index. Indexes of 0x80000000
+// and above derive hardened children.
+func childKey(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0}, key...)
+	} else {
+		priv, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(priv.Public().(*ecdsa.PublicKey))
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	return child.FillBytes(make([]byte, 32)), sum[32:], nil
+}
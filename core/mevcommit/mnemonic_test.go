@@ -0,0 +1,112 @@
+package mevcommit
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testMnemonic is the well-known development mnemonic whose first account is testPrivateKey.
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestAuthenticateMnemonic(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		path     string
+		count    int
+		want     []string // The addresses of the derived accounts.
+		wantErr  error
+	}{
+		{
+			name:     "default path",
+			mnemonic: testMnemonic,
+			count:    3,
+			want: []string{
+				"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+				"0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+				"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC",
+			},
+		},
+		{
+			name:     "explicit default path",
+			mnemonic: testMnemonic,
+			path:     "m/44'/60'/0'/0",
+			count:    1,
+			want:     []string{"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"},
+		},
+		{name: "no accounts", mnemonic: testMnemonic, count: 0, want: []string{}},
+		{name: "bad checksum", mnemonic: "test test test test test test test test test test test test", count: 1, wantErr: ErrInvalidMnemonic},
+		{name: "not a mnemonic", mnemonic: "not a mnemonic", count: 1, wantErr: ErrInvalidMnemonic},
+		{name: "invalid path", mnemonic: testMnemonic, path: "m/x", count: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accts, err := AuthenticateMnemonic(tt.mnemonic, tt.path, tt.count)
+			if tt.want == nil {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("AuthenticateMnemonic error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateMnemonic: %v", err)
+			}
+			if len(accts) != len(tt.want) {
+				t.Fatalf("%d accounts, want %d", len(accts), len(tt.want))
+			}
+			for i, acct := range accts {
+				if acct.Address != common.HexToAddress(tt.want[i]) {
+					t.Errorf("account %d = %s, want %s", i, acct.Address, tt.want[i])
+				}
+			}
+		})
+	}
+
+	// The first account is the well-known development key
+	accts, err := AuthenticateMnemonic(testMnemonic, "", 1)
+	if err != nil {
+		t.Fatalf("AuthenticateMnemonic: %v", err)
+	}
+	if got := hex.EncodeToString(crypto.FromECDSA(accts[0].PrivateKey)); got != testPrivateKey {
+		t.Fatalf("private key = %s, want %s", got, testPrivateKey)
+	}
+}
+
+// TestChildKey checks the BIP-32 derivation against test vector 1 of the specification.
+func TestChildKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	key, chainCode := masterKey(seed)
+	if got := hex.EncodeToString(key); got != "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35" {
+		t.Fatalf("master key = %s", got)
+	}
+	if got := hex.EncodeToString(chainCode); got != "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508" {
+		t.Fatalf("master chain code = %s", got)
+	}
+
+	tests := []struct {
+		index uint32
+		key   string
+		chain string
+	}{
+		{index: 0x80000000, key: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea", chain: "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141"},
+		{index: 1, key: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", chain: "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19"},
+	}
+	for _, tt := range tests {
+		// Each vector derives from the previous one: m/0H, then m/0H/1
+		var err error
+		key, chainCode, err = childKey(key, chainCode, tt.index)
+		if err != nil {
+			t.Fatalf("childKey(%#x): %v", tt.index, err)
+		}
+		if got := hex.EncodeToString(key); got != tt.key {
+			t.Errorf("key at %#x = %s, want %s", tt.index, got, tt.key)
+		}
+		if got := hex.EncodeToString(chainCode); got != tt.chain {
+			t.Errorf("chain code at %#x = %s, want %s", tt.index, got, tt.chain)
+		}
+	}
+}
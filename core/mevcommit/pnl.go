@@ -0,0 +1,121 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrSettlementEventsUnsupported is returned when the BidderRegistry ABI does not define the
+// settlement events, as with contract versions that predate them.
+var ErrSettlementEventsUnsupported = errors.New("BidderRegistry does not emit settlement events")
+
+// BidderPnL summarises how a bidder's commitments were settled over a range of bidding windows.
+type BidderPnL struct {
+	FromWindow *big.Int // The first window of the range.
+	ToWindow   *big.Int // The last window of the range, inclusive.
+	Rewards    *big.Int // Wei paid to providers for commitments they delivered.
+	Slashes    *big.Int // Wei returned to the bidder for commitments whose provider was slashed.
+	Rewarded   int      // Number of commitments whose provider was paid.
+	Slashed    int      // Number of commitments whose bid was returned.
+}
+
+// GetBidderRewards returns the amount the bidder paid providers for delivered commitments in a
+// window. The BidderRegistry keeps no running total, so it is summed from FundsRewarded logs.
+//
+// Parameters:
+// - client: The client connected to the mev-commit chain.
+// - address: The bidder address.
+// - window: The bidding window.
+//
+// Returns:
+// - The amount in wei, or an error if the logs cannot be read.
+func GetBidderRewards(client *ethclient.Client, address common.Address, window *big.Int) (*big.Int, error) {
+	pnl, err := GetBidderPnL(client, address, window, window)
+	if err != nil {
+		return nil, err
+	}
+	return pnl.Rewards, nil
+}
+
+// GetBidderSlashes returns the amount returned to the bidder in a window for commitments whose
+// provider was slashed. It is summed from FundsRetrieved logs.
+//
+// Parameters:
+// - client: The client connected to the mev-commit chain.
+// - address: The bidder address.
+// - window: The bidding window.
+//
+// Returns:
+// - The amount in wei, or an error if the logs cannot be read.
+func GetBidderSlashes(client *ethclient.Client, address common.Address, window *big.Int) (*big.Int, error) {
+	pnl, err := GetBidderPnL(client, address, window, window)
+	if err != nil {
+		return nil, err
+	}
+	return pnl.Slashes, nil
+}
+
+// GetBidderPnL sums the settlements of the bidder's commitments in the windows fromWindow to
+// toWindow inclusive.
+//
+// Parameters:
+// - client: The client connected to the mev-commit chain.
+// - address: The bidder address.
+// - fromWindow: The first window of the range.
+// - toWindow: The last window of the range, inclusive.
+//
+// Returns:
+// - The summary, ErrSettlementEventsUnsupported for older contracts, or an error if the logs cannot be read.
+func GetBidderPnL(client *ethclient.Client, address common.Address, fromWindow, toWindow *big.Int) (*BidderPnL, error) {
+	if fromWindow.Cmp(toWindow) > 0 {
+		return nil, fmt.Errorf("window range is empty: %s > %s", fromWindow, toWindow)
+	}
+
+	l, err := NewSettlementListener(client, address)
+	if err != nil {
+		return nil, err
+	}
+	rewarded, hasRewarded := l.abi.Events["FundsRewarded"]
+	retrieved, hasRetrieved := l.abi.Events["FundsRetrieved"]
+	if !hasRewarded || !hasRetrieved {
+		return nil, ErrSettlementEventsUnsupported
+	}
+
+	// The window is not indexed, so every settlement of the bidder is read and filtered here
+	ctx, cancel := context.WithTimeout(context.Background(), contractCallTimeout)
+	defer cancel()
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{l.address},
+		Topics:    [][]common.Hash{{rewarded.ID, retrieved.ID}, nil, {common.BytesToHash(address.Bytes())}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter settlement logs: %w", err)
+	}
+
+	pnl := &BidderPnL{FromWindow: fromWindow, ToWindow: toWindow, Rewards: new(big.Int), Slashes: new(big.Int)}
+	for _, vLog := range logs {
+		event, err := l.decode(vLog)
+		if err != nil {
+			logger.Warn("Failed to unpack settlement log", "tx", vLog.TxHash, "error", err)
+			continue
+		}
+		if event.Window.Cmp(fromWindow) < 0 || event.Window.Cmp(toWindow) > 0 {
+			continue
+		}
+		switch event.Outcome {
+		case SettlementRewarded:
+			pnl.Rewards.Add(pnl.Rewards, event.Amount)
+			pnl.Rewarded++
+		case SettlementRetrieved:
+			pnl.Slashes.Add(pnl.Slashes, event.Amount)
+			pnl.Slashed++
+		}
+	}
+	return pnl, nil
+}
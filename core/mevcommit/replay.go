@@ -0,0 +1,128 @@
+package mevcommit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+// ReplayRecord holds everything needed to reproduce a failed bid cycle.
+type ReplayRecord struct {
+	Timestamp   int64   `json:"timestamp"`    // Unix time at which the cycle failed.
+	RawTx       string  `json:"raw_tx"`       // The hex-encoded signed transaction.
+	TxHash      string  `json:"tx_hash"`      // The hash of the signed transaction.
+	BidRequest  *pb.Bid `json:"bid_request"`  // The bid request sent (or about to be sent) to the bidder node.
+	TargetBlock uint64  `json:"target_block"` // The L1 block number the bid targeted.
+	BaseFee     string  `json:"base_fee"`     // The base fee in wei of the head the transaction was built on.
+	ChainID     string  `json:"chain_id"`     // The chain ID the transaction was signed for.
+	Error       string  `json:"error"`        // The error that caused the cycle to fail.
+}
+
+// ReplayRecorder writes one ReplayRecord file per failed bid cycle into a directory.
+type ReplayRecorder struct {
+	dir string // The directory recordings are written to.
+}
+
+// RecordForReplay creates a ReplayRecorder that writes recordings into dir.
+//
+// Parameters:
+// - dir: The directory to write recordings to. It is created on first use.
+//
+// Returns:
+// - A pointer to a ReplayRecorder.
+func RecordForReplay(dir string) *ReplayRecorder {
+	return &ReplayRecorder{dir: dir}
+}
+
+// Record writes a recording of a failed bid cycle to its own JSON file.
+//
+// Parameters:
+// - rec: The recording to write.
+//
+// Returns:
+// - The path of the written file, or an error if it could not be written.
+func (r *ReplayRecorder) Record(rec *ReplayRecord) (string, error) {
+	// Ensure the directory exists
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create replay directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode replay record: %w", err)
+	}
+
+	name := fmt.Sprintf("replay-%d-%d-%s.json", rec.TargetBlock, rec.Timestamp, strings.TrimPrefix(rec.TxHash, "0x"))
+	path := filepath.Join(r.dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write replay record: %w", err)
+	}
+
+	logger.Info("Recorded failed bid cycle for replay", "file", path)
+	return path, nil
+}
+
+// LoadReplayRecord reads a recording written by ReplayRecorder.Record.
+//
+// Parameters:
+// - path: The path of the recording file.
+//
+// Returns:
+// - A pointer to the loaded ReplayRecord, or an error if it could not be read or decoded.
+func LoadReplayRecord(path string) (*ReplayRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay record: %w", err)
+	}
+
+	var rec ReplayRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to decode replay record: %w", err)
+	}
+	return &rec, nil
+}
+
+// Transaction reconstructs the signed transaction stored in the recording.
+//
+// Returns:
+// - The decoded transaction, or an error if the recording holds no valid transaction.
+func (rec *ReplayRecord) Transaction() (*types.Transaction, error) {
+	if rec.RawTx == "" {
+		return nil, fmt.Errorf("replay record has no transaction")
+	}
+
+	raw, err := hexutil.Decode(rec.RawTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode raw transaction: %w", err)
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// Replay re-sends the bid request stored in a recording to the mev-commit bidder node.
+//
+// Parameters:
+// - ctx: The context bounding the call, see SendBidRequest.
+// - rec: The recording to replay.
+//
+// Returns:
+// - The result of the bid, or an error if the recording holds no bid or the bid fails.
+func (b *Bidder) Replay(ctx context.Context, rec *ReplayRecord) (*BidResult, error) {
+	if rec.BidRequest == nil {
+		return nil, fmt.Errorf("replay record has no bid request")
+	}
+
+	b.logger.Info("Replaying recorded bid", "txHash", rec.TxHash, "block", rec.BidRequest.BlockNumber)
+	return b.SendBidRequest(ctx, rec.BidRequest)
+}
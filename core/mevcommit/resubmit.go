@@ -0,0 +1,92 @@
+package mevcommit
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+// ValidateBid checks that a bid request is well formed: a positive amount in wei, a target
+// block, either transaction hashes or raw transactions that decode, and a decay window that ends
+// after it starts.
+//
+// Parameters:
+// - bid: The bid request to check.
+//
+// Returns:
+// - An error describing the first problem found, or nil if the bid is well formed.
+func ValidateBid(bid *pb.Bid) error {
+	if bid == nil {
+		return errors.New("bid request is missing")
+	}
+	amount, ok := new(big.Int).SetString(bid.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return fmt.Errorf("invalid bid amount '%s'", bid.Amount)
+	}
+	if bid.BlockNumber <= 0 {
+		return fmt.Errorf("invalid block number %d", bid.BlockNumber)
+	}
+	if err := ValidateDecayWindow(bid.DecayStartTimestamp, bid.DecayEndTimestamp); err != nil {
+		return err
+	}
+
+	switch {
+	case len(bid.TxHashes) > 0 && len(bid.RawTransactions) > 0:
+		return errors.New("bid has both transaction hashes and raw transactions")
+	case len(bid.TxHashes) > 0:
+		for i, hash := range bid.TxHashes {
+			decoded, err := hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+			if err != nil || len(decoded) != 32 {
+				return fmt.Errorf("invalid transaction hash %d '%s'", i, hash)
+			}
+		}
+	case len(bid.RawTransactions) > 0:
+		for i, raw := range bid.RawTransactions {
+			decoded, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+			if err != nil {
+				return fmt.Errorf("raw transaction %d is not hex: %w", i, err)
+			}
+			if err := new(types.Transaction).UnmarshalBinary(decoded); err != nil {
+				return fmt.Errorf("failed to decode raw transaction %d: %w", i, err)
+			}
+		}
+	default:
+		return errors.New("bid has no transactions")
+	}
+	return nil
+}
+
+// ReconstructBids rebuilds the bid requests of saved bids, as read by LoadBidRequests, so they
+// can be sent again with SendBidRequest. Malformed entries are skipped.
+//
+// Parameters:
+// - saved: The saved bid requests.
+//
+// Returns:
+// - The well-formed bid requests in their saved order, and the joined problems of the skipped entries.
+func ReconstructBids(saved []SavedBidRequest) ([]*pb.Bid, error) {
+	bids := make([]*pb.Bid, 0, len(saved))
+	var errs []error
+	for i, entry := range saved {
+		if err := ValidateBid(entry.BidRequest); err != nil {
+			errs = append(errs, fmt.Errorf("entry %d: %w", i, err))
+			continue
+		}
+		bid := entry.BidRequest
+		bids = append(bids, &pb.Bid{
+			TxHashes:            append([]string(nil), bid.TxHashes...),
+			Amount:              bid.Amount,
+			BlockNumber:         bid.BlockNumber,
+			DecayStartTimestamp: bid.DecayStartTimestamp,
+			DecayEndTimestamp:   bid.DecayEndTimestamp,
+			RevertingTxHashes:   append([]string(nil), bid.RevertingTxHashes...),
+			RawTransactions:     append([]string(nil), bid.RawTransactions...),
+		})
+	}
+	return bids, errors.Join(errs...)
+}
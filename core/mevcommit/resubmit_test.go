@@ -0,0 +1,105 @@
+package mevcommit
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+func TestValidateBid(t *testing.T) {
+	rawTx, err := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1)}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	hash := common.Hash{1}.Hex()
+	valid := func(modify func(bid *pb.Bid)) *pb.Bid {
+		bid := &pb.Bid{TxHashes: []string{hash}, Amount: "1000", BlockNumber: 10, DecayStartTimestamp: 1, DecayEndTimestamp: 2}
+		if modify != nil {
+			modify(bid)
+		}
+		return bid
+	}
+	tests := []struct {
+		name    string
+		bid     *pb.Bid
+		wantErr string
+	}{
+		{name: "hashes", bid: valid(nil)},
+		{name: "hash without prefix", bid: valid(func(bid *pb.Bid) { bid.TxHashes = []string{hash[2:]} })},
+		{name: "raw transactions", bid: valid(func(bid *pb.Bid) { bid.TxHashes, bid.RawTransactions = nil, []string{hexutil.Encode(rawTx)} })},
+		{name: "missing", wantErr: "missing"},
+		{name: "zero amount", bid: valid(func(bid *pb.Bid) { bid.Amount = "0" }), wantErr: "invalid bid amount"},
+		{name: "decimal amount", bid: valid(func(bid *pb.Bid) { bid.Amount = "1.5" }), wantErr: "invalid bid amount"},
+		{name: "no block", bid: valid(func(bid *pb.Bid) { bid.BlockNumber = 0 }), wantErr: "invalid block number"},
+		{name: "instant decay", bid: valid(func(bid *pb.Bid) { bid.DecayEndTimestamp = bid.DecayStartTimestamp }), wantErr: "decay ends"},
+		{name: "decay ends first", bid: valid(func(bid *pb.Bid) { bid.DecayEndTimestamp = 0 }), wantErr: "decay ends"},
+		{name: "no transactions", bid: valid(func(bid *pb.Bid) { bid.TxHashes = nil }), wantErr: "no transactions"},
+		{name: "both", bid: valid(func(bid *pb.Bid) { bid.RawTransactions = []string{hexutil.Encode(rawTx)} }), wantErr: "both"},
+		{name: "short hash", bid: valid(func(bid *pb.Bid) { bid.TxHashes = []string{"0x01"} }), wantErr: "invalid transaction hash"},
+		{name: "raw not hex", bid: valid(func(bid *pb.Bid) { bid.TxHashes, bid.RawTransactions = nil, []string{"0xzz"} }), wantErr: "not hex"},
+		{name: "raw not a transaction", bid: valid(func(bid *pb.Bid) { bid.TxHashes, bid.RawTransactions = nil, []string{"0x0102"} }), wantErr: "failed to decode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBid(tt.bid)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ValidateBid: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ValidateBid error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconstructBids(t *testing.T) {
+	first := &pb.Bid{TxHashes: []string{common.Hash{1}.Hex()}, Amount: "1", BlockNumber: 10, DecayStartTimestamp: 1, DecayEndTimestamp: 2, RevertingTxHashes: []string{common.Hash{1}.Hex()}}
+	second := &pb.Bid{TxHashes: []string{common.Hash{2}.Hex()}, Amount: "2", BlockNumber: 11, DecayStartTimestamp: 5, DecayEndTimestamp: 9}
+	tests := []struct {
+		name      string
+		saved     []SavedBidRequest
+		want      []*pb.Bid
+		wantErrOn []string // The entries reported as skipped.
+	}{
+		{name: "none", want: []*pb.Bid{}},
+		{name: "valid", saved: []SavedBidRequest{{BidRequest: first}, {BidRequest: second}}, want: []*pb.Bid{first, second}},
+		{
+			name:      "malformed skipped",
+			saved:     []SavedBidRequest{{}, {BidRequest: first}, {BidRequest: &pb.Bid{Amount: "1", BlockNumber: 1}}, {BidRequest: second}},
+			want:      []*pb.Bid{first, second},
+			wantErrOn: []string{"entry 0", "entry 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bids, err := ReconstructBids(tt.saved)
+			if (err != nil) != (len(tt.wantErrOn) > 0) {
+				t.Fatalf("ReconstructBids error = %v, want errors on %v", err, tt.wantErrOn)
+			}
+			for _, entry := range tt.wantErrOn {
+				if !strings.Contains(err.Error(), entry) {
+					t.Fatalf("ReconstructBids error = %v, want it to mention %s", err, entry)
+				}
+			}
+			if len(bids) != len(tt.want) {
+				t.Fatalf("%d bids, want %d", len(bids), len(tt.want))
+			}
+			for i, bid := range bids {
+				want := tt.want[i]
+				if bid == want {
+					t.Fatalf("bid %d is the saved request, want a copy", i)
+				}
+				if !reflect.DeepEqual([]interface{}{bid.TxHashes, bid.Amount, bid.BlockNumber, bid.DecayStartTimestamp, bid.DecayEndTimestamp, bid.RevertingTxHashes, bid.RawTransactions},
+					[]interface{}{want.TxHashes, want.Amount, want.BlockNumber, want.DecayStartTimestamp, want.DecayEndTimestamp, want.RevertingTxHashes, want.RawTransactions}) {
+					t.Fatalf("bid %d = %v, want %v", i, bid, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultBidRetryBackoff is the wait before the first bid retry. It doubles on each retry.
+const DefaultBidRetryBackoff = 250 * time.Millisecond
+
+// ErrRetryBudgetExhausted is returned with the last error of a bid that was not retried because
+// the retry budget it shares with the rest of its cycle ran out.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget bounds the retries made by all operations of a bid cycle, so failures in several
+// steps cannot add up to a long stall. It limits both the number of retries and the total time
+// spent waiting between them. A nil RetryBudget allows any retry. It is safe for concurrent use.
+type RetryBudget struct {
+	mu         sync.Mutex
+	retries    int           // Retries left, when maxRetries is set.
+	wait       time.Duration // Backoff time left, when maxWait is set.
+	maxRetries bool
+	maxWait    bool
+	exhausted  bool // Whether a retry was refused or the last retry was taken.
+}
+
+// NewRetryBudget creates a RetryBudget allowing maxRetries retries that wait at most maxWait in
+// total. A zero maxRetries or maxWait leaves that dimension unbounded.
+//
+// Parameters:
+// - maxRetries: The number of retries allowed, 0 for no limit.
+// - maxWait: The total backoff allowed across the retries, 0 for no limit.
+//
+// Returns:
+// - The budget, or nil if neither dimension is bounded.
+func NewRetryBudget(maxRetries int, maxWait time.Duration) *RetryBudget {
+	if maxRetries <= 0 && maxWait <= 0 {
+		return nil
+	}
+	return &RetryBudget{retries: maxRetries, wait: maxWait, maxRetries: maxRetries > 0, maxWait: maxWait > 0}
+}
+
+// Take spends one retry waiting backoff before it from the budget. It reports false, and spends
+// nothing, if the budget cannot cover the retry; the budget is then exhausted.
+//
+// Parameters:
+// - backoff: How long the retry waits before it is made.
+//
+// Returns:
+// - true if the retry may be made.
+func (r *RetryBudget) Take(backoff time.Duration) bool {
+	if r == nil {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.exhausted || (r.maxRetries && r.retries <= 0) || (r.maxWait && backoff > r.wait) {
+		r.exhausted = true
+		return false
+	}
+	if r.maxRetries {
+		r.retries--
+		if r.retries == 0 {
+			r.exhausted = true
+		}
+	}
+	if r.maxWait {
+		r.wait -= backoff
+	}
+	return true
+}
+
+// Exhausted reports whether the budget has run out, so the operations sharing it should stop.
+func (r *RetryBudget) Exhausted() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exhausted
+}
+
+// RetryWithBudget runs op, retrying it with the bid retry backoff while it fails. It bounds the
+// retries of cycle steps other than bids, such as building a transaction or sending a bundle, by
+// the budget their bids share.
+//
+// Parameters:
+// - ctx: The context passed to op and bounding the retries; no retry is made once it is done.
+// - maxRetries: How many times op may be retried after the first attempt.
+// - budget: The retry budget of the cycle, nil for none.
+// - op: The operation to run.
+//
+// Returns:
+// - The error of the last attempt, wrapped with ErrRetryBudgetExhausted if the budget stopped a retry.
+func RetryWithBudget(ctx context.Context, maxRetries int, budget *RetryBudget, op func(ctx context.Context) error) error {
+	backoff := DefaultBidRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := op(ctx)
+		if err == nil || attempt >= maxRetries {
+			return err
+		}
+		if !budget.Take(backoff) {
+			return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// IsRetryableBidError reports whether a failed bid may succeed when sent again. Only an
+// unavailable bidder service is retried; errors such as an invalid argument would fail again.
+//
+// Parameters:
+// - err: The error returned by SendBidRequest.
+//
+// Returns:
+// - true if the bid should be retried.
+func IsRetryableBidError(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// SendBidRequestWithRetry sends a bid request like SendBidRequest, retrying it with a short
+// backoff while it fails with a retryable error before any commitment was received.
+//
+// Parameters:
+// - ctx: The context bounding the retries; no retry is made once it is done.
+// - bidRequest: The bid request to submit.
+// - maxRetries: How many times the bid may be retried after the first attempt.
+//
+// Returns:
+// - The result and error of the last attempt.
+func (b *Bidder) SendBidRequestWithRetry(ctx context.Context, bidRequest *pb.Bid, maxRetries int) (*BidResult, error) {
+	return b.SendBidRequestWithRetryBudget(ctx, bidRequest, maxRetries, nil)
+}
+
+// SendBidRequestWithRetryBudget sends a bid request like SendBidRequestWithRetry, additionally
+// taking each retry from a budget shared with the other operations of the cycle.
+//
+// Parameters:
+// - ctx: The context bounding each attempt and the retries; no retry is made once it is done.
+// - bidRequest: The bid request to submit.
+// - maxRetries: How many times the bid may be retried after the first attempt.
+// - budget: The retry budget of the cycle, nil for none.
+//
+// Returns:
+// - The result and error of the last attempt, wrapped with ErrRetryBudgetExhausted if the budget stopped a retry.
+func (b *Bidder) SendBidRequestWithRetryBudget(ctx context.Context, bidRequest *pb.Bid, maxRetries int, budget *RetryBudget) (*BidResult, error) {
+	backoff := DefaultBidRetryBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := b.SendBidRequest(ctx, bidRequest)
+		if err == nil || attempt >= maxRetries || len(result.Commitments) > 0 || !IsRetryableBidError(err) {
+			return result, err
+		}
+		if !budget.Take(backoff) {
+			b.logger.Warn("Not retrying bid, the cycle's retry budget is exhausted", "attempt", attempt+1, "error", err)
+			return result, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		b.logger.Warn("Retrying bid", "attempt", attempt+1, "maxRetries", maxRetries, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,271 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryableBidError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: status.Error(codes.Unavailable, "connection refused"), want: true},
+		{err: status.Error(codes.InvalidArgument, "bid amount too low")},
+		{err: status.Error(codes.DeadlineExceeded, "deadline exceeded")},
+		{err: errors.New("plain error")},
+		{err: ErrNoCommitment},
+		{err: nil},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableBidError(tt.err); got != tt.want {
+			t.Errorf("IsRetryableBidError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSendBidRequestWithRetry(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "bidder node unavailable")
+	tests := []struct {
+		name         string
+		failures     int   // Bids failing before the node recovers.
+		failWith     error // The error the failing bids return.
+		maxRetries   int
+		cancelled    bool // Whether the context is done before the first attempt.
+		wantAttempts int
+		wantCode     codes.Code
+	}{
+		{name: "success", failWith: unavailable, maxRetries: 2, wantAttempts: 1},
+		{name: "recovers", failures: 1, failWith: unavailable, maxRetries: 2, wantAttempts: 2},
+		{name: "retries exhausted", failures: 5, failWith: unavailable, maxRetries: 1, wantAttempts: 2, wantCode: codes.Unavailable},
+		{name: "retries disabled", failures: 1, failWith: unavailable, wantAttempts: 1, wantCode: codes.Unavailable},
+		{name: "not retryable", failures: 1, failWith: status.Error(codes.InvalidArgument, "bid amount too low"), maxRetries: 2, wantAttempts: 1, wantCode: codes.InvalidArgument},
+		{name: "context done", failures: 1, failWith: unavailable, maxRetries: 2, cancelled: true, wantCode: codes.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := &fakeBidderServer{fail: func(*pb.Bid) error {
+				if int(attempts.Add(1)) <= tt.failures {
+					return tt.failWith
+				}
+				return nil
+			}}
+			b := dialFakeBidder(t, server)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			bid, err := NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+			if err != nil {
+				t.Fatalf("NewBidRequest: %v", err)
+			}
+			result, err := b.SendBidRequestWithRetry(ctx, bid, tt.maxRetries)
+			if got := status.Code(errors.Unwrap(err)); got != tt.wantCode {
+				t.Fatalf("SendBidRequestWithRetry error = %v, want code %s", err, tt.wantCode)
+			}
+			if got := len(server.received()); got != tt.wantAttempts {
+				t.Fatalf("%d attempts, want %d", got, tt.wantAttempts)
+			}
+			if err == nil && len(result.Commitments) != 1 {
+				t.Fatalf("%d commitments, want 1", len(result.Commitments))
+			}
+		})
+	}
+}
+
+func TestSendBidRequestWithRetryNoCommitment(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+	}{
+		{name: "retries disabled"},
+		{name: "retries enabled", maxRetries: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeBidderServer{commit: func(*pb.Bid) []*pb.Commitment { return nil }}
+			b := dialFakeBidder(t, server)
+
+			bid, err := NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+			if err != nil {
+				t.Fatalf("NewBidRequest: %v", err)
+			}
+			// The node accepted the bid, so sending it again would only duplicate it
+			result, err := b.SendBidRequestWithRetry(context.Background(), bid, tt.maxRetries)
+			if !errors.Is(err, ErrNoCommitment) {
+				t.Fatalf("SendBidRequestWithRetry error = %v, want %v", err, ErrNoCommitment)
+			}
+			if result == nil || len(result.Commitments) != 0 {
+				t.Fatalf("result = %+v, want one without commitments", result)
+			}
+			if got := len(server.received()); got != 1 {
+				t.Fatalf("%d attempts, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxRetries    int
+		maxWait       time.Duration
+		backoffs      []time.Duration // Of the retries taken, in order.
+		want          []bool          // Whether each retry is allowed.
+		wantExhausted bool
+	}{
+		{name: "unbounded", backoffs: []time.Duration{time.Second, time.Hour}, want: []bool{true, true}},
+		{name: "retry count", maxRetries: 2, backoffs: []time.Duration{time.Second, time.Second, time.Second}, want: []bool{true, true, false}, wantExhausted: true},
+		{name: "last retry taken", maxRetries: 1, backoffs: []time.Duration{time.Second}, want: []bool{true}, wantExhausted: true},
+		{name: "within the wait", maxWait: time.Second, backoffs: []time.Duration{250 * time.Millisecond, 500 * time.Millisecond}, want: []bool{true, true}},
+		{name: "wait spent", maxWait: time.Second, backoffs: []time.Duration{500 * time.Millisecond, time.Second, 250 * time.Millisecond}, want: []bool{true, false, false}, wantExhausted: true},
+		{name: "both bounded", maxRetries: 5, maxWait: 300 * time.Millisecond, backoffs: []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, want: []bool{true, false}, wantExhausted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := NewRetryBudget(tt.maxRetries, tt.maxWait)
+			if (budget == nil) != (tt.maxRetries == 0 && tt.maxWait == 0) {
+				t.Fatalf("NewRetryBudget(%d, %s) = %v", tt.maxRetries, tt.maxWait, budget)
+			}
+			for i, backoff := range tt.backoffs {
+				if got := budget.Take(backoff); got != tt.want[i] {
+					t.Fatalf("retry %d: Take(%s) = %v, want %v", i, backoff, got, tt.want[i])
+				}
+			}
+			if got := budget.Exhausted(); got != tt.wantExhausted {
+				t.Fatalf("Exhausted = %v, want %v", got, tt.wantExhausted)
+			}
+		})
+	}
+}
+
+func TestSendBidRequestWithRetryBudget(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "bidder node unavailable")
+	tests := []struct {
+		name         string
+		budget       *RetryBudget
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "no budget", wantAttempts: 3},
+		{name: "budget allows every retry", budget: NewRetryBudget(5, 0), wantAttempts: 3},
+		{name: "budget runs out", budget: NewRetryBudget(1, 0), wantAttempts: 2, wantErr: ErrRetryBudgetExhausted},
+		{name: "backoff exceeds the wait", budget: NewRetryBudget(0, DefaultBidRetryBackoff/2), wantAttempts: 1, wantErr: ErrRetryBudgetExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The node stays unavailable, so every allowed retry is made
+			server := &fakeBidderServer{fail: func(*pb.Bid) error { return unavailable }}
+			b := dialFakeBidder(t, server)
+
+			bid, err := NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+			if err != nil {
+				t.Fatalf("NewBidRequest: %v", err)
+			}
+			_, err = b.SendBidRequestWithRetryBudget(context.Background(), bid, 2, tt.budget)
+			if !errors.Is(err, unavailable) {
+				t.Fatalf("SendBidRequestWithRetryBudget error = %v, want the last attempt's error", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendBidRequestWithRetryBudget error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && errors.Is(err, ErrRetryBudgetExhausted) {
+				t.Fatalf("SendBidRequestWithRetryBudget error = %v, want no exhausted budget", err)
+			}
+			if got := len(server.received()); got != tt.wantAttempts {
+				t.Fatalf("%d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryWithBudget(t *testing.T) {
+	failing := errors.New("rpc unavailable")
+	tests := []struct {
+		name         string
+		failures     int // Attempts failing before op succeeds.
+		maxRetries   int
+		budget       *RetryBudget
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "success", maxRetries: 2, wantAttempts: 1},
+		{name: "recovers", failures: 1, maxRetries: 2, wantAttempts: 2},
+		{name: "retries exhausted", failures: 5, maxRetries: 1, wantAttempts: 2, wantErr: failing},
+		{name: "retries disabled", failures: 1, wantAttempts: 1, wantErr: failing},
+		{name: "budget runs out", failures: 5, maxRetries: 3, budget: NewRetryBudget(1, 0), wantAttempts: 2, wantErr: ErrRetryBudgetExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := RetryWithBudget(context.Background(), tt.maxRetries, tt.budget, func(context.Context) error {
+				attempts++
+				if attempts <= tt.failures {
+					return failing
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RetryWithBudget error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Fatalf("%d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+// TestRetryBudgetAcrossSteps runs the steps of a cycle against one budget: the retries of the
+// transaction build spend it, so neither the bundle nor the bid is retried afterwards.
+func TestRetryBudgetAcrossSteps(t *testing.T) {
+	ctx := context.Background()
+	budget := NewRetryBudget(2, 0)
+
+	buildAttempts := 0
+	err := RetryWithBudget(ctx, 3, budget, func(context.Context) error {
+		buildAttempts++
+		if buildAttempts <= 2 {
+			return errors.New("fee history unavailable")
+		}
+		return nil
+	})
+	if err != nil || buildAttempts != 3 {
+		t.Fatalf("build: %d attempts, error %v; want 3 attempts and no error", buildAttempts, err)
+	}
+	if !budget.Exhausted() {
+		t.Fatal("budget not exhausted by the build retries")
+	}
+
+	bundleAttempts := 0
+	err = RetryWithBudget(ctx, 3, budget, func(context.Context) error {
+		bundleAttempts++
+		return errors.New("relay unavailable")
+	})
+	if !errors.Is(err, ErrRetryBudgetExhausted) || bundleAttempts != 1 {
+		t.Fatalf("bundle: %d attempts, error %v; want 1 attempt and %v", bundleAttempts, err, ErrRetryBudgetExhausted)
+	}
+
+	server := &fakeBidderServer{fail: func(*pb.Bid) error { return status.Error(codes.Unavailable, "bidder node unavailable") }}
+	b := dialFakeBidder(t, server)
+	bid, err := NewBidRequest([]string{"ab"}, "42", 100, 1000, 37000)
+	if err != nil {
+		t.Fatalf("NewBidRequest: %v", err)
+	}
+	_, err = b.SendBidRequestWithRetryBudget(ctx, bid, 3, budget)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("bid error = %v, want %v", err, ErrRetryBudgetExhausted)
+	}
+	if got := len(server.received()); got != 1 {
+		t.Fatalf("bid: %d attempts, want 1", got)
+	}
+}
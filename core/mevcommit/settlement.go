@@ -0,0 +1,164 @@
+package mevcommit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SettlementOutcome describes how the funds locked for a commitment were settled.
+type SettlementOutcome int
+
+const (
+	// SettlementRewarded is a commitment whose provider delivered and was paid the bid (FundsRewarded).
+	SettlementRewarded SettlementOutcome = iota
+	// SettlementRetrieved is a commitment whose bid was returned to the bidder, e.g. because the provider was slashed (FundsRetrieved).
+	SettlementRetrieved
+)
+
+// String returns the name of the outcome.
+func (o SettlementOutcome) String() string {
+	switch o {
+	case SettlementRewarded:
+		return "rewarded"
+	case SettlementRetrieved:
+		return "retrieved"
+	default:
+		return "unknown"
+	}
+}
+
+// SettlementEvent is a decoded FundsRewarded or FundsRetrieved log of the BidderRegistry, emitted
+// when the oracle settles an opened commitment.
+type SettlementEvent struct {
+	CommitmentDigest [32]byte          // The digest of the settled commitment.
+	Bidder           common.Address    // The bidder whose funds were settled.
+	Provider         common.Address    // The provider paid, zero unless the outcome is SettlementRewarded.
+	Window           *big.Int          // The bidding window the funds were locked in.
+	Amount           *big.Int          // The settled amount in wei.
+	Outcome          SettlementOutcome // Whether the provider was paid or the bidder refunded.
+	Log              types.Log         // The raw log; Log.Removed is set when a reorg reverted it.
+}
+
+// SettlementListener streams commitment settlements from the BidderRegistry contract using a
+// single log subscription.
+type SettlementListener struct {
+	client  ethereum.LogFilterer // The client used to subscribe to contract logs.
+	abi     abi.ABI              // The BidderRegistry contract ABI.
+	address common.Address       // The BidderRegistry contract address.
+	bidder  common.Address       // Only settlements of this bidder are streamed, all if zero.
+}
+
+// NewSettlementListener creates a SettlementListener for the BidderRegistry contract.
+//
+// Parameters:
+// - client: The client used to subscribe to contract logs.
+// - bidder: The bidder whose settlements are streamed, or the zero address for every bidder.
+//
+// Returns:
+// - A pointer to a SettlementListener, or an error if the contract ABI cannot be loaded.
+func NewSettlementListener(client ethereum.LogFilterer, bidder common.Address) (*SettlementListener, error) {
+	contractAbi, err := LoadABI("abi/BidderRegistry.abi")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contract ABI: %w", err)
+	}
+
+	return &SettlementListener{
+		client:  client,
+		abi:     contractAbi,
+		address: common.HexToAddress(bidderRegistryAddress),
+		bidder:  bidder,
+	}, nil
+}
+
+// Run subscribes to FundsRewarded and FundsRetrieved logs and sends each decoded event to out
+// until the context is cancelled or the subscription fails.
+//
+// Parameters:
+// - ctx: The context controlling the subscription.
+// - out: The channel decoded events are sent to.
+//
+// Returns:
+// - The subscription error, or the context error once the context is cancelled.
+func (l *SettlementListener) Run(ctx context.Context, out chan<- SettlementEvent) error {
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{l.address},
+		Topics:    [][]common.Hash{{l.abi.Events["FundsRewarded"].ID, l.abi.Events["FundsRetrieved"].ID}},
+	}
+	// The bidder is the second indexed argument of both events
+	if l.bidder != (common.Address{}) {
+		query.Topics = append(query.Topics, nil, []common.Hash{common.BytesToHash(l.bidder.Bytes())})
+	}
+
+	logs := make(chan types.Log)
+	sub, err := l.client.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("log subscription failed: %w", err)
+		case vLog := <-logs:
+			event, err := l.decode(vLog)
+			if err != nil {
+				logger.Warn("Failed to unpack settlement log", "tx", vLog.TxHash, "error", err)
+				continue
+			}
+			if err := send(ctx, out, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// decode unpacks a FundsRewarded or FundsRetrieved log into a SettlementEvent.
+func (l *SettlementListener) decode(vLog types.Log) (SettlementEvent, error) {
+	event := SettlementEvent{Log: vLog}
+	if len(vLog.Topics) < 3 {
+		return event, fmt.Errorf("expected at least 3 topics, got %d", len(vLog.Topics))
+	}
+
+	name := "FundsRetrieved"
+	event.Outcome = SettlementRetrieved
+	if vLog.Topics[0] == l.abi.Events["FundsRewarded"].ID {
+		name = "FundsRewarded"
+		event.Outcome = SettlementRewarded
+		if len(vLog.Topics) < 4 {
+			return event, fmt.Errorf("expected 4 topics, got %d", len(vLog.Topics))
+		}
+		event.Provider = common.BytesToAddress(vLog.Topics[3].Bytes())
+	}
+
+	// The window and amount are carried in the data, the rest is indexed
+	values, err := l.abi.Unpack(name, vLog.Data)
+	if err != nil {
+		return event, err
+	}
+	if len(values) != 2 {
+		return event, fmt.Errorf("expected 2 values, got %d", len(values))
+	}
+	window, ok := values[0].(*big.Int)
+	if !ok {
+		return event, fmt.Errorf("failed to convert window to *big.Int")
+	}
+	amount, ok := values[1].(*big.Int)
+	if !ok {
+		return event, fmt.Errorf("failed to convert amount to *big.Int")
+	}
+
+	event.CommitmentDigest = vLog.Topics[1]
+	event.Bidder = common.BytesToAddress(vLog.Topics[2].Bytes())
+	event.Window = window
+	event.Amount = amount
+	return event, nil
+}
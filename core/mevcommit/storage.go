@@ -0,0 +1,212 @@
+package mevcommit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+// Storage persists the bid requests sent by a Bidder and the commitments received for them.
+// Saves run in their own goroutines, so implementations must be safe for concurrent use.
+// Implementations log failures rather than return them, since no caller waits on a save.
+type Storage interface {
+	// SaveBidRequest saves a bid request along with its submission time and bid hash.
+	SaveBidRequest(saved SavedBidRequest)
+	// SaveBidResponses saves the commitments received for a bid.
+	SaveBidResponses(responses []*pb.Commitment)
+	// SaveObservedCommitment saves a commitment observed on chain.
+	SaveObservedCommitment(observed ObservedCommitment)
+}
+
+// ObservedCommitment is the stored form of a CommitmentEvent seen by a CommitmentListener.
+type ObservedCommitment struct {
+	Timestamp         int64  `json:"timestamp"`         // Unix time at which the event was observed.
+	Status            string `json:"status"`            // The CommitmentStatus of the event.
+	CommitmentIndex   string `json:"commitmentIndex"`   // The commitment index, hex encoded.
+	Bidder            string `json:"bidder"`            // The bidder address.
+	Committer         string `json:"committer"`         // The provider address.
+	Bid               uint64 `json:"bid"`               // The bid amount in wei.
+	BlockNumber       uint64 `json:"blockNumber"`       // The L1 block number the commitment is for.
+	BidHash           string `json:"bidHash"`           // The bid hash, hex encoded, see BidHash.
+	TxnHash           string `json:"txnHash"`           // The committed transaction hashes.
+	CommitmentHash    string `json:"commitmentHash"`    // The commitment hash, hex encoded.
+	DispatchTimestamp uint64 `json:"dispatchTimestamp"` // The time the provider dispatched the commitment.
+	LogTxHash         string `json:"logTxHash"`         // The mev-commit chain transaction that stored the commitment.
+	LogBlockNumber    uint64 `json:"logBlockNumber"`    // The mev-commit chain block that holds the log.
+}
+
+// NewObservedCommitment converts a CommitmentEvent into its stored form.
+func NewObservedCommitment(e CommitmentEvent, observedAt time.Time) ObservedCommitment {
+	return ObservedCommitment{
+		Timestamp:         observedAt.Unix(),
+		Status:            e.Status.String(),
+		CommitmentIndex:   hexutil.Encode(e.Event.CommitmentIndex[:]),
+		Bidder:            e.Event.Bidder.Hex(),
+		Committer:         e.Event.Commiter.Hex(),
+		Bid:               e.Event.Bid,
+		BlockNumber:       e.Event.BlockNumber,
+		BidHash:           hexutil.Encode(e.Event.BidHash[:]),
+		TxnHash:           e.Event.TxnHash,
+		CommitmentHash:    hexutil.Encode(e.Event.CommitmentHash[:]),
+		DispatchTimestamp: e.Event.DispatchTimestamp,
+		LogTxHash:         e.Log.TxHash.Hex(),
+		LogBlockNumber:    e.Log.BlockNumber,
+	}
+}
+
+// defaultFileStorage is shared by all bidders saving to the default files, so that their saves
+// are serialized too.
+var defaultFileStorage = NewFileStorage(DefaultBidRequestsFile, DefaultBidResponsesFile, DefaultObservedCommitmentsFile)
+
+// FileStorage appends bid requests, responses and observed commitments to JSON array files.
+type FileStorage struct {
+	mu              sync.Mutex // Serializes the read-modify-write of the files.
+	requestsFile    string     // The file bid requests are appended to.
+	responsesFile   string     // The file bid responses are appended to.
+	commitmentsFile string     // The file observed commitments are appended to.
+}
+
+// NewFileStorage creates a FileStorage writing to the given files.
+//
+// Parameters:
+// - requestsFile: The JSON file to append bid requests to.
+// - responsesFile: The JSON file to append bid responses to.
+// - commitmentsFile: The JSON file to append observed commitments to.
+//
+// Returns:
+// - A pointer to a FileStorage.
+func NewFileStorage(requestsFile, responsesFile, commitmentsFile string) *FileStorage {
+	return &FileStorage{requestsFile: requestsFile, responsesFile: responsesFile, commitmentsFile: commitmentsFile}
+}
+
+// SaveBidRequest appends the bid request to the requests file.
+func (s *FileStorage) SaveBidRequest(saved SavedBidRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saveBidRequest(s.requestsFile, saved)
+}
+
+// SaveBidResponses appends the responses to the responses file.
+func (s *FileStorage) SaveBidResponses(responses []*pb.Commitment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saveBidResponses(s.responsesFile, responses)
+}
+
+// SaveObservedCommitment appends the observed commitment to the commitments file.
+func (s *FileStorage) SaveObservedCommitment(observed ObservedCommitment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := appendJSON(s.commitmentsFile, observed); err != nil {
+		logger.Error("Failed to save observed commitment", "filename", s.commitmentsFile, "error", err)
+	}
+}
+
+// DiscardStorage drops everything it is handed, for bidders that should not persist bid data at
+// all, for privacy or to avoid the disk writes.
+type DiscardStorage struct{}
+
+// SaveBidRequest discards the bid request.
+func (DiscardStorage) SaveBidRequest(SavedBidRequest) {}
+
+// SaveBidResponses discards the responses.
+func (DiscardStorage) SaveBidResponses([]*pb.Commitment) {}
+
+// SaveObservedCommitment discards the observed commitment.
+func (DiscardStorage) SaveObservedCommitment(ObservedCommitment) {}
+
+// InMemoryStorage keeps bid requests and responses in memory. It is meant for tests and for
+// consumers that do not want the bidder to write to the filesystem.
+type InMemoryStorage struct {
+	mu          sync.Mutex
+	requests    []SavedBidRequest
+	responses   []*pb.Commitment
+	commitments []ObservedCommitment
+}
+
+// NewInMemoryStorage creates an empty InMemoryStorage.
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{}
+}
+
+// SaveBidRequest records the bid request.
+func (s *InMemoryStorage) SaveBidRequest(saved SavedBidRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, saved)
+}
+
+// SaveBidResponses records the responses.
+func (s *InMemoryStorage) SaveBidResponses(responses []*pb.Commitment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, responses...)
+}
+
+// SaveObservedCommitment records the observed commitment.
+func (s *InMemoryStorage) SaveObservedCommitment(observed ObservedCommitment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commitments = append(s.commitments, observed)
+}
+
+// BidRequests returns a copy of the recorded bid requests, in the order they were saved.
+func (s *InMemoryStorage) BidRequests() []SavedBidRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SavedBidRequest(nil), s.requests...)
+}
+
+// BidResponses returns a copy of the recorded responses, in the order they were saved.
+func (s *InMemoryStorage) BidResponses() []*pb.Commitment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pb.Commitment(nil), s.responses...)
+}
+
+// ObservedCommitments returns a copy of the recorded observed commitments, in the order they were saved.
+func (s *InMemoryStorage) ObservedCommitments() []ObservedCommitment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ObservedCommitment(nil), s.commitments...)
+}
+
+// appendJSON appends item to the JSON array stored in filename, creating the file and its
+// directory if needed.
+func appendJSON(filename string, item interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var existing []json.RawMessage
+	data, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("failed to decode existing JSON data: %w", err)
+		}
+	}
+
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode data to JSON: %w", err)
+	}
+	existing = append(existing, encoded)
+
+	data, err = json.Marshal(existing)
+	if err != nil {
+		return fmt.Errorf("failed to encode data to JSON: %w", err)
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,93 @@
+package mevcommit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	pb "github.com/primev/preconf_blob_bidder/core/bidderpb"
+)
+
+func TestStorage(t *testing.T) {
+	dir := t.TempDir()
+	files := NewFileStorage(filepath.Join(dir, "data", "bid.json"), filepath.Join(dir, "data", "response.json"), filepath.Join(dir, "data", "commitments.json"))
+	memory := NewInMemoryStorage()
+
+	// kept counts the bid requests, commitments and observed commitments a storage kept
+	type kept struct{ requests, responses, observed int }
+	tests := []struct {
+		name    string
+		storage Storage
+		read    func(t *testing.T) kept
+		want    kept
+	}{
+		{
+			name:    "file",
+			storage: files,
+			read: func(t *testing.T) kept {
+				requests, err := LoadBidRequests(files.requestsFile)
+				if err != nil {
+					t.Fatalf("LoadBidRequests: %v", err)
+				}
+				responses, err := LoadBidResponses(files.responsesFile)
+				if err != nil {
+					t.Fatalf("LoadBidResponses: %v", err)
+				}
+				data, err := os.ReadFile(files.commitmentsFile)
+				if err != nil {
+					t.Fatalf("ReadFile: %v", err)
+				}
+				var observed []ObservedCommitment
+				if err := json.Unmarshal(data, &observed); err != nil {
+					t.Fatalf("Unmarshal: %v", err)
+				}
+				return kept{len(requests), len(responses), len(observed)}
+			},
+			want: kept{20, 40, 20},
+		},
+		{
+			name:    "in memory",
+			storage: memory,
+			read: func(*testing.T) kept {
+				return kept{len(memory.BidRequests()), len(memory.BidResponses()), len(memory.ObservedCommitments())}
+			},
+			want: kept{20, 40, 20},
+		},
+		{
+			name:    "discard",
+			storage: DiscardStorage{},
+			read:    func(*testing.T) kept { return kept{} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Saves run concurrently, as a Bidder runs them in their own goroutines
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					tt.storage.SaveBidRequest(SavedBidRequest{Timestamp: int64(i), BidRequest: &pb.Bid{Amount: "1", BlockNumber: int64(i)}})
+					tt.storage.SaveBidResponses([]*pb.Commitment{{BlockNumber: int64(i)}, {BlockNumber: int64(i)}})
+					tt.storage.SaveObservedCommitment(ObservedCommitment{BlockNumber: uint64(i)})
+				}(i)
+			}
+			wg.Wait()
+			if got := tt.read(t); got != tt.want {
+				t.Fatalf("kept %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInMemoryStorageCopies(t *testing.T) {
+	s := NewInMemoryStorage()
+	s.SaveBidRequest(SavedBidRequest{Timestamp: 1})
+	requests := s.BidRequests()
+	requests[0].Timestamp = 2
+	if got := s.BidRequests()[0].Timestamp; got != 1 {
+		t.Fatalf("saved request changed through a returned copy, timestamp %d", got)
+	}
+}
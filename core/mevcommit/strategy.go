@@ -0,0 +1,156 @@
+package mevcommit
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// escalationHistory is how many target blocks behind the latest one an EscalatingBidStrategy
+// keeps bid amounts for before forgetting them.
+const escalationHistory = 64
+
+// BidStrategy decides how much to bid for a target block and learns from the outcome of each bid.
+type BidStrategy interface {
+	// BidAmount returns the amount in wei to bid for the target block.
+	BidAmount(targetBlock uint64) (*big.Int, error)
+	// Observe reports whether the bid for the target block was accepted by a provider.
+	Observe(targetBlock uint64, accepted bool)
+}
+
+// RandomBidStrategy bids a uniformly random amount between its bounds, regardless of outcomes.
+type RandomBidStrategy struct {
+	minAmount *big.Int
+	maxAmount *big.Int
+}
+
+// NewRandomBidStrategy creates a RandomBidStrategy bidding between minAmount and maxAmount wei.
+//
+// Parameters:
+// - minAmount: The lowest amount to bid, in wei.
+// - maxAmount: The highest amount to bid, in wei.
+//
+// Returns:
+// - A pointer to a RandomBidStrategy, or an error if the bounds are invalid.
+func NewRandomBidStrategy(minAmount, maxAmount *big.Int) (*RandomBidStrategy, error) {
+	if err := validateBidBounds(minAmount, maxAmount); err != nil {
+		return nil, err
+	}
+	return &RandomBidStrategy{minAmount: minAmount, maxAmount: maxAmount}, nil
+}
+
+// BidAmount returns a uniformly random amount between the bounds, inclusive. The arithmetic is
+// done on big integers so the amount is exact whatever the bounds.
+func (s *RandomBidStrategy) BidAmount(targetBlock uint64) (*big.Int, error) {
+	span := new(big.Int).Sub(s.maxAmount, s.minAmount)
+	offset, err := rand.Int(rand.Reader, span.Add(span, big.NewInt(1)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate bid amount: %w", err)
+	}
+	return offset.Add(offset, s.minAmount), nil
+}
+
+// Observe is a no-op, the random strategy does not adapt to outcomes.
+func (s *RandomBidStrategy) Observe(targetBlock uint64, accepted bool) {}
+
+// EscalatingBidStrategy starts bidding at the minimum amount and raises the amount by a fixed
+// step after every rejected bid, up to the maximum. An accepted bid resets the amount to the
+// minimum. The amount bid for each target block is tracked so outcomes reported out of order
+// escalate from the amount that was actually rejected.
+type EscalatingBidStrategy struct {
+	mu        sync.Mutex
+	minAmount *big.Int
+	maxAmount *big.Int
+	step      *big.Int
+	current   *big.Int            // The amount the next bid will use.
+	bids      map[uint64]*big.Int // The amount bid per target block, awaiting an outcome.
+}
+
+// NewEscalatingBidStrategy creates an EscalatingBidStrategy.
+//
+// Parameters:
+// - minAmount: The amount of the first bid and the amount bids reset to on acceptance, in wei.
+// - maxAmount: The highest amount to bid, in wei.
+// - step: The amount added after each rejected bid, in wei.
+//
+// Returns:
+// - A pointer to an EscalatingBidStrategy, or an error if the bounds or step are invalid.
+func NewEscalatingBidStrategy(minAmount, maxAmount, step *big.Int) (*EscalatingBidStrategy, error) {
+	if err := validateBidBounds(minAmount, maxAmount); err != nil {
+		return nil, err
+	}
+	if step == nil || step.Sign() <= 0 {
+		return nil, fmt.Errorf("bid escalation step must be positive")
+	}
+	return &EscalatingBidStrategy{
+		minAmount: minAmount,
+		maxAmount: maxAmount,
+		step:      step,
+		current:   new(big.Int).Set(minAmount),
+		bids:      make(map[uint64]*big.Int),
+	}, nil
+}
+
+// BidAmount returns the current escalation amount and remembers it for the target block.
+// Asking again for a block whose outcome is still pending returns the same amount.
+func (s *EscalatingBidStrategy) BidAmount(targetBlock uint64) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if amount, ok := s.bids[targetBlock]; ok {
+		return new(big.Int).Set(amount), nil
+	}
+
+	amount := new(big.Int).Set(s.current)
+	s.bids[targetBlock] = amount
+
+	// Forget blocks whose outcome will never be reported
+	for block := range s.bids {
+		if block+escalationHistory < targetBlock {
+			delete(s.bids, block)
+		}
+	}
+	return new(big.Int).Set(amount), nil
+}
+
+// Observe resets the amount to the minimum when the bid for the target block was accepted, and
+// otherwise raises it one step above the rejected amount, capped at the maximum.
+func (s *EscalatingBidStrategy) Observe(targetBlock uint64, accepted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	amount, ok := s.bids[targetBlock]
+	if !ok {
+		return
+	}
+	delete(s.bids, targetBlock)
+
+	if accepted {
+		s.current.Set(s.minAmount)
+		return
+	}
+
+	next := new(big.Int).Add(amount, s.step)
+	if next.Cmp(s.maxAmount) > 0 {
+		next.Set(s.maxAmount)
+	}
+	// A stale rejection must not lower an amount that already escalated further
+	if next.Cmp(s.current) > 0 {
+		s.current = next
+	}
+}
+
+// validateBidBounds checks that both bounds are set, non-negative and ordered.
+func validateBidBounds(minAmount, maxAmount *big.Int) error {
+	if minAmount == nil || maxAmount == nil {
+		return fmt.Errorf("bid amount bounds must be set")
+	}
+	if minAmount.Sign() < 0 {
+		return fmt.Errorf("minimum bid amount %s is negative", minAmount)
+	}
+	if maxAmount.Cmp(minAmount) < 0 {
+		return fmt.Errorf("maximum bid amount %s is lower than minimum %s", maxAmount, minAmount)
+	}
+	return nil
+}
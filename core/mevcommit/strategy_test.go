@@ -0,0 +1,145 @@
+package mevcommit
+
+import (
+	"math/big"
+	"testing"
+)
+
+// bigAmount parses a decimal wei amount.
+func bigAmount(t *testing.T, s string) *big.Int {
+	t.Helper()
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid amount %q", s)
+	}
+	return amount
+}
+
+func TestRandomBidStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max string
+		wantErr  bool
+	}{
+		{name: "ether bounds", min: "40000000000000000", max: "110000000000000000"},
+		{name: "beyond int64", min: "9223372036854775808", max: "100000000000000000000000"},
+		{name: "beyond uint64 span", min: "0", max: "1000000000000000000000000000000"},
+		{name: "equal bounds", min: "12345678901234567890", max: "12345678901234567890"},
+		{name: "zero", min: "0", max: "0"},
+		{name: "reversed", min: "2", max: "1", wantErr: true},
+		{name: "negative", min: "-1", max: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minAmount, maxAmount := bigAmount(t, tt.min), bigAmount(t, tt.max)
+			s, err := NewRandomBidStrategy(minAmount, maxAmount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRandomBidStrategy error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i := 0; i < 100; i++ {
+				amount, err := s.BidAmount(uint64(i))
+				if err != nil {
+					t.Fatalf("BidAmount: %v", err)
+				}
+				if amount.Cmp(minAmount) < 0 || amount.Cmp(maxAmount) > 0 {
+					t.Fatalf("BidAmount = %s, want between %s and %s", amount, minAmount, maxAmount)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomBidStrategyNilBounds(t *testing.T) {
+	if _, err := NewRandomBidStrategy(nil, big.NewInt(1)); err == nil {
+		t.Fatal("NewRandomBidStrategy accepted a nil minimum")
+	}
+	if _, err := NewRandomBidStrategy(big.NewInt(1), nil); err == nil {
+		t.Fatal("NewRandomBidStrategy accepted a nil maximum")
+	}
+}
+
+func TestEscalatingBidStrategy(t *testing.T) {
+	type step struct {
+		bid      uint64 // Target block to bid for, or zero to observe.
+		observe  uint64 // Target block whose outcome is observed.
+		accepted bool
+		want     int64 // The amount bid.
+	}
+	bid := func(block uint64, want int64) step { return step{bid: block, want: want} }
+	reject := func(block uint64) step { return step{observe: block} }
+	accept := func(block uint64) step { return step{observe: block, accepted: true} }
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "escalates after rejections",
+			steps: []step{bid(1, 10), reject(1), bid(2, 15), reject(2), bid(3, 20)},
+		},
+		{
+			name:  "capped at the maximum",
+			steps: []step{bid(1, 10), reject(1), bid(2, 15), reject(2), bid(3, 20), reject(3), bid(4, 22), reject(4), bid(5, 22)},
+		},
+		{
+			name:  "resets after acceptance",
+			steps: []step{bid(1, 10), reject(1), bid(2, 15), accept(2), bid(3, 10)},
+		},
+		{
+			name:  "same amount while pending",
+			steps: []step{bid(1, 10), bid(1, 10), reject(1), bid(1, 15)},
+		},
+		{
+			name:  "stale rejection does not lower the amount",
+			steps: []step{bid(1, 10), bid(2, 10), reject(2), bid(3, 15), reject(3), bid(4, 20), reject(1), bid(5, 20)},
+		},
+		{
+			name:  "unknown outcome is ignored",
+			steps: []step{reject(7), bid(8, 10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewEscalatingBidStrategy(big.NewInt(10), big.NewInt(22), big.NewInt(5))
+			if err != nil {
+				t.Fatalf("NewEscalatingBidStrategy: %v", err)
+			}
+			for i, st := range tt.steps {
+				if st.bid == 0 {
+					s.Observe(st.observe, st.accepted)
+					continue
+				}
+				amount, err := s.BidAmount(st.bid)
+				if err != nil {
+					t.Fatalf("step %d: BidAmount: %v", i, err)
+				}
+				if amount.Int64() != st.want {
+					t.Fatalf("step %d: BidAmount(%d) = %s, want %d", i, st.bid, amount, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewEscalatingBidStrategy(t *testing.T) {
+	tests := []struct {
+		name           string
+		min, max, step *big.Int
+		wantErr        bool
+	}{
+		{name: "valid", min: big.NewInt(1), max: big.NewInt(2), step: big.NewInt(1)},
+		{name: "no step", min: big.NewInt(1), max: big.NewInt(2), wantErr: true},
+		{name: "zero step", min: big.NewInt(1), max: big.NewInt(2), step: big.NewInt(0), wantErr: true},
+		{name: "reversed bounds", min: big.NewInt(2), max: big.NewInt(1), step: big.NewInt(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEscalatingBidStrategy(tt.min, tt.max, tt.step); (err != nil) != tt.wantErr {
+				t.Fatalf("NewEscalatingBidStrategy error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package mevcommit
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// debugTopologyMethod is the mev-commit node debug API method that returns the p2p topology.
+// The debug service is served on the same gRPC port as the bidder API.
+const debugTopologyMethod = "/debugapi.v1.DebugService/GetTopology"
+
+// topologyResponse describes debugapi.v1.TopologyResponse, whose only field holds the topology
+// as a google.protobuf.Struct. The bidder API protobufs do not include the debug service, so the
+// message is described here and decoded dynamically.
+var topologyResponse = mustTopologyResponse()
+
+func mustTopologyResponse() protoreflect.MessageDescriptor {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("debugapi/v1/debugapi_topology.proto"),
+		Package:    proto.String("debugapi.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("TopologyResponse"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("topology"),
+				JsonName: proto.String("topology"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".google.protobuf.Struct"),
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(fmt.Sprintf("invalid topology response descriptor: %v", err))
+	}
+	return file.Messages().ByName("TopologyResponse")
+}
+
+// Topology describes the p2p peers the bidder node is connected to.
+type Topology struct {
+	Providers []string               `json:"providers"` // Addresses of the connected providers.
+	Raw       map[string]interface{} `json:"raw"`       // The full topology document reported by the node.
+}
+
+// Topology queries the bidder node's debug API for its p2p topology.
+//
+// The bidder API protobufs do not define this call, so it is made against the node's debug
+// service. Its request is empty and its response holds the topology as a google.protobuf.Struct.
+//
+// Parameters:
+// - ctx: The context for the call.
+//
+// Returns:
+// - The node's topology, or an error if the node does not expose the debug service.
+func (b *Bidder) Topology(ctx context.Context) (*Topology, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.callTimeout)
+	defer cancel()
+
+	resp := dynamicpb.NewMessage(topologyResponse)
+	if err := b.conn.Invoke(ctx, debugTopologyMethod, &emptypb.Empty{}, resp); err != nil {
+		return nil, fmt.Errorf("failed to get topology: %w", err)
+	}
+
+	// The field's descriptor is the registered google.protobuf.Struct, so it merges into structpb
+	var topology structpb.Struct
+	field := topologyResponse.Fields().ByNumber(1)
+	if resp.Has(field) {
+		proto.Merge(&topology, resp.Get(field).Message().Interface())
+	}
+
+	result := &Topology{Raw: topology.AsMap()}
+	if peers, ok := result.Raw["connected_peers"].(map[string]interface{}); ok {
+		if providers, ok := peers["providers"].([]interface{}); ok {
+			for _, provider := range providers {
+				result.Providers = append(result.Providers, fmt.Sprint(provider))
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package mevcommit
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// rawMessage carries already encoded protobuf bytes through the gRPC codec.
+type rawMessage struct {
+	emptypb.Empty
+}
+
+// dialTopologyServer serves the debug topology method over an in-process connection. A nil
+// topology makes the server report the method as unimplemented.
+func dialTopologyServer(t *testing.T, topology *structpb.Struct) *Bidder {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if method != debugTopologyMethod || topology == nil {
+			return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+		}
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		// Encode TopologyResponse by hand: field 1 holds the Struct
+		value, err := proto.Marshal(topology)
+		if err != nil {
+			return err
+		}
+		var resp rawMessage
+		resp.ProtoReflect().SetUnknown(protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), value))
+		return stream.SendMsg(&resp)
+	}))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewBidderClientWithConn(conn)
+}
+
+func TestTopology(t *testing.T) {
+	tests := []struct {
+		name      string
+		topology  map[string]interface{}
+		providers []string
+	}{
+		{
+			name: "providers",
+			topology: map[string]interface{}{
+				"self":            map[string]interface{}{"Ethereum Address": "0xabc"},
+				"connected_peers": map[string]interface{}{"providers": []interface{}{"0x01", "0x02"}},
+			},
+			providers: []string{"0x01", "0x02"},
+		},
+		{
+			name:     "no providers",
+			topology: map[string]interface{}{"connected_peers": map[string]interface{}{}},
+		},
+		{
+			name:     "empty",
+			topology: map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology, err := structpb.NewStruct(tt.topology)
+			if err != nil {
+				t.Fatalf("NewStruct: %v", err)
+			}
+			got, err := dialTopologyServer(t, topology).Topology(context.Background())
+			if err != nil {
+				t.Fatalf("Topology: %v", err)
+			}
+			if !reflect.DeepEqual(got.Providers, tt.providers) {
+				t.Errorf("Providers = %v, want %v", got.Providers, tt.providers)
+			}
+			if !reflect.DeepEqual(got.Raw, tt.topology) {
+				t.Errorf("Raw = %v, want %v", got.Raw, tt.topology)
+			}
+		})
+	}
+}
+
+func TestTopologyUnimplemented(t *testing.T) {
+	_, err := dialTopologyServer(t, nil).Topology(context.Background())
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("Topology error = %v, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,88 @@
+package mevcommit
+
+import "sync"
+
+// WindowSizeDetector derives the number of L1 blocks per bidding window from the window height
+// observed at consecutive L1 blocks, instead of relying on DefaultBlocksPerWindow. The size is
+// the distance between two consecutive window transitions; it is cached once measured and only
+// replaced when a later pair of transitions measures a different size. Until then, and on a nil
+// detector, DefaultBlocksPerWindow is used.
+type WindowSizeDetector struct {
+	mu              sync.Mutex
+	window          uint64 // The last observed window height, zero before the first observation.
+	block           uint64 // The L1 block the last window height was observed at.
+	transition      uint64 // The first L1 block of the current window, zero if it was not observed.
+	blocksPerWindow uint64 // The detected window size, zero until measured.
+}
+
+// NewWindowSizeDetector creates a WindowSizeDetector without observations.
+func NewWindowSizeDetector() *WindowSizeDetector {
+	return &WindowSizeDetector{}
+}
+
+// Observe records the window height, as returned by WindowHeight, at an L1 block. A transition
+// only counts when the previous observation was of the block right before it and of the window
+// right before the new one, so missed blocks or windows never produce a wrong size.
+//
+// Parameters:
+// - window: The current window height.
+// - blockNumber: The L1 block the window height was observed at.
+func (d *WindowSizeDetector) Observe(window, blockNumber uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.window != 0 && (window < d.window || blockNumber <= d.block) {
+		// A stale or out of order observation
+		return
+	}
+	consecutive := d.window != 0 && blockNumber == d.block+1
+	switch {
+	case window == d.window:
+		if !consecutive {
+			d.transition = 0
+		}
+	case consecutive && window == d.window+1:
+		if d.transition != 0 {
+			d.measured(blockNumber - d.transition)
+		}
+		d.transition = blockNumber
+	default:
+		d.transition = 0
+	}
+	d.window = window
+	d.block = blockNumber
+}
+
+// measured caches a window size taken from two consecutive transitions.
+func (d *WindowSizeDetector) measured(blocksPerWindow uint64) {
+	if blocksPerWindow == d.blocksPerWindow {
+		return
+	}
+	if d.blocksPerWindow == 0 {
+		logger.Info("Detected blocks per window", "blocksPerWindow", blocksPerWindow)
+	} else {
+		logger.Warn("Blocks per window changed", "previous", d.blocksPerWindow, "blocksPerWindow", blocksPerWindow)
+	}
+	d.blocksPerWindow = blocksPerWindow
+}
+
+// BlocksPerWindow returns the detected number of L1 blocks per window, or DefaultBlocksPerWindow
+// and false while it has not been measured yet.
+func (d *WindowSizeDetector) BlocksPerWindow() (uint64, bool) {
+	if d == nil {
+		return DefaultBlocksPerWindow, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.blocksPerWindow == 0 {
+		return DefaultBlocksPerWindow, false
+	}
+	return d.blocksPerWindow, true
+}
+
+// WindowForBlock returns the bidding window an L1 block belongs to, like the package-level
+// WindowForBlock but with the detected window size once there is one.
+func (d *WindowSizeDetector) WindowForBlock(blockNumber uint64) uint64 {
+	blocksPerWindow, _ := d.BlocksPerWindow()
+	return windowForBlock(blockNumber, blocksPerWindow)
+}
@@ -0,0 +1,98 @@
+package mevcommit
+
+import "testing"
+
+// observation is a window height observed at an L1 block.
+type observation struct {
+	window, block uint64
+}
+
+// consecutiveObservations returns observations of every block from `from` to `to` with windows
+// of blocksPerWindow blocks, the first starting at block `start` as window `first`.
+func consecutiveObservations(from, to, start, first, blocksPerWindow uint64) []observation {
+	var observations []observation
+	for block := from; block <= to; block++ {
+		observations = append(observations, observation{window: first + (block-start)/blocksPerWindow, block: block})
+	}
+	return observations
+}
+
+func TestWindowSizeDetector(t *testing.T) {
+	concat := func(lists ...[]observation) []observation {
+		var all []observation
+		for _, list := range lists {
+			all = append(all, list...)
+		}
+		return all
+	}
+	tests := []struct {
+		name         string
+		observations []observation
+		want         uint64
+		wantDetected bool
+	}{
+		{name: "no observations", want: DefaultBlocksPerWindow},
+		{name: "one transition", observations: consecutiveObservations(1, 8, 1, 1, 5), want: DefaultBlocksPerWindow},
+		{name: "two transitions", observations: consecutiveObservations(1, 11, 1, 1, 5), want: 5, wantDetected: true},
+		{name: "offset windows", observations: consecutiveObservations(100, 130, 97, 20, 12), want: 12, wantDetected: true},
+		{
+			name:         "missed block across a transition",
+			observations: concat(consecutiveObservations(1, 6, 1, 1, 5), consecutiveObservations(8, 12, 1, 1, 5)),
+			want:         DefaultBlocksPerWindow,
+		},
+		{
+			name:         "missed window",
+			observations: []observation{{1, 4}, {1, 5}, {3, 6}, {3, 7}, {4, 8}},
+			want:         DefaultBlocksPerWindow,
+		},
+		{
+			name:         "stale observations ignored",
+			observations: concat(consecutiveObservations(1, 11, 1, 1, 5), []observation{{1, 3}, {2, 11}, {2, 12}}),
+			want:         5,
+			wantDetected: true,
+		},
+		{
+			name:         "size change",
+			observations: concat(consecutiveObservations(1, 11, 1, 1, 5), consecutiveObservations(12, 27, 11, 3, 8)),
+			want:         8,
+			wantDetected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewWindowSizeDetector()
+			for _, o := range tt.observations {
+				d.Observe(o.window, o.block)
+			}
+			if got, detected := d.BlocksPerWindow(); got != tt.want || detected != tt.wantDetected {
+				t.Fatalf("BlocksPerWindow = %d, %v, want %d, %v", got, detected, tt.want, tt.wantDetected)
+			}
+		})
+	}
+}
+
+func TestWindowSizeDetectorWindowForBlock(t *testing.T) {
+	var unset *WindowSizeDetector
+	detected := NewWindowSizeDetector()
+	for _, o := range consecutiveObservations(1, 11, 1, 1, 5) {
+		detected.Observe(o.window, o.block)
+	}
+	tests := []struct {
+		name     string
+		detector *WindowSizeDetector
+		block    uint64
+		want     uint64
+	}{
+		{name: "nil detector", detector: unset, block: 11, want: 2},
+		{name: "not measured", detector: NewWindowSizeDetector(), block: 10, want: 1},
+		{name: "measured", detector: detected, block: 10, want: 2},
+		{name: "measured block zero", detector: detected, block: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.detector.WindowForBlock(tt.block); got != tt.want {
+				t.Fatalf("WindowForBlock(%d) = %d, want %d", tt.block, got, tt.want)
+			}
+		})
+	}
+}